@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/cldixon/jernel/internal/config"
+	"github.com/cldixon/jernel/internal/entry"
+	"github.com/cldixon/jernel/internal/store"
+	"github.com/cldixon/jernel/internal/timefmt"
+	"github.com/spf13/cobra"
+)
+
+// Flags for catchup
+var catchupSummarizeFlag bool
+var catchupPeekFlag bool
+
+var catchupCmd = &cobra.Command{
+	Use:   "catchup",
+	Short: "Read everything written since you last checked",
+	Long: `Print every unread entry, oldest first, and mark them as read.
+
+Use --summarize to have the LLM write one short recap of the unread entries
+instead; the recap is printed, not saved. Use --peek to leave the entries unread.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := store.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		entries, err := db.ListFiltered(store.Filter{Unread: true})
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Println("You're all caught up.")
+			return nil
+		}
+		// Read in the order they were written
+		slices.Reverse(entries)
+
+		if catchupSummarizeFlag {
+			cfg, err := config.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			fmt.Printf("Summarizing %d unread %s...\n\n", len(entries), pluralize(len(entries), "entry", "entries"))
+			recap, err := entry.Recap(context.Background(), cfg, entries)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println("---")
+			fmt.Println(recap)
+			fmt.Println("---")
+			fmt.Println()
+			for _, e := range entries {
+				fmt.Printf("#%d [%s] %s%s\n", e.ID, e.Persona, timefmt.Format(e.CreatedAt, timefmt.Short), formatTags(e.Tags))
+			}
+		} else {
+			for i, e := range entries {
+				if i > 0 {
+					fmt.Println()
+				}
+				printEntry(e, false)
+			}
+		}
+
+		if catchupPeekFlag {
+			return nil
+		}
+		for _, e := range entries {
+			if err := db.MarkRead(e.ID); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("\nMarked %d %s as read.\n", len(entries), pluralize(len(entries), "entry", "entries"))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(catchupCmd)
+	catchupCmd.Flags().BoolVar(&catchupSummarizeFlag, "summarize", false, "Print an LLM recap of the unread entries instead of each entry")
+	catchupCmd.Flags().BoolVar(&catchupPeekFlag, "peek", false, "Leave the entries unread")
+}
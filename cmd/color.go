@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+)
+
+// Styles for command output, matching the TUI palette
+var (
+	accentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#de4f5c")).Bold(true)
+	dimStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#666666"))
+)
+
+// colorEnabled reports whether command output may be styled. Setting NO_COLOR
+// (https://no-color.org) or TERM=dumb turns styling off, as does writing to
+// anything other than a terminal, e.g. a pipe or a CI log
+func colorEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// styled renders text with style when color is enabled and returns it
+// unchanged otherwise; all colored command output goes through here
+func styled(style lipgloss.Style, text string) string {
+	if !colorEnabled() {
+		return text
+	}
+	return style.Render(text)
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cldixon/jernel/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and update configuration",
+	Long: `Read and update individual settings in ~/.config/jernel/config.yaml.
+
+Keys use dotted paths for nested sections:
+  ` + strings.Join(config.Keys(), "\n  ") + `
+
+config.yaml can also define named profiles that overlay these settings, applied
+with --profile or $` + config.ProfileEnv + `:
+
+  profiles:
+    work:
+      default_persona: prof_whitlock
+      daemon:
+        rate: 1`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a config value",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		value, err := config.GetValue(cfg, args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Update a config value",
+	Long: `Validate and save a single config value.
+
+List values (daemon.personas) are comma-separated.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Other settings may reference environment variables; keep them as written
+		cfg, err := config.LoadRaw()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if err := config.SetValue(cfg, args[0], args[1]); err != nil {
+			return err
+		}
+
+		if err := config.Save(cfg); err != nil {
+			return err
+		}
+
+		value, _ := config.GetValue(cfg, args[0])
+		fmt.Printf("Set %s = %s\n", args[0], value)
+		if profile := config.ActiveProfile(); profile != "" {
+			fmt.Printf("Note: this changed the base settings; profile '%s' may override it (edit profiles in config.yaml)\n", profile)
+		}
+		return nil
+	},
+}
+
+var configProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List the profiles defined in config.yaml",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadRaw()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		names := config.ProfileNames(cfg)
+		if len(names) == 0 {
+			fmt.Println("No profiles defined. Add a profiles: section to config.yaml.")
+			return nil
+		}
+
+		active := config.ActiveProfile()
+		for _, name := range names {
+			if name == active {
+				fmt.Printf("* %s (active)\n", name)
+			} else {
+				fmt.Printf("  %s\n", name)
+			}
+		}
+		return nil
+	},
+}
+
+// Flags for config reset-prompts
+var configResetPromptsForce bool
+
+var configResetPromptsCmd = &cobra.Command{
+	Use:   "reset-prompts",
+	Short: "Restore the bundled prompt templates",
+	Long: `Overwrite system_prompt.md, message_prompt.md, and digest_prompt.md with the
+defaults shipped with jernel. Each existing file is first copied to a .bak
+next to it, replacing any earlier backup. A combined prompt.md is moved to
+prompt.md.bak so the restored files take effect. config.yaml and personas are
+not touched.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths, err := config.PromptPaths()
+		if err != nil {
+			return err
+		}
+
+		if !configResetPromptsForce {
+			fmt.Println("This will overwrite:")
+			for _, path := range paths {
+				fmt.Printf("  %s\n", path)
+			}
+			fmt.Print("Type 'yes' to confirm: ")
+
+			reader := bufio.NewReader(os.Stdin)
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read input: %w", err)
+			}
+			if strings.TrimSpace(strings.ToLower(input)) != "yes" {
+				fmt.Println("Aborted.")
+				return nil
+			}
+		}
+
+		backups, err := config.ResetPrompts()
+		for _, backup := range backups {
+			fmt.Printf("Backed up %s\n", backup)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Restored %d prompt %s to the defaults.\n", len(paths), pluralize(len(paths), "template", "templates"))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configResetPromptsCmd)
+	configCmd.AddCommand(configProfilesCmd)
+	configResetPromptsCmd.Flags().BoolVar(&configResetPromptsForce, "force", false, "Overwrite without asking for confirmation")
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -11,16 +12,25 @@ import (
 
 	"github.com/cldixon/jernel/internal/config"
 	"github.com/cldixon/jernel/internal/daemon"
+	"github.com/cldixon/jernel/internal/store"
+	"github.com/cldixon/jernel/internal/timefmt"
 	"github.com/spf13/cobra"
 )
 
 // Flags for daemon start command
 var (
-	daemonRate       int
-	daemonRatePeriod string
-	daemonPersonas   string
+	daemonRate        int
+	daemonRatePeriod  string
+	daemonPersonas    string
+	daemonMode        string
+	daemonSelection   string
+	daemonMetricsAddr string
+	daemonLogFormat   string
 )
 
+// Flags for daemon install
+var daemonInstallForce bool
+
 var daemonCmd = &cobra.Command{
 	Use:   "daemon",
 	Short: "Manage the jernel daemon for autonomous entry generation",
@@ -31,10 +41,17 @@ Configure defaults in ~/.config/jernel/config.yaml:
 
   daemon:
     rate: 3           # entries per period
-    rate_period: day  # hour, day, or week
-    personas:         # personas to randomly select from
+    rate_period: day  # minute, hour, day, week, or month (30 days)
+    personas:         # personas to select from
       - default
       - dramatic
+    mode: single      # single (one persona) or all (every persona) per trigger
+    selection: random # how single mode picks: random or round_robin (each in turn)
+    jitter: 1         # 0 = exact intervals, 1 = anywhere from 0.5x to 1.5x the average
+    min_interval: 30m # never wait less than this between entries (empty = no floor)
+    metrics_addr: ""  # e.g. ":9099" to serve Prometheus metrics at /metrics
+    log_format: text  # text or json (one structured object per line)
+    catch_up: true    # on start, write one entry right away if the last run missed its trigger
 
 Or override with flags: jernel daemon start --rate 5 --rate-period day`,
 }
@@ -45,43 +62,53 @@ var daemonStartCmd = &cobra.Command{
 	Long: `Start the jernel daemon in the foreground. The daemon will generate
 journal entries at random intervals based on your configuration.
 
-Use Ctrl+C to stop the daemon gracefully.
+Use Ctrl+C to stop the daemon gracefully. Send SIGHUP to reload config.yaml
+without restarting (kill -HUP <pid>); the next entry is rescheduled with the
+new rate, period, and personas.
 
-Flags override config.yaml settings for this run only.`,
+Flags override config.yaml settings for this run only, and keep overriding
+them after a reload.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load()
-		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
-		}
-
-		// Apply flag overrides
-		if cmd.Flags().Changed("rate") {
-			cfg.Daemon.Rate = daemonRate
-		}
-		if cmd.Flags().Changed("rate-period") {
-			cfg.Daemon.RatePeriod = daemonRatePeriod
-		}
-		if cmd.Flags().Changed("personas") {
-			if daemonPersonas != "" {
-				cfg.Daemon.Personas = strings.Split(daemonPersonas, ",")
-			} else {
-				cfg.Daemon.Personas = []string{}
+		loadConfig := func() (*config.Config, error) {
+			cfg, err := config.Load()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load config: %w", err)
 			}
+			applyDaemonStartFlags(cmd, cfg)
+			return cfg, nil
 		}
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		warnPromptProblems()
 
 		// Create daemon
 		d := daemon.New(cfg)
+		d.LoadConfig = loadConfig
 
 		// Set up signal handling
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
+		hupChan := make(chan os.Signal, 1)
+		signal.Notify(hupChan, syscall.SIGHUP)
+		defer signal.Stop(hupChan)
+		go func() {
+			for range hupChan {
+				d.Reload()
+			}
+		}()
+
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 		go func() {
 			<-sigChan
-			fmt.Println("\nReceived shutdown signal...")
+			// Keep stdout parseable when logging JSON; the daemon logs the shutdown itself
+			if cfg.Daemon.LogFormat != daemon.LogFormatJSON {
+				fmt.Println("\nReceived shutdown signal...")
+			}
 			d.Stop()
 			cancel()
 		}()
@@ -98,6 +125,86 @@ Flags override config.yaml settings for this run only.`,
 	},
 }
 
+// applyDaemonStartFlags overrides cfg with the daemon start flags that were set
+func applyDaemonStartFlags(cmd *cobra.Command, cfg *config.Config) {
+	if cmd.Flags().Changed("rate") {
+		cfg.Daemon.Rate = daemonRate
+	}
+	if cmd.Flags().Changed("rate-period") {
+		cfg.Daemon.RatePeriod = daemonRatePeriod
+	}
+	if cmd.Flags().Changed("mode") {
+		cfg.Daemon.Mode = daemonMode
+	}
+	if cmd.Flags().Changed("selection") {
+		cfg.Daemon.Selection = daemonSelection
+	}
+	if cmd.Flags().Changed("metrics-addr") {
+		cfg.Daemon.MetricsAddr = daemonMetricsAddr
+	}
+	if cmd.Flags().Changed("log-format") {
+		cfg.Daemon.LogFormat = daemonLogFormat
+	}
+	if cmd.Flags().Changed("personas") {
+		if daemonPersonas != "" {
+			cfg.Daemon.Personas = strings.Split(daemonPersonas, ",")
+		} else {
+			cfg.Daemon.Personas = []string{}
+		}
+	}
+}
+
+var daemonRunOnceCmd = &cobra.Command{
+	Use:   "run-once",
+	Short: "Generate one trigger's entries and exit",
+	Long: `Generate the entries for a single daemon trigger and exit, for scheduling
+jernel with cron instead of a long-running daemon. Personas are chosen the way
+the daemon chooses them: one from daemon.personas (at random, or in turn with
+selection: round_robin), or all of them in "all" mode.
+
+No PID file is written, but the entries are recorded in the daemon state file
+so 'jernel daemon status' and 'jernel daemon undo' see them. Refuses while a
+daemon is running. For example, three entries a day from cron:
+
+  0 9,14,20 * * * jernel daemon run-once`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		applyDaemonStartFlags(cmd, cfg)
+		warnPromptProblems()
+
+		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer cancel()
+		return daemon.New(cfg).RunOnce(ctx)
+	},
+}
+
+var daemonReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Reload config in the running daemon",
+	Long: `Signal the running daemon (SIGHUP) to re-read config.yaml and reschedule its
+next entry with the new rate, period, and personas. Its uptime, counters, and
+state are kept. Changes to log_format and metrics_addr need a restart.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		running, pid, err := daemon.IsRunning()
+		if err != nil {
+			return fmt.Errorf("failed to check daemon status: %w", err)
+		}
+		if !running {
+			fmt.Println("Daemon is not running")
+			return nil
+		}
+
+		if err := daemon.ReloadRunning(); err != nil {
+			return fmt.Errorf("failed to reload daemon: %w", err)
+		}
+		fmt.Printf("Asked the daemon (PID: %d) to reload its config; check its log for the new schedule\n", pid)
+		return nil
+	},
+}
+
 var daemonStopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stop the running daemon",
@@ -148,36 +255,207 @@ var daemonStatusCmd = &cobra.Command{
 		}
 
 		fmt.Println("Daemon Configuration:")
+		if profile := config.ActiveProfile(); profile != "" {
+			fmt.Printf("  Profile:     %s\n", profile)
+		}
 		fmt.Printf("  Rate:        %d per %s\n", cfg.Daemon.Rate, cfg.Daemon.RatePeriod)
 		if len(cfg.Daemon.Personas) > 0 {
 			fmt.Printf("  Personas:    %v\n", cfg.Daemon.Personas)
 		} else {
 			fmt.Printf("  Personas:    [%s] (default)\n", cfg.DefaultPersona)
 		}
+		if cfg.Daemon.Mode == daemon.ModeAll {
+			fmt.Printf("  Mode:        all personas per trigger\n")
+		} else if cfg.Daemon.Selection == daemon.SelectionRoundRobin {
+			fmt.Printf("  Selection:   round robin\n")
+		}
+		if cfg.Daemon.MetricsAddr != "" {
+			fmt.Printf("  Metrics:     http://%s/metrics\n", cfg.Daemon.MetricsAddr)
+		}
 		fmt.Println()
 
 		if !running {
 			fmt.Println("Status: NOT RUNNING")
+			// A stopped daemon or 'daemon run-once' leaves its last entry in the state file
+			if state, err := daemon.LoadState(); err == nil && state != nil && !state.LastEntryAt.IsZero() {
+				fmt.Printf("  Last entry:  %s (persona: %s)\n",
+					timefmt.Format(state.LastEntryAt, time.RFC1123), state.LastPersona)
+			}
 			return nil
 		}
 
-		fmt.Printf("Status: RUNNING (PID: %d)\n", pid)
-
 		// Load state for more details
 		state, err := daemon.LoadState()
 		if err != nil {
+			fmt.Printf("Status: RUNNING (PID: %d)\n", pid)
 			fmt.Printf("  (could not load state: %v)\n", err)
 			return nil
 		}
 
-		if state != nil {
-			fmt.Printf("  Started:     %s\n", state.StartedAt.Format(time.RFC1123))
-			fmt.Printf("  Next entry:  %s\n", state.NextTrigger.Format(time.RFC1123))
-			fmt.Printf("  Entries:     %d generated\n", state.EntriesGenerated)
-			if !state.LastEntryAt.IsZero() {
-				fmt.Printf("  Last entry:  %s (persona: %s)\n",
-					state.LastEntryAt.Format(time.RFC1123), state.LastPersona)
-			}
+		if state == nil {
+			fmt.Printf("Status: RUNNING (PID: %d)\n", pid)
+			return nil
+		}
+
+		if stale, reason := state.Stale(time.Now()); stale {
+			fmt.Printf("Status: STALE (PID: %d) - %s\n", pid, reason)
+		} else {
+			fmt.Printf("Status: RUNNING (PID: %d)\n", pid)
+		}
+
+		if !state.Heartbeat.IsZero() {
+			fmt.Printf("  Heartbeat:   %s\n", timefmt.Format(state.Heartbeat, time.RFC1123))
+		}
+		fmt.Printf("  Started:     %s\n", timefmt.Format(state.StartedAt, time.RFC1123))
+		fmt.Printf("  Next entry:  %s\n", timefmt.Format(state.NextTrigger, time.RFC1123))
+		fmt.Printf("  Entries:     %d generated\n", state.EntriesGenerated)
+		if state.Errors > 0 {
+			fmt.Printf("  Errors:      %d\n", state.Errors)
+		}
+		if !state.LastEntryAt.IsZero() {
+			fmt.Printf("  Last entry:  %s (persona: %s)\n",
+				timefmt.Format(state.LastEntryAt, time.RFC1123), state.LastPersona)
+		}
+
+		return nil
+	},
+}
+
+var daemonPingCmd = &cobra.Command{
+	Use:   "ping",
+	Short: "Check that the daemon is alive",
+	Long: `Exit with status 0 if the daemon is running and its heartbeat is fresh,
+or non-zero otherwise. Suitable for cron or service health checks.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := daemon.Ping()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("ok (PID: %d, heartbeat %s ago)\n", state.PID, time.Since(state.Heartbeat).Round(time.Second))
+		return nil
+	},
+}
+
+// Flags for daemon undo
+var daemonUndoYesFlag bool
+
+var daemonUndoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Move the daemon's last entry to the trash",
+	Long: `Move the most recent entry generated by the daemon (or run-once) to the
+trash, after confirmation (--yes skips it). Restore it with 'jernel trash restore <id>' if needed.
+Works while the daemon is running, e.g. to drop a bad entry while tuning it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := daemon.LoadState()
+		if err != nil {
+			return err
+		}
+		if state == nil || state.LastEntryID == 0 {
+			fmt.Println("Nothing to undo.")
+			return nil
+		}
+
+		db, err := store.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		// Lookups skip trashed entries, so an entry already moved to the trash
+		// (by undo or by hand) counts as nothing to undo
+		e, err := db.GetByID(state.LastEntryID)
+		if errors.Is(err, store.ErrEntryNotFound) {
+			fmt.Println("Nothing to undo.")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Last daemon entry: #%d [%s] %s\n", e.ID, e.Persona, timefmt.Format(e.CreatedAt, timefmt.Short))
+		fmt.Printf("  %s\n\n", truncateLine(e.Content, 70))
+		if ok, err := confirm(daemonUndoYesFlag); err != nil || !ok {
+			return err
+		}
+
+		// The state file is left alone since a running daemon owns it; its
+		// LastEntryID now points at a trashed entry, which reads as nothing to undo
+		if err := db.Delete(e.ID); err != nil {
+			return err
+		}
+
+		fmt.Printf("Moved entry #%d to the trash.\n", e.ID)
+		return nil
+	},
+}
+
+// truncateLine flattens content to a single line of at most maxLen characters
+func truncateLine(content string, maxLen int) string {
+	line := strings.Join(strings.Fields(content), " ")
+	if runes := []rune(line); len(runes) > maxLen {
+		return string(runes[:maxLen-1]) + "…"
+	}
+	return line
+}
+
+var daemonInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the daemon as a user service",
+	Long: `Write a systemd user unit (Linux) or launchd agent (macOS) that runs
+'jernel daemon start' with the current executable, so the daemon survives
+reboots. Prints the commands to enable and start it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		svc, err := daemon.CurrentService()
+		if err != nil {
+			return err
+		}
+
+		if err := svc.Install(daemonInstallForce); err != nil {
+			return err
+		}
+
+		fmt.Printf("Installed %s service for %s\n", svc.Platform, svc.Executable)
+		fmt.Printf("  %s\n\n", svc.Path)
+
+		fmt.Println("Enable and start it with:")
+		for _, c := range svc.Enable {
+			fmt.Printf("  %s\n", c)
+		}
+		fmt.Println()
+
+		switch svc.Platform {
+		case "systemd":
+			fmt.Printf("The service does not inherit your shell environment. Put ANTHROPIC_API_KEY=... in\n  %s\n", svc.EnvFile)
+		case "launchd":
+			fmt.Println("The agent does not inherit your shell environment. Make ANTHROPIC_API_KEY available with:")
+			fmt.Println("  launchctl setenv ANTHROPIC_API_KEY your-key-here")
+			fmt.Printf("Daemon output is written to %s\n", svc.LogPath)
+		}
+
+		return nil
+	},
+}
+
+var daemonUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the daemon user service",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		svc, err := daemon.CurrentService()
+		if err != nil {
+			return err
+		}
+
+		if err := svc.Uninstall(); err != nil {
+			return err
+		}
+
+		fmt.Printf("Removed %s\n\n", svc.Path)
+		fmt.Println("If the service is still loaded, stop it with:")
+		for _, c := range svc.Disable {
+			fmt.Printf("  %s\n", c)
 		}
 
 		return nil
@@ -188,10 +466,28 @@ func init() {
 	rootCmd.AddCommand(daemonCmd)
 	daemonCmd.AddCommand(daemonStartCmd)
 	daemonCmd.AddCommand(daemonStopCmd)
+	daemonCmd.AddCommand(daemonReloadCmd)
+	daemonCmd.AddCommand(daemonRunOnceCmd)
 	daemonCmd.AddCommand(daemonStatusCmd)
+	daemonCmd.AddCommand(daemonPingCmd)
+	daemonCmd.AddCommand(daemonUndoCmd)
+	daemonUndoCmd.Flags().BoolVarP(&daemonUndoYesFlag, "yes", "y", false, "Skip the confirmation prompt")
+	daemonCmd.AddCommand(daemonInstallCmd)
+	daemonCmd.AddCommand(daemonUninstallCmd)
 
 	// Flags for daemon start
 	daemonStartCmd.Flags().IntVar(&daemonRate, "rate", 0, "Number of entries per period (overrides config)")
-	daemonStartCmd.Flags().StringVar(&daemonRatePeriod, "rate-period", "", "Period for rate: hour, day, or week (overrides config)")
+	daemonStartCmd.Flags().StringVar(&daemonRatePeriod, "rate-period", "", "Period for rate: minute, hour, day, week, or month (overrides config)")
 	daemonStartCmd.Flags().StringVar(&daemonPersonas, "personas", "", "Comma-separated list of personas (overrides config)")
+	daemonStartCmd.Flags().StringVar(&daemonMode, "mode", "", "Generation mode: single or all (overrides config)")
+	daemonStartCmd.Flags().StringVar(&daemonSelection, "selection", "", "Persona selection in single mode: random or round_robin (overrides config)")
+	daemonStartCmd.Flags().StringVar(&daemonMetricsAddr, "metrics-addr", "", "Serve Prometheus metrics on this address, e.g. :9099 (overrides config)")
+	daemonStartCmd.Flags().StringVar(&daemonLogFormat, "log-format", "", "Log format: text or json (overrides config)")
+
+	daemonRunOnceCmd.Flags().StringVar(&daemonPersonas, "personas", "", "Comma-separated list of personas (overrides config)")
+	daemonRunOnceCmd.Flags().StringVar(&daemonMode, "mode", "", "Generation mode: single or all (overrides config)")
+	daemonRunOnceCmd.Flags().StringVar(&daemonSelection, "selection", "", "Persona selection in single mode: random or round_robin (overrides config)")
+	daemonRunOnceCmd.Flags().StringVar(&daemonLogFormat, "log-format", "", "Log format: text or json (overrides config)")
+
+	daemonInstallCmd.Flags().BoolVar(&daemonInstallForce, "force", false, "Overwrite an existing service file")
 }
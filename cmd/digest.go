@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cldixon/jernel/internal/config"
+	"github.com/cldixon/jernel/internal/entry"
+	"github.com/cldixon/jernel/internal/timefmt"
+	"github.com/spf13/cobra"
+)
+
+// Flags for digest
+var digestPeriodFlag string
+var digestModelFlag string
+var digestProviderFlag string
+
+// digestLong is the digest help text; the help func appends the template path
+const digestLong = `Generate a reflective summary of the journal entries written over the last
+day, week, or month, across all personas.
+
+The digest is saved as an entry under the ` + entry.DigestPersona + ` persona and tagged
+"` + entry.DigestTag + `", so it can be found with 'jernel entry list --tag ` + entry.DigestTag + `'.`
+
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Summarize recent entries into a digest",
+	Long:  digestLong + "\nThe prompt template is digest_prompt.md in the config directory.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !validDigestPeriod(digestPeriodFlag) {
+			return fmt.Errorf("invalid period: %s (must be one of: %s)", digestPeriodFlag, strings.Join(entry.DigestPeriods, ", "))
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if err := applyModelOverrides(cfg, digestModelFlag, digestProviderFlag); err != nil {
+			return err
+		}
+
+		fmt.Printf("Writing a digest of the last %s...\n", digestPeriodFlag)
+
+		result, err := entry.Digest(context.Background(), cfg, digestPeriodFlag)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("\nSummarized %d %s from %s to %s\n\n", result.Summary, pluralize(result.Summary, "entry", "entries"),
+			timefmt.Format(result.Start, timefmt.Day), timefmt.Format(result.End, timefmt.Day))
+		fmt.Println("---")
+		fmt.Println(result.Entry.Content)
+		fmt.Println("---")
+		fmt.Printf("\nSaved as entry #%d\n", result.Entry.ID)
+
+		return nil
+	},
+}
+
+func validDigestPeriod(period string) bool {
+	for _, p := range entry.DigestPeriods {
+		if period == p {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(digestCmd)
+
+	// Help runs without the root pre-run, so resolve the config flags here to
+	// name the template the digest will actually use
+	defaultHelp := digestCmd.HelpFunc()
+	digestCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		if applyConfigFlags() == nil {
+			if path, err := config.DigestPromptPath(); err == nil {
+				cmd.Long = digestLong + "\nThe prompt template lives in " + path + "."
+			}
+		}
+		defaultHelp(cmd, args)
+	})
+
+	digestCmd.Flags().StringVar(&digestPeriodFlag, "period", "week", "Range to summarize: day, week, or month")
+	digestCmd.Flags().StringVar(&digestModelFlag, "model", "", "Model to use for this run (defaults to config setting)")
+	digestCmd.Flags().StringVar(&digestProviderFlag, "provider", "", "Provider for this run; only anthropic is supported so far")
+}
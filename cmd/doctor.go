@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cldixon/jernel/internal/config"
+	"github.com/cldixon/jernel/internal/llm"
+	"github.com/cldixon/jernel/internal/persona"
+	"github.com/cldixon/jernel/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configuration for problems",
+	Long: `Check config.yaml, the default persona, the message prompt template, and
+the API key, and report anything that would stop an entry from being written.
+Exits non-zero if a check fails.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		failed := 0
+		check := func(name string, err error) {
+			if err != nil {
+				failed++
+				fmt.Printf("%s %s: %v\n", styled(accentStyle, "✗"), name, err)
+				return
+			}
+			fmt.Printf("%s %s\n", styled(dimStyle, "✓"), name)
+		}
+		// Warnings are printed but don't fail the run
+		warn := func(err error) {
+			if err != nil {
+				fmt.Printf("%s %v\n", styled(accentStyle, "!"), err)
+			}
+		}
+
+		cfg, err := config.Load()
+		check("config.yaml", err)
+		if err == nil {
+			_, err = persona.Get(cfg.DefaultPersona)
+			check("default persona", err)
+
+			_, _, err = llm.ResolveAPIKey(cfg)
+			if err == nil {
+				err = llm.CheckAPIKeyFile(cfg)
+			}
+			check("API key", err)
+		}
+		check("message prompt", prompt.ValidateMessagePrompt())
+		warn(prompt.CheckMessagePromptLength())
+
+		if failed > 0 {
+			return fmt.Errorf("%d %s failed", failed, pluralize(failed, "check", "checks"))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
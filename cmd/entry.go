@@ -1,29 +1,58 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/cldixon/jernel/internal/config"
 	"github.com/cldixon/jernel/internal/entry"
+	"github.com/cldixon/jernel/internal/persona"
+	"github.com/cldixon/jernel/internal/prompt"
 	"github.com/cldixon/jernel/internal/store"
+	"github.com/cldixon/jernel/internal/timefmt"
 	"github.com/spf13/cobra"
 )
 
 var entryCmd = &cobra.Command{
 	Use:   "entry",
 	Short: "Manage journal entries",
-	Long:  `Create, list, and read journal entries.`,
+	Long:  `Create, list, read, tag, and move journal entries, or revisit a random one.`,
 }
 
 // Flags for entry create
 var entryCreatePersonaFlag string
+var entryCreateCountFlag int
+var entryCreateModelFlag string
+var entryCreateProviderFlag string
+var entryCreateInteractiveFlag bool
+var entryCreateNoteFlag string
+var entryCreateDescriptionFlag string
+
+// entryCreateDelay spaces out generations when creating several entries
+const entryCreateDelay = 2 * time.Second
 
 var entryCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new journal entry",
-	Long:  `Generate a new journal entry using system metrics and the specified persona.`,
+	Long: `Generate a new journal entry using system metrics and the specified persona.
+
+Use --count to generate several entries in a row; they share one metrics snapshot,
+which is re-sampled once it is a minute old.
+Use --model to try a different model for this run without editing config.
+--provider only accepts anthropic, the one provider supported so far.
+Use --interactive to pick the persona from a numbered list.
+Use --note to tell the persona about something that happened, e.g.
+--note "today I upgraded the RAM", or --note - to read the note from stdin.
+Use --description for a one-off voice without creating a persona file, e.g.
+--description "terse and grumpy"; the entry is saved under the ` + entry.AdHocPersona + ` persona.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 
@@ -32,15 +61,52 @@ var entryCreateCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
+		if err := applyModelOverrides(cfg, entryCreateModelFlag, entryCreateProviderFlag); err != nil {
+			return err
+		}
+
 		personaName := entryCreatePersonaFlag
 		if personaName == "" {
 			personaName = cfg.DefaultPersona
 		}
+		note, err := readNote(entryCreateNoteFlag)
+		if err != nil {
+			return err
+		}
+		opts := entry.Options{Note: note, Description: entryCreateDescriptionFlag}
+
+		if entryCreateDescriptionFlag != "" {
+			if strings.TrimSpace(entryCreateDescriptionFlag) == "" {
+				return fmt.Errorf("--description cannot be empty")
+			}
+			if entryCreatePersonaFlag != "" || entryCreateInteractiveFlag {
+				return fmt.Errorf("--description can't be combined with --persona or --interactive")
+			}
+			personaName = entry.AdHocPersona
+		}
+
+		if entryCreateInteractiveFlag {
+			if entryCreateNoteFlag == "-" {
+				return fmt.Errorf("--note - reads stdin and can't be combined with --interactive")
+			}
+			personaName, err = promptForPersona(cfg.DefaultPersona)
+			if err != nil {
+				return err
+			}
+		}
+
+		if entryCreateCountFlag < 1 {
+			return fmt.Errorf("invalid count: %d (must be at least 1)", entryCreateCountFlag)
+		}
+		warnPromptProblems()
+		if entryCreateCountFlag > 1 {
+			return createEntries(ctx, cfg, personaName, entryCreateCountFlag, opts)
+		}
 
 		fmt.Printf("Creating a new jernel entry with persona: %s\n\n", personaName)
 		fmt.Println("Gathering system metrics and generating entry...")
 
-		result, err := entry.Generate(ctx, cfg, personaName)
+		result, err := entry.GenerateWithOptions(ctx, cfg, personaName, opts)
 		if err != nil {
 			return err
 		}
@@ -55,18 +121,147 @@ var entryCreateCmd = &cobra.Command{
 		fmt.Println("---")
 		fmt.Printf("\nSaved as entry #%d\n", result.Entry.ID)
 
+		// The entry was just printed in full, so it shouldn't sit in the unread queue
+		if db, err := store.Open(); err == nil {
+			db.MarkRead(result.Entry.ID)
+			db.Close()
+		}
+
 		return nil
 	},
 }
 
+// promptForPersona lists the available personas and asks the user to pick one
+// by number; pressing enter keeps the default. With at most one persona there
+// is nothing to choose, so no prompt is shown
+func promptForPersona(defaultName string) (string, error) {
+	names, err := persona.List()
+	if err != nil {
+		return "", fmt.Errorf("failed to list personas: %w", err)
+	}
+	if len(names) == 0 {
+		return defaultName, nil
+	}
+	if len(names) == 1 {
+		return names[0], nil
+	}
+
+	fmt.Println("Select a persona:")
+	for i, name := range names {
+		marker := ""
+		if name == defaultName {
+			marker = " (default)"
+		}
+		fmt.Printf("  %d. %s%s\n", i+1, name, marker)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Enter a number [default: %s]: ", defaultName)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			return defaultName, nil
+		}
+
+		n, err := strconv.Atoi(input)
+		if err == nil && n >= 1 && n <= len(names) {
+			fmt.Println()
+			return names[n-1], nil
+		}
+		fmt.Printf("Please enter a number between 1 and %d.\n", len(names))
+	}
+}
+
+// readNote returns the --note text, reading it from stdin when the flag is "-"
+func readNote(flag string) (string, error) {
+	if flag != "-" {
+		return strings.TrimSpace(flag), nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read note from stdin: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// applyModelOverrides replaces the configured provider and model for one run
+// Empty values keep the config settings
+func applyModelOverrides(cfg *config.Config, model string, provider string) error {
+	if provider != "" {
+		if err := config.SetValue(cfg, "provider", provider); err != nil {
+			return err
+		}
+	}
+	if model != "" {
+		if err := config.SetValue(cfg, "model", model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createEntries generates count entries back to back with opts, continuing past failures
+func createEntries(ctx context.Context, cfg *config.Config, personaName string, count int, opts entry.Options) error {
+	fmt.Printf("Creating %d jernel entries with persona: %s\n\n", count, personaName)
+
+	var ids []string
+	failed := 0
+	for i := 1; i <= count; i++ {
+		if i > 1 {
+			time.Sleep(entryCreateDelay)
+		}
+
+		fmt.Printf("[%d/%d] Generating entry... ", i, count)
+		// Reuse one metrics sample across the batch instead of re-sampling the CPU each time
+		opts.MetricsMaxAge = entry.BatchMetricsMaxAge
+		result, err := entry.GenerateWithOptions(ctx, cfg, personaName, opts)
+		if err != nil {
+			failed++
+			fmt.Printf("failed: %v\n", err)
+			continue
+		}
+		ids = append(ids, fmt.Sprintf("#%d", result.Entry.ID))
+		fmt.Printf("saved as entry #%d\n", result.Entry.ID)
+	}
+
+	fmt.Printf("\nCreated %d of %d %s", len(ids), count, pluralize(count, "entry", "entries"))
+	if len(ids) > 0 {
+		fmt.Printf(" (%s)", strings.Join(ids, ", "))
+	}
+	fmt.Println()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d generations failed", failed, count)
+	}
+	return nil
+}
+
 // Flags for entry list
 var entryListLimitFlag int
 var entryListPersonaFlag string
+var entryListTagFlag string
+var entryListModelFlag string
+var entryListMoodFlag string
+var entryListFullFlag bool
+var entryListGroupFlag bool
 
 var entryListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List journal entries",
-	Long:  `List journal entries with optional filtering by persona.`,
+	Long: `List journal entries with optional filtering by persona, tag, model, or mood.
+
+Use --full to print each entry in full instead of a one-line summary, e.g.
+to page through the whole journal:
+
+  jernel entry list --full --limit 0 | less
+
+Use --group-by-persona to print each persona's entries under its own header,
+with --limit applied to every group.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		db, err := store.Open()
 		if err != nil {
@@ -74,33 +269,107 @@ var entryListCmd = &cobra.Command{
 		}
 		defer db.Close()
 
-		var entries []*store.Entry
-		if entryListPersonaFlag != "" {
-			entries, err = db.ListByPersona(entryListPersonaFlag, entryListLimitFlag)
-		} else {
-			entries, err = db.List(entryListLimitFlag)
-		}
-		if err != nil {
-			return err
+		filter := store.Filter{
+			Persona: entryListPersonaFlag,
+			Tag:     entryListTagFlag,
+			Model:   entryListModelFlag,
+			Mood:    entryListMoodFlag,
+			Limit:   entryListLimitFlag,
 		}
 
-		if len(entries) == 0 {
-			fmt.Println("No entries found.")
+		// Entries are printed as they are read so large journals don't pile up in memory
+		found := 0
+		printListed := func(e *store.Entry) error {
+			if entryListFullFlag {
+				if found > 0 {
+					fmt.Println()
+				}
+				printEntry(e, false)
+			} else {
+				fmt.Printf("#%d [%s] %s%s\n", e.ID, e.Persona, timefmt.Format(e.CreatedAt, timefmt.Short), formatTags(e.Tags))
+			}
+			found++
 			return nil
 		}
 
-		for _, e := range entries {
-			fmt.Printf("#%d [%s] %s\n", e.ID, e.Persona, e.CreatedAt.Format("Jan 02, 2006 3:04 PM"))
+		if entryListGroupFlag {
+			groups, err := db.CountPerPersona()
+			if err != nil {
+				return err
+			}
+			sort.Slice(groups, func(i, j int) bool { return groups[i].Value < groups[j].Value })
+
+			for _, g := range groups {
+				filter.Persona = g.Value
+				header := false
+				err := db.EachFiltered(filter, func(e *store.Entry) error {
+					// Headers are printed lazily so tag and model filters don't leave empty groups
+					if !header {
+						if found > 0 {
+							fmt.Println()
+						}
+						fmt.Println(styled(accentStyle, g.Value))
+						header = true
+					}
+					return printListed(e)
+				})
+				if err != nil {
+					return err
+				}
+			}
+		} else if err := db.EachFiltered(filter, printListed); err != nil {
+			return err
+		}
+
+		if found == 0 {
+			fmt.Println("No entries found.")
 		}
 		return nil
 	},
 }
 
+// Flags for entry read
+var entryReadShowPromptFlag bool
+var entryReadPeekFlag bool
+var entryReadMetricsFlag bool
+var entryReadFormatFlag string
+var entryReadNextFlag bool
+var entryReadPrevFlag bool
+
 var entryReadCmd = &cobra.Command{
 	Use:   "read [id]",
 	Short: "Read a journal entry",
-	Long:  `Read a specific journal entry by ID, or the most recent entry if no ID is provided.`,
+	Long: `Read a specific journal entry by ID, or the most recent entry if no ID is provided.
+
+Use --next or --prev to read the entry written just after or just before it
+instead, e.g. to page through the journal like a book:
+
+  jernel entry read 42 --next
+
+The entry is printed as Markdown with YAML frontmatter, the format 'jernel import'
+reads, so it can be saved as is. Use --metrics to add its metrics snapshot to the
+frontmatter, e.g. to save a full copy:
+
+  jernel entry read 42 --metrics > entry-42.md
+
+The entry is marked as read; use --peek to leave it unread.
+
+Use --format to print the entry through a Go template instead. The template sees
+the entry's fields (.ID, .Persona, .Content, .CreatedAt, .ModelID, .Tags, ...)
+and its metrics under .MetricsSnapshot, e.g.:
+
+  jernel entry read 42 --format '{{.ID}}: {{.Content}}'
+  jernel entry read --format '{{.CreatedAt.Format "2006-01-02"}} cpu={{.MetricsSnapshot.CPUPercent}}'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		var format *template.Template
+		if entryReadFormatFlag != "" {
+			var err error
+			format, err = parseEntryFormat(entryReadFormatFlag)
+			if err != nil {
+				return err
+			}
+		}
+
 		db, err := store.Open()
 		if err != nil {
 			return fmt.Errorf("failed to open database: %w", err)
@@ -129,24 +398,235 @@ var entryReadCmd = &cobra.Command{
 			e = entries[0]
 		}
 
-		printEntry(e)
+		if entryReadNextFlag || entryReadPrevFlag {
+			prev, next, err := db.GetNeighbors(e.ID)
+			if err != nil {
+				return err
+			}
+			switch {
+			case entryReadNextFlag && next == nil:
+				fmt.Printf("Entry #%d is the newest entry.\n", e.ID)
+				return nil
+			case entryReadPrevFlag && prev == nil:
+				fmt.Printf("Entry #%d is the oldest entry.\n", e.ID)
+				return nil
+			case entryReadNextFlag:
+				e = next
+			default:
+				e = prev
+			}
+		}
+
+		switch {
+		case format != nil:
+			if err := printEntryFormat(format, e); err != nil {
+				return err
+			}
+		default:
+			printEntry(e, entryReadMetricsFlag)
+		}
+		if entryReadShowPromptFlag {
+			printPrompt(e)
+		}
+
+		if !entryReadPeekFlag && !e.IsRead {
+			if err := db.MarkRead(e.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// Flags for entry random
+var entryRandomPersonaFlag string
+
+var entryRandomCmd = &cobra.Command{
+	Use:   "random",
+	Short: "Read a random past entry",
+	Long:  `Print a randomly chosen journal entry, optionally from one persona, to rediscover something forgotten.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := store.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		e, err := db.Random(store.Filter{Persona: entryRandomPersonaFlag})
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			if entryRandomPersonaFlag != "" {
+				fmt.Printf("No entries from %s yet.\n", entryRandomPersonaFlag)
+			} else {
+				fmt.Println("No entries yet. Create one with 'jernel entry create'")
+			}
+			return nil
+		}
+
+		printEntry(e, false)
+		if !e.IsRead {
+			return db.MarkRead(e.ID)
+		}
+		return nil
+	},
+}
+
+// Flags for entry tag
+var entryTagRemoveFlag bool
+
+var entryTagCmd = &cobra.Command{
+	Use:   "tag <id> <tag>",
+	Short: "Tag a journal entry",
+	Long: `Attach a tag such as "milestone" or "funny" to an entry. Tags are lowercased and trimmed.
+
+Use --remove to detach a tag.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid entry ID: %s", args[0])
+		}
+
+		db, err := store.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		tag, err := store.NormalizeTag(args[1])
+		if err != nil {
+			return err
+		}
+
+		if entryTagRemoveFlag {
+			if err := db.RemoveTag(id, tag); err != nil {
+				return err
+			}
+			fmt.Printf("Removed tag '%s' from entry #%d.\n", tag, id)
+			return nil
+		}
+
+		if err := db.AddTag(id, tag); err != nil {
+			return err
+		}
+		fmt.Printf("Tagged entry #%d with '%s'.\n", id, tag)
 		return nil
 	},
 }
 
-func printEntry(e *store.Entry) {
-	fmt.Printf("Entry #%d\n", e.ID)
-	fmt.Printf("Persona: %s\n", e.Persona)
-	fmt.Printf("Date: %s\n", e.CreatedAt.Format("Monday, January 02, 2006 at 3:04 PM"))
-	fmt.Printf("Model: %s\n", e.ModelID)
-	if e.MetricsSnapshot != nil {
-		m := e.MetricsSnapshot
-		fmt.Printf("System: CPU %.1f%% | Memory %.1f%% | Disk %.1f%% | Uptime %s\n",
-			m.CPUPercent, m.MemoryPercent, m.DiskPercent, m.Uptime)
+// Flags for entry move
+var entryMovePersonaFlag string
+var entryMoveFromFlag string
+var entryMoveToFlag string
+
+var entryMoveCmd = &cobra.Command{
+	Use:   "move [id]",
+	Short: "Move entries to a different persona",
+	Long: `Reassign a single entry with 'jernel entry move <id> --persona <name>', or move
+every entry from one persona to another with '--from <persona> --to <persona>'.
+
+The target persona must exist.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bulk := entryMoveFromFlag != "" || entryMoveToFlag != ""
+
+		var target string
+		switch {
+		case bulk && len(args) > 0:
+			return fmt.Errorf("use either an entry ID with --persona, or --from with --to")
+		case bulk:
+			if entryMoveFromFlag == "" || entryMoveToFlag == "" {
+				return fmt.Errorf("--from and --to must be used together")
+			}
+			target = entryMoveToFlag
+		default:
+			if len(args) == 0 || entryMovePersonaFlag == "" {
+				return fmt.Errorf("specify an entry ID and --persona, or --from with --to")
+			}
+			target = entryMovePersonaFlag
+		}
+
+		if _, err := persona.Get(target); err != nil {
+			return err
+		}
+
+		db, err := store.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		if bulk {
+			moved, err := db.ReassignPersona(entryMoveFromFlag, target)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Moved %d %s from '%s' to '%s'.\n", moved, pluralize(int(moved), "entry", "entries"), entryMoveFromFlag, target)
+			return nil
+		}
+
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid entry ID: %s", args[0])
+		}
+		if err := db.Reassign(id, target); err != nil {
+			return err
+		}
+		fmt.Printf("Moved entry #%d to '%s'.\n", id, target)
+		return nil
+	},
+}
+
+// formatTags renders tags as a " #tag #tag" suffix, or nothing when untagged
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
 	}
+	return " #" + strings.Join(tags, " #")
+}
+
+// printEntry prints an entry in the Markdown format 'jernel import' reads,
+// dimming the frontmatter when color is enabled
+func printEntry(e *store.Entry, includeMetrics bool) {
+	header, body, _ := strings.Cut(e.ToMarkdown(includeMetrics), "\n---\n")
+	fmt.Println(styled(dimStyle, header+"\n---"))
+	fmt.Print(body)
+}
+
+// parseEntryFormat parses a --format template, with the prompt template helpers available
+func parseEntryFormat(format string) (*template.Template, error) {
+	t, err := template.New("format").Funcs(prompt.Funcs()).Option("missingkey=error").Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	return t, nil
+}
+
+// printEntryFormat executes a --format template against an entry, ending the output with a newline
+func printEntryFormat(t *template.Template, e *store.Entry) error {
+	var buf strings.Builder
+	if err := t.Execute(&buf, e); err != nil {
+		return fmt.Errorf("failed to format entry #%d: %w", e.ID, err)
+	}
+	out := buf.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	fmt.Print(out)
+	return nil
+}
+
+func printPrompt(e *store.Entry) {
 	fmt.Println()
+	if e.PromptText == "" {
+		fmt.Println("No prompt stored for this entry.")
+		return
+	}
+	fmt.Println("Prompt:")
 	fmt.Println("---")
-	fmt.Println(e.Content)
+	fmt.Println(e.PromptText)
 	fmt.Println("---")
 }
 
@@ -156,12 +636,47 @@ func init() {
 	// entry create
 	entryCmd.AddCommand(entryCreateCmd)
 	entryCreateCmd.Flags().StringVarP(&entryCreatePersonaFlag, "persona", "p", "", "Persona to use (defaults to config setting)")
+	entryCreateCmd.Flags().IntVar(&entryCreateCountFlag, "count", 1, "Number of entries to generate")
+	entryCreateCmd.Flags().StringVar(&entryCreateModelFlag, "model", "", "Model to use for this run (defaults to config setting)")
+	entryCreateCmd.Flags().StringVar(&entryCreateProviderFlag, "provider", "", "Provider for this run; only anthropic is supported so far")
+	entryCreateCmd.Flags().BoolVarP(&entryCreateInteractiveFlag, "interactive", "i", false, "Choose the persona from a list")
+	entryCreateCmd.Flags().StringVar(&entryCreateNoteFlag, "note", "", "Recent events for the persona to react to (\"-\" reads stdin)")
+	entryCreateCmd.Flags().StringVar(&entryCreateDescriptionFlag, "description", "", "Inline persona description to use instead of a persona file")
+	entryCreateCmd.MarkFlagsMutuallyExclusive("persona", "interactive")
 
 	// entry list
 	entryCmd.AddCommand(entryListCmd)
-	entryListCmd.Flags().IntVarP(&entryListLimitFlag, "limit", "n", 10, "Number of entries to list")
+	entryListCmd.Flags().IntVarP(&entryListLimitFlag, "limit", "n", 10, "Number of entries to list (0 for all)")
 	entryListCmd.Flags().StringVarP(&entryListPersonaFlag, "persona", "p", "", "Filter by persona")
+	entryListCmd.Flags().StringVarP(&entryListTagFlag, "tag", "t", "", "Filter by tag")
+	entryListCmd.Flags().StringVar(&entryListModelFlag, "model", "", "Filter by the model that wrote the entry")
+	entryListCmd.Flags().StringVar(&entryListMoodFlag, "mood", "", "Filter by mood ("+strings.Join(entry.Moods, ", ")+")")
+	entryListCmd.Flags().BoolVar(&entryListFullFlag, "full", false, "Print each entry's full content")
+	entryListCmd.Flags().BoolVar(&entryListGroupFlag, "group-by-persona", false, "Group entries under a header for each persona")
+	entryListCmd.MarkFlagsMutuallyExclusive("persona", "group-by-persona")
 
 	// entry read
 	entryCmd.AddCommand(entryReadCmd)
+	entryReadCmd.Flags().BoolVar(&entryReadShowPromptFlag, "show-prompt", false, "Also print the prompt that generated the entry")
+	entryReadCmd.Flags().BoolVar(&entryReadPeekFlag, "peek", false, "Don't mark the entry as read")
+	entryReadCmd.Flags().BoolVar(&entryReadMetricsFlag, "metrics", false, "Include the metrics snapshot in the frontmatter")
+	entryReadCmd.Flags().StringVar(&entryReadFormatFlag, "format", "", "Print the entry with a Go template (e.g. '{{.ID}}: {{.Content}}')")
+	entryReadCmd.Flags().BoolVar(&entryReadNextFlag, "next", false, "Read the entry written after this one")
+	entryReadCmd.Flags().BoolVar(&entryReadPrevFlag, "prev", false, "Read the entry written before this one")
+	entryReadCmd.MarkFlagsMutuallyExclusive("metrics", "format")
+	entryReadCmd.MarkFlagsMutuallyExclusive("next", "prev")
+
+	// entry random
+	entryCmd.AddCommand(entryRandomCmd)
+	entryRandomCmd.Flags().StringVarP(&entryRandomPersonaFlag, "persona", "p", "", "Only pick from this persona's entries")
+
+	// entry tag
+	entryCmd.AddCommand(entryTagCmd)
+	entryTagCmd.Flags().BoolVar(&entryTagRemoveFlag, "remove", false, "Remove the tag instead of adding it")
+
+	// entry move
+	entryCmd.AddCommand(entryMoveCmd)
+	entryMoveCmd.Flags().StringVarP(&entryMovePersonaFlag, "persona", "p", "", "Persona to move the entry to")
+	entryMoveCmd.Flags().StringVar(&entryMoveFromFlag, "from", "", "Move all entries from this persona")
+	entryMoveCmd.Flags().StringVar(&entryMoveToFlag, "to", "", "Persona to move entries to (with --from)")
 }
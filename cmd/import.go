@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cldixon/jernel/internal/config"
+	"github.com/cldixon/jernel/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <dir>",
+	Short: "Import entries from Markdown files",
+	Long: `Add the .md files in a directory to the journal, keeping their dates.
+
+Files use the format written by 'jernel entry read': YAML frontmatter with a
+persona and created_at (RFC 3339, or a plain date for hand-written entries),
+then the entry text. id, model, message_id, template_hash, tags, mood, and
+metrics are optional.
+
+A file is skipped when the journal already has an entry with the same
+message_id, the same id and date, or the same persona, text (ignoring
+whitespace), and date to the second. Imported entries start out read.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dirEntries, err := os.ReadDir(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read directory: %w", err)
+		}
+		var paths []string
+		for _, d := range dirEntries {
+			if !d.IsDir() && strings.EqualFold(filepath.Ext(d.Name()), ".md") {
+				paths = append(paths, filepath.Join(args[0], d.Name()))
+			}
+		}
+		sort.Strings(paths)
+		if len(paths) == 0 {
+			fmt.Printf("No .md files found in %s\n", args[0])
+			return nil
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		db, err := store.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+		db.SetCompressMetrics(cfg.CompressMetrics)
+
+		imported, skipped, failed := 0, 0, 0
+		for _, path := range paths {
+			name := filepath.Base(path)
+			e, err := importFile(db, path)
+			switch {
+			case errors.Is(err, store.ErrEntryExists):
+				skipped++
+				fmt.Printf("Skipped %s: already in the journal\n", name)
+			case err != nil:
+				failed++
+				fmt.Printf("Failed %s: %v\n", name, err)
+			default:
+				imported++
+				fmt.Printf("Imported %s as entry #%d\n", name, e.ID)
+			}
+		}
+
+		fmt.Printf("\nImported %d, skipped %d, failed %d of %d %s\n", imported, skipped, failed, len(paths), pluralize(len(paths), "file", "files"))
+		if failed > 0 {
+			return fmt.Errorf("%d of %d files could not be imported", failed, len(paths))
+		}
+		return nil
+	},
+}
+
+// importFile parses one Markdown entry and adds it to the journal
+func importFile(db *store.Store, path string) (*store.Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	e, err := store.ParseMarkdown(data)
+	if err != nil {
+		return nil, err
+	}
+	return db.Import(e)
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cldixon/jernel/internal/daemon"
+	"github.com/cldixon/jernel/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var maintainCmd = &cobra.Command{
+	Use:   "maintain",
+	Short: "Compact the database and refresh its statistics",
+	Long: `Run VACUUM and ANALYZE on the journal database. The file doesn't shrink on
+its own after 'jernel reset' or 'jernel trash empty'; this reclaims the space
+and refreshes the statistics SQLite uses to plan queries.
+
+Run it while the daemon is stopped or idle. If another process writes during
+maintenance, it waits a few seconds and then fails, leaving the database
+unchanged; just run it again.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if running, pid, _ := daemon.IsRunning(); running {
+			fmt.Printf("Note: the daemon is running (PID %d). If it writes an entry during maintenance, maintenance fails; run it again afterwards.\n\n", pid)
+		}
+
+		db, err := store.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		before, err := store.DiskUsage()
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Compacting database...")
+		if err := db.Maintain(); err != nil {
+			return err
+		}
+
+		after, err := store.DiskUsage()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Database size: %s -> %s", formatBytes(before), formatBytes(after))
+		if saved := before - after; saved > 0 {
+			fmt.Printf(" (%s reclaimed)", formatBytes(saved))
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. "1.5 MB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(maintainCmd)
+}
@@ -3,31 +3,63 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/cldixon/jernel/internal/config"
 	"github.com/cldixon/jernel/internal/store"
 	"github.com/cldixon/jernel/internal/tui"
 	"github.com/spf13/cobra"
 )
 
+// Flags for open
+var openLimitFlag int
+var openTabFlag string
+
 var openCmd = &cobra.Command{
-	Use:   "open",
-	Short: "Open the interactive journal viewer",
-	Long:  `Opens your journal in an interactive terminal UI to browse and read entries.`,
+	Use:     "open",
+	Aliases: []string{"tui"},
+	Short:   "Open the interactive journal viewer",
+	Long: `Opens your journal in an interactive terminal UI to browse and read entries.
+
+The entries tab loads the newest tui.entry_limit entries (100 by default);
+use --limit to load more or fewer for one session, or 0 for all of them.
+
+Use --tab to start somewhere other than the entries tab, e.g. --tab daemon to
+check on the daemon.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		db, err := store.Open()
-		if err != nil {
-			return fmt.Errorf("failed to open database: %w", err)
+		limit := -1
+		if cmd.Flags().Changed("limit") {
+			limit = openLimitFlag
 		}
-		defer db.Close()
+		return runTUI(limit, openTabFlag)
+	},
+}
 
-		entries, err := db.List(100)
+// runTUI loads the newest entries and starts the TUI on startTab; a negative
+// limit uses the tui.entry_limit setting
+func runTUI(limit int, startTab string) error {
+	if limit < 0 {
+		cfg, err := config.Load()
 		if err != nil {
-			return fmt.Errorf("failed to load entries: %w", err)
+			return fmt.Errorf("failed to load config: %w", err)
 		}
+		limit = cfg.TUI.EntryLimit
+	}
 
-		return tui.Run(entries, Version)
-	},
+	db, err := store.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	entries, err := db.ListFiltered(store.Filter{Limit: limit})
+	if err != nil {
+		return fmt.Errorf("failed to load entries: %w", err)
+	}
+
+	return tui.Run(entries, Version, limit, startTab)
 }
 
 func init() {
 	rootCmd.AddCommand(openCmd)
+	openCmd.Flags().IntVarP(&openLimitFlag, "limit", "n", 0, "Number of entries to load (0 for all; defaults to tui.entry_limit)")
+	openCmd.Flags().StringVar(&openTabFlag, "tab", "", "Tab to start on: entries, personas, daemon, or settings")
 }
@@ -1,31 +1,58 @@
 package cmd
 
 import (
-	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
+	"github.com/cldixon/jernel/internal/config"
+	"github.com/cldixon/jernel/internal/entry"
 	"github.com/cldixon/jernel/internal/persona"
 	"github.com/cldixon/jernel/internal/store"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var personaCmd = &cobra.Command{
 	Use:   "persona",
 	Short: "Manage personas",
-	Long:  `Create, list, and delete personas. Personas define the voice and style for journal entries.`,
+	Long:  `Create, list, rename, and delete personas. Personas define the voice and style for journal entries.`,
+}
+
+// Flags for persona list
+var personaListOutputFlag string
+
+// personaSummaryLen is how much of each description persona list shows
+const personaSummaryLen = 50
+
+// personaSummary is one persona in persona list's JSON and YAML output
+type personaSummary struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"` // start of the description, flattened to one line
 }
 
 var personaListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available personas",
+	Long: `List personas with the start of each description.
+
+Use --output json or --output yaml for a machine-readable list of names and
+truncated descriptions; the default table output is meant for reading.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validPersonaListOutput(personaListOutputFlag); err != nil {
+			return err
+		}
+
 		names, err := persona.List()
 		if err != nil {
 			return fmt.Errorf("failed to list personas: %w", err)
 		}
 
+		if personaListOutputFlag != "table" {
+			return printPersonaSummaries(names, personaListOutputFlag)
+		}
+
 		if len(names) == 0 {
 			fmt.Println("No personas found.")
 			fmt.Println("Create one with 'jernel persona create <name>'")
@@ -51,6 +78,158 @@ var personaListCmd = &cobra.Command{
 	},
 }
 
+// validPersonaListOutput checks a persona list --output value
+func validPersonaListOutput(output string) error {
+	switch output {
+	case "table", "json", "yaml":
+		return nil
+	}
+	return fmt.Errorf("invalid --output %q (must be table, json, or yaml)", output)
+}
+
+// printPersonaSummaries writes persona names and truncated descriptions as JSON or YAML
+// Personas that fail to load are listed with an empty description
+func printPersonaSummaries(names []string, output string) error {
+	summaries := make([]personaSummary, 0, len(names))
+	for _, name := range names {
+		summary := personaSummary{Name: name}
+		if p, err := persona.Get(name); err == nil {
+			summary.Description = truncateLine(p.Description, personaSummaryLen)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	var data []byte
+	var err error
+	if output == "json" {
+		data, err = json.MarshalIndent(summaries, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(summaries)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal personas: %w", err)
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+// Flags for persona show
+var personaShowJSONFlag bool
+
+var personaShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a persona's full description",
+	Long:  `Print a persona's name and full description, or emit it as JSON with --json.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := persona.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		if personaShowJSONFlag {
+			data, err := json.MarshalIndent(struct {
+				Name        string `json:"name"`
+				Description string `json:"description"`
+			}{p.Name, p.Description}, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal persona: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("Persona: %s\n", p.Name)
+		fmt.Println()
+		fmt.Println(p.Description)
+		return nil
+	},
+}
+
+// Flags for persona examples
+var personaExamplesPreviewFlag bool
+
+var personaExamplesCmd = &cobra.Command{
+	Use:   "examples",
+	Short: "List the bundled example personas",
+	Long: `List the example personas that ship with jernel, which the TUI offers when
+you create your first persona.
+
+Use --preview to print each example's full description with a sample entry in
+its voice. Samples are bundled with jernel, so no API call is made.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := persona.ListExamples()
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Println("No example personas are bundled with this build.")
+			return nil
+		}
+
+		if !personaExamplesPreviewFlag {
+			fmt.Println("Example personas:")
+		}
+		for i, name := range names {
+			p, err := persona.GetExample(name)
+			if err != nil {
+				return err
+			}
+
+			if !personaExamplesPreviewFlag {
+				fmt.Printf("  %s - %s\n", name, truncateLine(p.Description, personaSummaryLen))
+				continue
+			}
+
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Println(styled(accentStyle, name))
+			fmt.Println()
+			fmt.Println(p.Description)
+			if sample := persona.ExampleSample(name); sample != "" {
+				fmt.Println()
+				fmt.Println(styled(dimStyle, "Sample entry:"))
+				fmt.Println(styled(dimStyle, "---"))
+				fmt.Println(sample)
+				fmt.Println(styled(dimStyle, "---"))
+			}
+		}
+		return nil
+	},
+}
+
+var personaTestCmd = &cobra.Command{
+	Use:   "test <name>",
+	Short: "Preview an entry from a persona without saving it",
+	Long: `Gather metrics and generate one entry with the persona, then print it.
+Nothing is written to the journal, so you can iterate on a persona's description
+and run this again.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		warnPromptProblems()
+		fmt.Printf("Generating a test entry with persona: %s\n\n", args[0])
+
+		result, err := entry.GenerateWithOptions(context.Background(), cfg, args[0], entry.Options{NoSave: true})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("---")
+		fmt.Println(result.Entry.Content)
+		fmt.Println("---")
+		fmt.Println("\nNot saved (preview only)")
+		return nil
+	},
+}
+
 var personaCreateCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new persona",
@@ -74,17 +253,22 @@ default editor ($EDITOR) for you to customize.`,
 	},
 }
 
+// Flags for persona delete
+var personaDeleteYesFlag bool
+
 var personaDeleteCmd = &cobra.Command{
 	Use:   "delete <name>",
 	Short: "Delete a persona",
-	Long:  `Delete a persona and optionally all associated journal entries.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Delete a persona and move its journal entries to the trash.
+
+Asks for confirmation first; pass --yes to skip it, e.g. in scripts.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
 
 		// Check persona exists
 		if _, err := persona.Get(name); err != nil {
-			return fmt.Errorf("persona '%s' not found", name)
+			return err
 		}
 
 		// Count associated entries
@@ -107,17 +291,8 @@ var personaDeleteCmd = &cobra.Command{
 		} else {
 			fmt.Printf("This will delete persona '%s'.\n", name)
 		}
-		fmt.Print("Type 'yes' to confirm: ")
-
-		reader := bufio.NewReader(os.Stdin)
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			return fmt.Errorf("failed to read input: %w", err)
-		}
-
-		if strings.TrimSpace(strings.ToLower(input)) != "yes" {
-			fmt.Println("Aborted.")
-			return nil
+		if ok, err := confirm(personaDeleteYesFlag); err != nil || !ok {
+			return err
 		}
 
 		// Delete entries first
@@ -131,7 +306,7 @@ var personaDeleteCmd = &cobra.Command{
 			if err != nil {
 				return fmt.Errorf("failed to delete entries: %w", err)
 			}
-			fmt.Printf("Deleted %d %s.\n", deleted, pluralize(int(deleted), "entry", "entries"))
+			fmt.Printf("Moved %d %s to the trash.\n", deleted, pluralize(int(deleted), "entry", "entries"))
 		}
 
 		// Delete persona file
@@ -144,9 +319,38 @@ var personaDeleteCmd = &cobra.Command{
 	},
 }
 
+var personaRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a persona",
+	Long:  `Rename a persona file and move all of its existing entries to the new name.`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldName, newName := args[0], args[1]
+
+		moved, err := entry.RenamePersona(oldName, newName)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Renamed persona '%s' to '%s'.\n", oldName, newName)
+		if moved > 0 {
+			fmt.Printf("Moved %d %s.\n", moved, pluralize(int(moved), "entry", "entries"))
+		}
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(personaCmd)
 	personaCmd.AddCommand(personaListCmd)
+	personaListCmd.Flags().StringVarP(&personaListOutputFlag, "output", "o", "table", "Output format: table, json, or yaml")
+	personaCmd.AddCommand(personaShowCmd)
+	personaCmd.AddCommand(personaRenameCmd)
+	personaShowCmd.Flags().BoolVar(&personaShowJSONFlag, "json", false, "Output as JSON")
+	personaCmd.AddCommand(personaTestCmd)
+	personaCmd.AddCommand(personaExamplesCmd)
+	personaExamplesCmd.Flags().BoolVar(&personaExamplesPreviewFlag, "preview", false, "Show each example's full description and a sample entry")
 	personaCmd.AddCommand(personaCreateCmd)
 	personaCmd.AddCommand(personaDeleteCmd)
+	personaDeleteCmd.Flags().BoolVarP(&personaDeleteYesFlag, "yes", "y", false, "Skip the confirmation prompt")
 }
@@ -8,12 +8,18 @@ import (
 
 	"github.com/cldixon/jernel/internal/store"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
+// Flags for reset
+var resetYesFlag bool
+
 var resetCmd = &cobra.Command{
 	Use:   "reset",
 	Short: "Delete all journal entries",
-	Long:  `Permanently deletes all journal entries from the database. This action cannot be undone.`,
+	Long: `Moves all journal entries to the trash. Restore them with 'jernel trash restore' or purge them with 'jernel trash empty'.
+
+Asks for confirmation first; pass --yes to skip it, e.g. in scripts.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get entry count first
 		db, err := store.Open()
@@ -35,18 +41,9 @@ var resetCmd = &cobra.Command{
 		}
 
 		// Confirm with user
-		fmt.Printf("This will permanently delete %d journal %s.\n", count, pluralize(count, "entry", "entries"))
-		fmt.Print("Type 'yes' to confirm: ")
-
-		reader := bufio.NewReader(os.Stdin)
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			return fmt.Errorf("failed to read input: %w", err)
-		}
-
-		if strings.TrimSpace(strings.ToLower(input)) != "yes" {
-			fmt.Println("Aborted.")
-			return nil
+		fmt.Printf("This will move %d journal %s to the trash.\n", count, pluralize(count, "entry", "entries"))
+		if ok, err := confirm(resetYesFlag); err != nil || !ok {
+			return err
 		}
 
 		// Delete all entries
@@ -61,11 +58,37 @@ var resetCmd = &cobra.Command{
 			return fmt.Errorf("failed to delete entries: %w", err)
 		}
 
-		fmt.Printf("Deleted %d %s.\n", deleted, pluralize(int(deleted), "entry", "entries"))
+		fmt.Printf("Moved %d %s to the trash. Use 'jernel trash' to restore.\n", deleted, pluralize(int(deleted), "entry", "entries"))
 		return nil
 	},
 }
 
+// confirm asks the user to type "yes" and reports whether they did, printing
+// "Aborted." otherwise. skip (from a --yes flag) confirms without asking.
+// When stdin isn't a terminal it refuses instead of waiting on input that
+// may never come
+func confirm(skip bool) (bool, error) {
+	if skip {
+		return true, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, fmt.Errorf("stdin is not a terminal; pass --yes to confirm without a prompt")
+	}
+
+	fmt.Print("Type 'yes' to confirm: ")
+	reader := bufio.NewReader(os.Stdin)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	if strings.TrimSpace(strings.ToLower(input)) != "yes" {
+		fmt.Println("Aborted.")
+		return false, nil
+	}
+	return true, nil
+}
+
 func pluralize(count int, singular, plural string) string {
 	if count == 1 {
 		return singular
@@ -75,4 +98,5 @@ func pluralize(count int, singular, plural string) string {
 
 func init() {
 	rootCmd.AddCommand(resetCmd)
+	resetCmd.Flags().BoolVarP(&resetYesFlag, "yes", "y", false, "Skip the confirmation prompt")
 }
@@ -3,13 +3,27 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/cldixon/jernel/internal/config"
+	"github.com/cldixon/jernel/internal/llm"
+	"github.com/cldixon/jernel/internal/prompt"
+	"github.com/cldixon/jernel/internal/timefmt"
 	"github.com/spf13/cobra"
 )
 
-// Version is set at build time via -ldflags
-var Version = "dev"
+// Build metadata, set at build time via -ldflags, e.g.
+// -X github.com/cldixon/jernel/cmd.Version=v1.2.0
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// Flags for the root command
+var configDir string
+var profileFlag string
 
 var rootCmd = &cobra.Command{
 	Use:     "jernel",
@@ -17,10 +31,64 @@ var rootCmd = &cobra.Command{
 	Long:    `jernel gives your computer a voice by translating system metrics into personal journal entries.`,
 	Version: Version,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		return config.Init()
+		if err := applyConfigFlags(); err != nil {
+			return err
+		}
+
+		if err := config.Init(); err != nil {
+			return err
+		}
+
+		if cfg, err := config.LoadRaw(); err == nil {
+			// An unknown profile is reported by the command that loads the config
+			config.ApplyProfile(cfg, config.ActiveProfile())
+			if unset := config.ExpandEnv(cfg); len(unset) > 0 {
+				fmt.Fprintf(os.Stderr, "Warning: config.yaml references unset environment %s %s; using empty values\n\n",
+					pluralize(len(unset), "variable", "variables"), strings.Join(unset, ", "))
+			}
+			if err := llm.CheckAPIKeyFile(cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n\n", err)
+			}
+			loc, err := timefmt.LoadLocation(cfg.DisplayTimezone)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v, using local time\n\n", err)
+			} else {
+				timefmt.SetLocation(loc)
+			}
+		}
+		return nil
 	},
 }
 
+// applyConfigFlags exports --config-dir and --profile to the environment so the
+// config package, and a daemon started from here (or the TUI), see them
+func applyConfigFlags() error {
+	if configDir != "" {
+		dir, err := filepath.Abs(configDir)
+		if err != nil {
+			return fmt.Errorf("invalid config directory: %w", err)
+		}
+		os.Setenv(config.DirEnv, dir)
+	}
+	if profileFlag != "" {
+		os.Setenv(config.ProfileEnv, profileFlag)
+	}
+	return nil
+}
+
+// warnPromptProblems surfaces message prompt mistakes before a command spends
+// an LLM call on them, without blocking it; 'jernel doctor' reports the same
+func warnPromptProblems() {
+	if err := prompt.ValidateMessagePrompt(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n\n", err)
+	}
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configDir, "config-dir", "", "config directory (default ~/.config/jernel, or $"+config.DirEnv+")")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "config profile to apply from config.yaml (or $"+config.ProfileEnv+")")
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cldixon/jernel/internal/config"
+	"github.com/cldixon/jernel/internal/entry"
+	"github.com/cldixon/jernel/internal/timefmt"
+	"github.com/spf13/cobra"
+)
+
+// Flags for seed
+var seedCountFlag int
+var seedSpreadFlag string
+var seedPersonaFlag string
+
+// seedMaxCount caps one seed run, since every entry is a separate LLM call
+const seedMaxCount = 50
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Backfill the journal with backdated entries",
+	Long: `Generate a few entries dated across the recent past, so a new journal has
+some history to browse.
+
+Seeded entries are written from the machine's current metrics; only their dates
+are spread over the --spread window (e.g. 7d, 2w, or 36h). Each one is tagged
+"` + entry.SeedTag + `" so it isn't mistaken for real history: list them with
+'jernel entry list --tag ` + entry.SeedTag + `'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if seedCountFlag < 1 || seedCountFlag > seedMaxCount {
+			return fmt.Errorf("invalid count: %d (must be between 1 and %d)", seedCountFlag, seedMaxCount)
+		}
+		spread, err := timefmt.ParseDuration(seedSpreadFlag)
+		if err != nil {
+			return fmt.Errorf("invalid spread: %w", err)
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		personaName := seedPersonaFlag
+		if personaName == "" {
+			personaName = cfg.DefaultPersona
+		}
+
+		times, err := entry.SeedTimes(seedCountFlag, spread, time.Now())
+		if err != nil {
+			return err
+		}
+		warnPromptProblems()
+
+		fmt.Printf("Seeding %d backdated %s with persona: %s\n", seedCountFlag, pluralize(seedCountFlag, "entry", "entries"), personaName)
+		fmt.Printf("Metrics are sampled now; only the dates are spread over the last %s.\n\n", timefmt.Human(spread))
+
+		ctx := context.Background()
+		seeded, failed := 0, 0
+		for i, createdAt := range times {
+			if i > 0 {
+				time.Sleep(entryCreateDelay)
+			}
+
+			fmt.Printf("[%d/%d] %s... ", i+1, len(times), timefmt.Format(createdAt, timefmt.Short))
+			result, err := entry.GenerateWithOptions(ctx, cfg, personaName, entry.Options{
+				MetricsMaxAge: entry.BatchMetricsMaxAge,
+				CreatedAt:     createdAt,
+				Tags:          []string{entry.SeedTag},
+			})
+			if err != nil {
+				failed++
+				fmt.Printf("failed: %v\n", err)
+				continue
+			}
+			seeded++
+			fmt.Printf("saved as entry #%d\n", result.Entry.ID)
+		}
+
+		fmt.Printf("\nSeeded %d of %d %s, tagged %q\n", seeded, len(times), pluralize(len(times), "entry", "entries"), entry.SeedTag)
+		if failed > 0 {
+			return fmt.Errorf("%d of %d generations failed", failed, len(times))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+	seedCmd.Flags().IntVar(&seedCountFlag, "count", 5, "Number of entries to generate")
+	seedCmd.Flags().StringVar(&seedSpreadFlag, "spread", "7d", "How far back to spread entry dates (e.g. 7d, 2w, 36h)")
+	seedCmd.Flags().StringVarP(&seedPersonaFlag, "persona", "p", "", "Persona to use (defaults to config setting)")
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cldixon/jernel/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show how many entries each persona and model has written",
+	Long: `Print entry totals broken down by persona, by the model that wrote them,
+and by mood. Entries in the trash are not counted. List one model's entries
+with 'jernel entry list --model <model>', or one mood's with --mood.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := store.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		byPersona, err := db.CountPerPersona()
+		if err != nil {
+			return err
+		}
+		if len(byPersona) == 0 {
+			fmt.Println("No entries yet.")
+			return nil
+		}
+		byModel, err := db.CountPerModel()
+		if err != nil {
+			return err
+		}
+		byMood, err := db.CountPerMood()
+		if err != nil {
+			return err
+		}
+		unread, err := db.CountUnread()
+		if err != nil {
+			return err
+		}
+
+		total := 0
+		for _, c := range byPersona {
+			total += c.Count
+		}
+		fmt.Printf("%d %s, %d unread\n", total, pluralize(total, "entry", "entries"), unread)
+
+		fmt.Println()
+		fmt.Println("By persona:")
+		printGroupCounts(byPersona, total)
+
+		fmt.Println()
+		fmt.Println("By model:")
+		printGroupCounts(byModel, total)
+
+		// Journals from before moods were classified have no moods to show
+		if len(byMood) > 1 || byMood[0].Value != "" {
+			fmt.Println()
+			fmt.Println("By mood:")
+			printGroupCounts(byMood, total)
+		}
+		return nil
+	},
+}
+
+// printGroupCounts prints an aligned count and share of the total for each
+// group; empty values (entries saved without a model) show as "(unknown)"
+func printGroupCounts(counts []store.GroupCount, total int) {
+	width := 0
+	for _, c := range counts {
+		width = max(width, len(groupLabel(c.Value)))
+	}
+	for _, c := range counts {
+		fmt.Printf("  %-*s  %5d  %3.0f%%\n", width, groupLabel(c.Value), c.Count, float64(c.Count)*100/float64(total))
+	}
+}
+
+func groupLabel(value string) string {
+	if value == "" {
+		return "(unknown)"
+	}
+	return value
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cldixon/jernel/internal/store"
+	"github.com/cldixon/jernel/internal/timefmt"
+	"github.com/spf13/cobra"
+)
+
+// Flags for trash list
+var trashLimitFlag int
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List and restore deleted entries",
+	Long: `Deleted entries are moved to the trash instead of being removed.
+
+Run without a subcommand to list recently deleted entries, restore one with
+'jernel trash restore <id>', or purge them for good with 'jernel trash empty'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := store.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		entries, err := db.ListTrash(trashLimitFlag)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("Trash is empty.")
+			return nil
+		}
+
+		for _, e := range entries {
+			fmt.Printf("#%d [%s] %s (deleted %s)\n", e.ID, e.Persona,
+				timefmt.Format(e.CreatedAt, timefmt.Short),
+				timefmt.Format(*e.DeletedAt, timefmt.Short))
+		}
+		return nil
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Restore a deleted entry",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid entry ID: %s", args[0])
+		}
+
+		db, err := store.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		if err := db.Restore(id); err != nil {
+			return err
+		}
+
+		fmt.Printf("Restored entry #%d.\n", id)
+		return nil
+	},
+}
+
+// Flags for trash empty
+var trashEmptyYesFlag bool
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete all trashed entries",
+	Long: `Permanently removes every entry in the trash, after confirmation (--yes
+skips it). This action cannot be undone.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := store.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		count, err := db.CountTrash()
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			fmt.Println("Trash is empty.")
+			return nil
+		}
+
+		// Confirm with user
+		fmt.Printf("This will permanently delete %d trashed %s.\n", count, pluralize(count, "entry", "entries"))
+		if ok, err := confirm(trashEmptyYesFlag); err != nil || !ok {
+			return err
+		}
+
+		purged, err := db.EmptyTrash()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Permanently deleted %d %s.\n", purged, pluralize(int(purged), "entry", "entries"))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+	trashCmd.Flags().IntVarP(&trashLimitFlag, "limit", "n", 20, "Number of trashed entries to list")
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+	trashEmptyCmd.Flags().BoolVarP(&trashEmptyYesFlag, "yes", "y", false, "Skip the confirmation prompt")
+}
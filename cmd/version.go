@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version and build information",
+	Long:  `Print the jernel version, the git commit and date it was built from, and the Go toolchain and platform. Include this output in bug reports.`,
+	// Skip config setup so this works even when the config directory is broken
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("jernel %s\n", Version)
+		fmt.Printf("  commit:   %s\n", Commit)
+		fmt.Printf("  built:    %s\n", BuildDate)
+		fmt.Printf("  go:       %s\n", runtime.Version())
+		fmt.Printf("  platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
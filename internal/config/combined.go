@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CombinedPromptFile holds both the system and message prompts in one file
+// When it exists it takes the place of system_prompt.md and message_prompt.md
+const CombinedPromptFile = "prompt.md"
+
+// Markers that start each section of the combined prompt file, on a line of their own
+const (
+	SystemSectionMarker  = "<!-- system -->"
+	MessageSectionMarker = "<!-- message -->"
+)
+
+// CombinedPromptPath returns the path to the combined prompt file
+func CombinedPromptPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, CombinedPromptFile), nil
+}
+
+// SplitCombinedPrompt splits combined prompt text into its system and message
+// sections. Each marker must appear once, in either order, and start a
+// non-empty section; text before the first marker is ignored, so the file can
+// open with notes
+func SplitCombinedPrompt(text string) (system string, message string, err error) {
+	sections := make(map[string]*strings.Builder)
+	var current *strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		marker := strings.TrimSpace(line)
+		if marker == SystemSectionMarker || marker == MessageSectionMarker {
+			if sections[marker] != nil {
+				return "", "", fmt.Errorf("duplicate %s section", marker)
+			}
+			current = &strings.Builder{}
+			sections[marker] = current
+			continue
+		}
+		if current != nil {
+			current.WriteString(line)
+		}
+	}
+
+	for _, marker := range []string{SystemSectionMarker, MessageSectionMarker} {
+		if sections[marker] == nil {
+			return "", "", fmt.Errorf("missing %s section", marker)
+		}
+		if strings.TrimSpace(sections[marker].String()) == "" {
+			return "", "", fmt.Errorf("empty %s section", marker)
+		}
+	}
+
+	system = strings.TrimSpace(sections[SystemSectionMarker].String()) + "\n"
+	message = strings.TrimSpace(sections[MessageSectionMarker].String()) + "\n"
+	return system, message, nil
+}
+
+// loadCombinedPrompt reads and splits the combined prompt file
+// ok is false when there is no combined file, so the separate files apply
+func loadCombinedPrompt() (system string, message string, ok bool, err error) {
+	path, err := CombinedPromptPath()
+	if err != nil {
+		return "", "", false, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", false, nil
+		}
+		return "", "", false, fmt.Errorf("failed to read combined prompt: %w", err)
+	}
+
+	system, message, err = SplitCombinedPrompt(string(data))
+	if err != nil {
+		return "", "", false, fmt.Errorf("invalid combined prompt (%s): %w", path, err)
+	}
+	return system, message, true, nil
+}
+
+// MessagePromptSource returns the file the message prompt is read from: the
+// combined prompt file when it exists, otherwise message_prompt.md
+func MessagePromptSource() (string, error) {
+	path, err := CombinedPromptPath()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	return MessagePromptPath()
+}
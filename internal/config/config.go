@@ -15,20 +15,77 @@ var DefaultSystemPrompt string
 //go:embed defaults/message_prompt.md
 var DefaultMessagePrompt string
 
+//go:embed defaults/digest_prompt.md
+var DefaultDigestPrompt string
+
 // DaemonConfig holds settings for autonomous entry generation
 type DaemonConfig struct {
-	Rate       int      `yaml:"rate"`        // number of entries per period
-	RatePeriod string   `yaml:"rate_period"` // "hour", "day", or "week"
-	Personas   []string `yaml:"personas"`    // personas to randomly select from
+	Rate        int      `yaml:"rate"`                   // number of entries per period
+	RatePeriod  string   `yaml:"rate_period"`            // "minute", "hour", "day", "week", or "month" (30 days)
+	Personas    []string `yaml:"personas"`               // personas to select from
+	Mode        string   `yaml:"mode"`                   // "single" (one random persona) or "all" (every persona) per trigger
+	Selection   string   `yaml:"selection"`              // how single mode picks: "random" or "round_robin" (each persona in turn)
+	Jitter      float64  `yaml:"jitter"`                 // randomness of intervals: 0 = exact, 1 = 0.5x-1.5x the average
+	MetricsAddr string   `yaml:"metrics_addr,omitempty"` // listen address for the Prometheus endpoint, e.g. ":9099" (off when empty)
+	LogFormat   string   `yaml:"log_format,omitempty"`   // "text" (default) or "json" for structured log lines
+	CatchUp     bool     `yaml:"catch_up"`               // on start, write one entry right away if the previous run's trigger was missed
+	MinInterval string   `yaml:"min_interval,omitempty"` // shortest wait between triggers, e.g. "30m" (empty = no floor)
+	WebhookURL  string   `yaml:"webhook_url,omitempty"`  // POST a JSON notice here after each generated entry (off when empty)
+}
+
+// MetricsConfig holds settings for system metric collection
+type MetricsConfig struct {
+	FanCommand          string `yaml:"fan_command"`                     // external fan reader on macOS, e.g. "istats fan speed"
+	MachineTypeOverride string `yaml:"machine_type_override,omitempty"` // skip detection and report this machine type, e.g. "server"
+	CPUSampleMS         int    `yaml:"cpu_sample_ms"`                   // CPU usage sampling window in milliseconds (0 = since the previous reading, no wait)
+}
+
+// TUIConfig holds settings for the interactive journal viewer
+type TUIConfig struct {
+	Timestamps string `yaml:"timestamps"`  // "relative" or "absolute" in the entry list
+	EntryLimit int    `yaml:"entry_limit"` // most entries loaded into the entries tab (0 = no limit)
+}
+
+// PostProcessConfig holds cleanup rules applied to generated entry content
+type PostProcessConfig struct {
+	Trim          bool `yaml:"trim"`           // strip leading and trailing whitespace
+	StripFences   bool `yaml:"strip_fences"`   // remove a markdown code fence wrapping the whole entry
+	MaxParagraphs int  `yaml:"max_paragraphs"` // keep at most this many paragraphs (0 = no limit)
+}
+
+// ValidateConfig holds checks that reject refusals, near-empty entries, and
+// repeats before they are saved
+type ValidateConfig struct {
+	MinChars        int      `yaml:"min_chars"`        // reject entries shorter than this many characters (0 = no minimum)
+	RefusalPhrases  []string `yaml:"refusal_phrases"`  // reject entries that start with any of these (case-insensitive)
+	Dedup           string   `yaml:"dedup"`            // off, retry (write again once), or skip entries too similar to the persona's last one
+	DedupSimilarity float64  `yaml:"dedup_similarity"` // word overlap (0-1) at which an entry counts as a repeat; 1 = identical text only
 }
 
 // Config holds application-level settings
 type Config struct {
-	Provider       string        `yaml:"provider"`
-	Model          string        `yaml:"model"`
-	DefaultPersona string        `yaml:"default_persona"`
-	ContextEntries int           `yaml:"context_entries"` // number of previous entries to include for continuity
-	Daemon         *DaemonConfig `yaml:"daemon,omitempty"`
+	Provider           string             `yaml:"provider"`
+	Model              string             `yaml:"model"`
+	BaseURL            string             `yaml:"base_url,omitempty"`         // API endpoint override for proxies or compatible gateways
+	APIKeyFile         string             `yaml:"api_key_file,omitempty"`     // file holding the API key, used when ANTHROPIC_API_KEY is unset
+	APIKeyKeychain     bool               `yaml:"api_key_keychain,omitempty"` // on macOS, fall back to the "jernel" keychain item
+	DefaultPersona     string             `yaml:"default_persona"`
+	ContextEntries     int                `yaml:"context_entries"`                // number of previous entries to include for continuity
+	ContextMaxChars    int                `yaml:"context_max_chars,omitempty"`    // truncate each previous entry to this many characters (0 = no limit)
+	ContextBudgetChars int                `yaml:"context_budget_chars,omitempty"` // drop the oldest previous entries beyond this combined size (0 = no limit)
+	CompressMetrics    bool               `yaml:"compress_metrics,omitempty"`     // gzip metrics snapshots in the database
+	KeepRevisions      bool               `yaml:"keep_revisions,omitempty"`       // save an entry's previous content when it is regenerated or restored
+	ClassifyMood       bool               `yaml:"classify_mood,omitempty"`        // ask the LLM for each entry's mood instead of guessing it from keywords
+	PersonaPlacement   string             `yaml:"persona_placement,omitempty"`    // "user" (in the message prompt) or "system" (appended to the system prompt)
+	DisplayTimezone    string             `yaml:"display_timezone,omitempty"`     // IANA timezone for displayed times, e.g. "Europe/Berlin" (empty = local)
+	EntryLength        string             `yaml:"entry_length,omitempty"`         // "short", "medium", or "long"; personas can override with length: in frontmatter
+	Language           string             `yaml:"language,omitempty"`             // language entries and digests are written in, e.g. "Spanish"
+	Daemon             *DaemonConfig      `yaml:"daemon,omitempty"`
+	Metrics            *MetricsConfig     `yaml:"metrics,omitempty"`
+	TUI                *TUIConfig         `yaml:"tui,omitempty"`
+	PostProcess        *PostProcessConfig `yaml:"post_process,omitempty"`
+	Validate           *ValidateConfig    `yaml:"validate,omitempty"`
+	Profiles           Profiles           `yaml:"profiles,omitempty"` // named overlays on these settings, see ApplyProfile
 }
 
 // DefaultDaemonConfig returns sensible defaults for daemon settings
@@ -37,22 +94,89 @@ func DefaultDaemonConfig() *DaemonConfig {
 		Rate:       3,
 		RatePeriod: "day",
 		Personas:   []string{},
+		Mode:       "single",
+		Selection:  "random",
+		Jitter:     1.0,
+		LogFormat:  "text",
+		CatchUp:    true,
+	}
+}
+
+// DefaultMetricsConfig returns sensible defaults for metric collection
+func DefaultMetricsConfig() *MetricsConfig {
+	return &MetricsConfig{
+		CPUSampleMS: 1000,
+	}
+}
+
+// DefaultTUIConfig returns sensible defaults for the TUI
+func DefaultTUIConfig() *TUIConfig {
+	return &TUIConfig{
+		Timestamps: "relative",
+		EntryLimit: 100,
+	}
+}
+
+// DefaultPostProcessConfig returns the minimal cleanup applied to every entry
+func DefaultPostProcessConfig() *PostProcessConfig {
+	return &PostProcessConfig{
+		Trim: true,
+	}
+}
+
+// DefaultValidateConfig returns checks that catch common refusals and empty replies
+func DefaultValidateConfig() *ValidateConfig {
+	return &ValidateConfig{
+		MinChars: 80,
+		RefusalPhrases: []string{
+			"I can't help",
+			"I cannot help",
+			"I can't write",
+			"I cannot write",
+			"I'm sorry, but",
+			"I apologize, but",
+			"I'm not able to",
+			"I am unable to",
+			"As an AI",
+		},
+		Dedup:           "off",
+		DedupSimilarity: 0.9,
 	}
 }
 
 // DefaultConfig returns sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
-		Provider:       "anthropic",
-		Model:          "claude-sonnet-4-5-20250929",
-		DefaultPersona: "default",
-		ContextEntries: 3,
-		Daemon:         DefaultDaemonConfig(),
+		Provider:         "anthropic",
+		Model:            "claude-sonnet-4-5-20250929",
+		DefaultPersona:   "default",
+		ContextEntries:   3,
+		PersonaPlacement: "user",
+		EntryLength:      "medium",
+		Language:         "English",
+		Daemon:           DefaultDaemonConfig(),
+		Metrics:          DefaultMetricsConfig(),
+		TUI:              DefaultTUIConfig(),
+		PostProcess:      DefaultPostProcessConfig(),
+		Validate:         DefaultValidateConfig(),
 	}
 }
 
+// DirEnv is the environment variable that overrides the config directory
+// The --config-dir flag sets it too, so child processes use the same directory
+const DirEnv = "JERNEL_CONFIG_DIR"
+
 // Dir returns the jernel config directory path
+// Every other path (personas, database, daemon files) is derived from it
 func Dir() (string, error) {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return filepath.Abs(dir)
+	}
+	return DefaultDir()
+}
+
+// DefaultDir returns the config directory used when DirEnv is unset
+func DefaultDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -70,7 +194,24 @@ func Path() (string, error) {
 }
 
 // Load reads the config file, returning defaults if it doesn't exist
+// The active profile (see ActiveProfile) is applied, and environment variable
+// references in string settings are expanded (see ExpandEnv)
 func Load() (*Config, error) {
+	cfg, err := LoadRaw()
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplyProfile(cfg, ActiveProfile()); err != nil {
+		return nil, err
+	}
+	ExpandEnv(cfg)
+	return cfg, nil
+}
+
+// LoadRaw is like Load but keeps ${VAR} references as written and skips the
+// active profile, so a config that is modified and saved doesn't bake in the
+// current environment or profile
+func LoadRaw() (*Config, error) {
 	path, err := Path()
 	if err != nil {
 		return nil, err
@@ -89,10 +230,8 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
-	// Ensure daemon config has defaults if not specified
-	if cfg.Daemon == nil {
-		cfg.Daemon = DefaultDaemonConfig()
-	}
+	// Ensure nested sections have defaults if not specified
+	fillDefaults(cfg)
 
 	return cfg, nil
 }
@@ -146,19 +285,13 @@ func Init() error {
 		}
 	}
 
-	// Write system prompt if it doesn't exist
-	systemPromptPath := filepath.Join(dir, "system_prompt.md")
-	if _, err := os.Stat(systemPromptPath); os.IsNotExist(err) {
-		if err := os.WriteFile(systemPromptPath, []byte(DefaultSystemPrompt), 0644); err != nil {
-			return fmt.Errorf("failed to write system prompt: %w", err)
-		}
-	}
-
-	// Write message prompt if it doesn't exist
-	messagePromptPath := filepath.Join(dir, "message_prompt.md")
-	if _, err := os.Stat(messagePromptPath); os.IsNotExist(err) {
-		if err := os.WriteFile(messagePromptPath, []byte(DefaultMessagePrompt), 0644); err != nil {
-			return fmt.Errorf("failed to write message prompt: %w", err)
+	// Write prompt templates that don't exist
+	for _, pf := range promptFiles() {
+		path := filepath.Join(dir, pf.name)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err := os.WriteFile(path, []byte(pf.content), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", pf.name, err)
+			}
 		}
 	}
 
@@ -168,6 +301,81 @@ func Init() error {
 	return nil
 }
 
+// promptFile is a bundled prompt template and its name in the config directory
+type promptFile struct {
+	name    string
+	content string
+}
+
+// promptFiles lists the prompt templates Init writes and ResetPrompts restores
+func promptFiles() []promptFile {
+	return []promptFile{
+		{"system_prompt.md", DefaultSystemPrompt},
+		{"message_prompt.md", DefaultMessagePrompt},
+		{"digest_prompt.md", DefaultDigestPrompt},
+	}
+}
+
+// PromptPaths returns the paths of the prompt templates ResetPrompts overwrites,
+// including the combined prompt file when there is one
+func PromptPaths() ([]string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, pf := range promptFiles() {
+		paths = append(paths, filepath.Join(dir, pf.name))
+	}
+	combined := filepath.Join(dir, CombinedPromptFile)
+	if _, err := os.Stat(combined); err == nil {
+		paths = append(paths, combined)
+	}
+	return paths, nil
+}
+
+// ResetPrompts rewrites every prompt template with its bundled default
+// Existing files are first copied to a .bak alongside them (replacing any
+// older backup); the backup paths are returned. A combined prompt file is
+// moved to its .bak so it no longer overrides the restored files
+func ResetPrompts() ([]string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	var backups []string
+	for _, pf := range promptFiles() {
+		path := filepath.Join(dir, pf.name)
+
+		existing, err := os.ReadFile(path)
+		if err == nil {
+			if err := os.WriteFile(path+".bak", existing, 0644); err != nil {
+				return backups, fmt.Errorf("failed to back up %s: %w", pf.name, err)
+			}
+			backups = append(backups, path+".bak")
+		} else if !os.IsNotExist(err) {
+			return backups, fmt.Errorf("failed to read %s: %w", pf.name, err)
+		}
+
+		if err := os.WriteFile(path, []byte(pf.content), 0644); err != nil {
+			return backups, fmt.Errorf("failed to write %s: %w", pf.name, err)
+		}
+	}
+
+	combined := filepath.Join(dir, CombinedPromptFile)
+	if err := os.Rename(combined, combined+".bak"); err == nil {
+		backups = append(backups, combined+".bak")
+	} else if !os.IsNotExist(err) {
+		return backups, fmt.Errorf("failed to back up %s: %w", CombinedPromptFile, err)
+	}
+	return backups, nil
+}
+
 // SystemPromptPath returns the path to the system prompt file
 func SystemPromptPath() (string, error) {
 	dir, err := Dir()
@@ -177,8 +385,12 @@ func SystemPromptPath() (string, error) {
 	return filepath.Join(dir, "system_prompt.md"), nil
 }
 
-// LoadSystemPrompt reads the system prompt from disk
+// LoadSystemPrompt reads the system prompt from disk, preferring the combined prompt file
 func LoadSystemPrompt() (string, error) {
+	if system, _, ok, err := loadCombinedPrompt(); err != nil || ok {
+		return system, err
+	}
+
 	path, err := SystemPromptPath()
 	if err != nil {
 		return "", err
@@ -204,8 +416,13 @@ func MessagePromptPath() (string, error) {
 	return filepath.Join(dir, "message_prompt.md"), nil
 }
 
-// LoadMessagePrompt reads the message prompt template from disk
+// LoadMessagePrompt reads the message prompt template from disk, preferring the
+// combined prompt file
 func LoadMessagePrompt() (string, error) {
+	if _, message, ok, err := loadCombinedPrompt(); err != nil || ok {
+		return message, err
+	}
+
 	path, err := MessagePromptPath()
 	if err != nil {
 		return "", err
@@ -221,3 +438,30 @@ func LoadMessagePrompt() (string, error) {
 
 	return string(data), nil
 }
+
+// DigestPromptPath returns the path to the digest prompt template file
+func DigestPromptPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "digest_prompt.md"), nil
+}
+
+// LoadDigestPrompt reads the digest prompt template from disk
+func LoadDigestPrompt() (string, error) {
+	path, err := DigestPromptPath()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultDigestPrompt, nil
+		}
+		return "", fmt.Errorf("failed to read digest prompt: %w", err)
+	}
+
+	return string(data), nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -16,10 +17,9 @@ func TestInitCreatesRequiredFiles(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpHome)
 
-	// Override HOME for the test
-	origHome := os.Getenv("HOME")
-	os.Setenv("HOME", tmpHome)
-	defer os.Setenv("HOME", origHome)
+	// Override HOME for the test, ignoring any JERNEL_CONFIG_DIR
+	t.Setenv("HOME", tmpHome)
+	t.Setenv(DirEnv, "")
 
 	// Run Init
 	if err := Init(); err != nil {
@@ -56,6 +56,12 @@ func TestInitCreatesRequiredFiles(t *testing.T) {
 		t.Error("message_prompt.md was not created")
 	}
 
+	// Verify digest_prompt.md was created
+	digestPromptPath := filepath.Join(configDir, "digest_prompt.md")
+	if _, err := os.Stat(digestPromptPath); os.IsNotExist(err) {
+		t.Error("digest_prompt.md was not created")
+	}
+
 	// Verify config.yaml has valid content
 	cfg, err := Load()
 	if err != nil {
@@ -108,9 +114,8 @@ func TestInitIsIdempotent(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpHome)
 
-	origHome := os.Getenv("HOME")
-	os.Setenv("HOME", tmpHome)
-	defer os.Setenv("HOME", origHome)
+	t.Setenv("HOME", tmpHome)
+	t.Setenv(DirEnv, "")
 
 	// First Init
 	if err := Init(); err != nil {
@@ -136,6 +141,144 @@ func TestInitIsIdempotent(t *testing.T) {
 	}
 }
 
+// TestResetPrompts verifies edited prompts are backed up and replaced with
+// the bundled defaults, and missing ones are recreated.
+func TestResetPrompts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(DirEnv, dir)
+
+	if err := Init(); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+
+	systemPath := filepath.Join(dir, "system_prompt.md")
+	if err := os.WriteFile(systemPath, []byte("my edits"), 0644); err != nil {
+		t.Fatalf("failed to edit system prompt: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "message_prompt.md")); err != nil {
+		t.Fatalf("failed to remove message prompt: %v", err)
+	}
+
+	backups, err := ResetPrompts()
+	if err != nil {
+		t.Fatalf("ResetPrompts() failed: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Errorf("expected backups of the 2 existing prompts, got %v", backups)
+	}
+
+	if data, _ := os.ReadFile(systemPath); string(data) != DefaultSystemPrompt {
+		t.Error("system prompt was not restored to the default")
+	}
+	if data, _ := os.ReadFile(systemPath + ".bak"); string(data) != "my edits" {
+		t.Errorf("expected the edited prompt in the backup, got %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "message_prompt.md")); string(data) != DefaultMessagePrompt {
+		t.Error("missing message prompt was not recreated")
+	}
+}
+
+// TestSplitCombinedPrompt verifies prompt.md splits into its two sections in
+// either order, and that missing, repeated, or empty sections are rejected.
+func TestSplitCombinedPrompt(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		system  string
+		message string
+		wantErr bool
+	}{
+		{
+			name:    "system first",
+			text:    "<!-- system -->\nYou are a computer.\n\n<!-- message -->\nWrite about {{.CPUPercent}}.\n",
+			system:  "You are a computer.\n",
+			message: "Write about {{.CPUPercent}}.\n",
+		},
+		{
+			name:    "message first with notes and indented markers",
+			text:    "My prompts, edited in March.\n  <!-- message -->  \nHello.\n<!-- system -->\nBe brief.\nReally.",
+			system:  "Be brief.\nReally.\n",
+			message: "Hello.\n",
+		},
+		{
+			name:    "inline marker mention stays in the text",
+			text:    "<!-- system -->\nNever print <!-- message --> literally.\n<!-- message -->\nHi.\n",
+			system:  "Never print <!-- message --> literally.\n",
+			message: "Hi.\n",
+		},
+		{name: "missing message", text: "<!-- system -->\nYou are a computer.\n", wantErr: true},
+		{name: "missing both", text: "Just one prompt.\n", wantErr: true},
+		{name: "duplicate system", text: "<!-- system -->\nA\n<!-- system -->\nB\n<!-- message -->\nC\n", wantErr: true},
+		{name: "empty section", text: "<!-- system -->\n\n<!-- message -->\nHi.\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			system, message, err := SplitCombinedPrompt(tt.text)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got system %q and message %q", system, message)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SplitCombinedPrompt() failed: %v", err)
+			}
+			if system != tt.system || message != tt.message {
+				t.Errorf("got system %q and message %q, want %q and %q", system, message, tt.system, tt.message)
+			}
+		})
+	}
+}
+
+// TestCombinedPromptOverridesFiles verifies prompt.md supplies both prompts
+// when present, the separate files apply without it, and reset-prompts moves
+// it aside.
+func TestCombinedPromptOverridesFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(DirEnv, dir)
+
+	if err := Init(); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+
+	if got, err := LoadSystemPrompt(); err != nil || got != DefaultSystemPrompt {
+		t.Errorf("expected the default system prompt without prompt.md, got %q (%v)", got, err)
+	}
+
+	combined := filepath.Join(dir, CombinedPromptFile)
+	if err := os.WriteFile(combined, []byte("<!-- system -->\nCombined system.\n<!-- message -->\nCombined message.\n"), 0644); err != nil {
+		t.Fatalf("failed to write prompt.md: %v", err)
+	}
+
+	if got, err := LoadSystemPrompt(); err != nil || got != "Combined system.\n" {
+		t.Errorf("expected the combined system prompt, got %q (%v)", got, err)
+	}
+	if got, err := LoadMessagePrompt(); err != nil || got != "Combined message.\n" {
+		t.Errorf("expected the combined message prompt, got %q (%v)", got, err)
+	}
+	if source, _ := MessagePromptSource(); source != combined {
+		t.Errorf("expected the message prompt source to be prompt.md, got %s", source)
+	}
+
+	if err := os.WriteFile(combined, []byte("<!-- system -->\nNo message section.\n"), 0644); err != nil {
+		t.Fatalf("failed to write prompt.md: %v", err)
+	}
+	if _, err := LoadMessagePrompt(); err == nil || !strings.Contains(err.Error(), CombinedPromptFile) {
+		t.Errorf("expected an error naming prompt.md, got %v", err)
+	}
+
+	if _, err := ResetPrompts(); err != nil {
+		t.Fatalf("ResetPrompts() failed: %v", err)
+	}
+	if _, err := os.Stat(combined + ".bak"); err != nil {
+		t.Errorf("expected prompt.md moved to a backup: %v", err)
+	}
+	if got, err := LoadMessagePrompt(); err != nil || got != DefaultMessagePrompt {
+		t.Errorf("expected the default message prompt after reset, got %q (%v)", got, err)
+	}
+}
+
 // TestLoadReturnsDefaultsForMissingFile verifies that Load() returns
 // sensible defaults when config.yaml doesn't exist.
 func TestLoadReturnsDefaultsForMissingFile(t *testing.T) {
@@ -145,9 +288,8 @@ func TestLoadReturnsDefaultsForMissingFile(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpHome)
 
-	origHome := os.Getenv("HOME")
-	os.Setenv("HOME", tmpHome)
-	defer os.Setenv("HOME", origHome)
+	t.Setenv("HOME", tmpHome)
+	t.Setenv(DirEnv, "")
 
 	// Don't run Init - just try to load
 	cfg, err := Load()
@@ -179,3 +321,306 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+// TestSetValueValidation verifies that config keys are validated before
+// being assigned and that unknown keys are rejected.
+func TestSetValueValidation(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"model", "claude-haiku-4-5", false},
+		{"model", "", true},
+		{"provider", "anthropic", false},
+		{"provider", "openai", true},
+		{"base_url", "https://gateway.internal/anthropic", false},
+		{"base_url", "", false},
+		{"base_url", "not a url", true},
+		{"context_entries", "0", false},
+		{"context_entries", "-1", true},
+		{"persona_placement", "system", false},
+		{"persona_placement", "assistant", true},
+		{"entry_length", "short", false},
+		{"entry_length", "tweet", true},
+		{"daemon.selection", "random", false},
+		{"daemon.selection", "round_robin", false},
+		{"daemon.selection", "round-robin", true},
+		{"daemon.jitter", "0", false},
+		{"daemon.jitter", "0.25", false},
+		{"daemon.jitter", "1", false},
+		{"daemon.jitter", "1.5", true},
+		{"daemon.jitter", "-0.1", true},
+		{"daemon.jitter", "lots", true},
+		{"metrics.machine_type_override", "server", false},
+		{"metrics.machine_type_override", "", false},
+		{"metrics.machine_type_override", "mainframe", true},
+		{"daemon.catch_up", "false", false},
+		{"daemon.catch_up", "sometimes", true},
+		{"daemon.min_interval", "30m", false},
+		{"daemon.min_interval", "", false},
+		{"daemon.min_interval", "-5m", true},
+		{"daemon.min_interval", "often", true},
+		{"daemon.log_format", "json", false},
+		{"daemon.log_format", "text", false},
+		{"daemon.log_format", "xml", true},
+		{"keep_revisions", "true", false},
+		{"keep_revisions", "maybe", true},
+		{"classify_mood", "true", false},
+		{"classify_mood", "sometimes", true},
+		{"validate.min_chars", "0", false},
+		{"validate.min_chars", "200", false},
+		{"validate.min_chars", "-1", true},
+		{"validate.min_chars", "short", true},
+		{"validate.refusal_phrases", "I can't,As an AI", false},
+		{"validate.dedup", "retry", false},
+		{"validate.dedup", "skip", false},
+		{"validate.dedup", "off", false},
+		{"validate.dedup", "sometimes", true},
+		{"validate.dedup_similarity", "0.8", false},
+		{"validate.dedup_similarity", "1", false},
+		{"validate.dedup_similarity", "0", true},
+		{"validate.dedup_similarity", "1.2", true},
+		{"validate.dedup_similarity", "close", true},
+		{"display_timezone", "UTC", false},
+		{"display_timezone", "local", false},
+		{"display_timezone", "Mars/Olympus_Mons", true},
+		{"daemon.rate", "5", false},
+		{"daemon.rate", "zero", true},
+		{"daemon.rate_period", "week", false},
+		{"daemon.rate_period", "minute", false},
+		{"daemon.rate_period", "fortnight", true},
+		{"daemon.mode", "all", false},
+		{"daemon.mode", "some", true},
+		{"daemon.metrics_addr", ":9099", false},
+		{"daemon.metrics_addr", "", false},
+		{"daemon.metrics_addr", "9099", true},
+		{"daemon.webhook_url", "https://ntfy.sh/my-jernel", false},
+		{"daemon.webhook_url", "", false},
+		{"daemon.webhook_url", "ftp://example.com/hook", true},
+		{"post_process.strip_fences", "true", false},
+		{"post_process.trim", "maybe", true},
+		{"metrics.cpu_sample_ms", "250", false},
+		{"metrics.cpu_sample_ms", "0", false},
+		{"metrics.cpu_sample_ms", "-1", true},
+		{"post_process.max_paragraphs", "3", false},
+		{"post_process.max_paragraphs", "-2", true},
+		{"tui.timestamps", "absolute", false},
+		{"tui.timestamps", "sometimes", true},
+		{"tui.entry_limit", "500", false},
+		{"language", "Spanish", false},
+		{"language", "  ", true},
+		{"tui.entry_limit", "-5", true},
+		{"no_such_key", "x", true},
+	}
+
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		err := SetValue(cfg, tt.key, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SetValue(%q, %q) error = %v, wantErr %v", tt.key, tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+// TestSetValueRoundTrip verifies that values set through SetValue survive
+// Save and Load and are reported by GetValue.
+func TestSetValueRoundTrip(t *testing.T) {
+	tmpHome, err := os.MkdirTemp("", "jernel-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpHome)
+
+	t.Setenv("HOME", tmpHome)
+	t.Setenv(DirEnv, "")
+
+	cfg := DefaultConfig()
+	if err := SetValue(cfg, "daemon.personas", "alice, bob,,carol"); err != nil {
+		t.Fatalf("SetValue() failed: %v", err)
+	}
+	if err := SetValue(cfg, "daemon.rate", "7"); err != nil {
+		t.Fatalf("SetValue() failed: %v", err)
+	}
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	personas, err := GetValue(loaded, "daemon.personas")
+	if err != nil {
+		t.Fatalf("GetValue() failed: %v", err)
+	}
+	if personas != "alice,bob,carol" {
+		t.Errorf("expected personas 'alice,bob,carol', got %q", personas)
+	}
+
+	rate, _ := GetValue(loaded, "daemon.rate")
+	if rate != "7" {
+		t.Errorf("expected rate '7', got %q", rate)
+	}
+}
+
+// TestLoadExpandsEnv verifies ${VAR} and $VAR references are expanded on
+// Load, reported when unset, and kept as written by LoadRaw.
+func TestLoadExpandsEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(DirEnv, dir)
+	t.Setenv("JERNEL_TEST_MODEL", "claude-haiku-4-5")
+	t.Setenv("JERNEL_TEST_HOST", "gateway.example.com")
+
+	data := `model: ${JERNEL_TEST_MODEL}
+base_url: https://$JERNEL_TEST_HOST/anthropic
+default_persona: plain
+daemon:
+  personas: ["${JERNEL_TEST_MISSING}", literal]
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Model != "claude-haiku-4-5" {
+		t.Errorf("expected model from env, got %q", cfg.Model)
+	}
+	if cfg.BaseURL != "https://gateway.example.com/anthropic" {
+		t.Errorf("expected base_url from env, got %q", cfg.BaseURL)
+	}
+	if cfg.DefaultPersona != "plain" {
+		t.Errorf("expected literal value untouched, got %q", cfg.DefaultPersona)
+	}
+	if len(cfg.Daemon.Personas) != 2 || cfg.Daemon.Personas[0] != "" || cfg.Daemon.Personas[1] != "literal" {
+		t.Errorf("expected unset variable to expand to empty, got %q", cfg.Daemon.Personas)
+	}
+
+	raw, err := LoadRaw()
+	if err != nil {
+		t.Fatalf("LoadRaw() failed: %v", err)
+	}
+	if raw.Model != "${JERNEL_TEST_MODEL}" {
+		t.Errorf("expected LoadRaw to keep the reference, got %q", raw.Model)
+	}
+	unset := ExpandEnv(raw)
+	if len(unset) != 1 || unset[0] != "JERNEL_TEST_MISSING" {
+		t.Errorf("expected JERNEL_TEST_MISSING reported as unset, got %v", unset)
+	}
+}
+
+// TestDirHonorsEnv verifies JERNEL_CONFIG_DIR overrides the default
+// directory and that every path derives from it.
+func TestDirHonorsEnv(t *testing.T) {
+	custom := t.TempDir()
+	t.Setenv(DirEnv, custom)
+
+	dir, err := Dir()
+	if err != nil {
+		t.Fatalf("Dir() failed: %v", err)
+	}
+	if dir != custom {
+		t.Errorf("expected %s, got %s", custom, dir)
+	}
+
+	if err := Init(); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	path, _ := Path()
+	if path != filepath.Join(custom, "config.yaml") {
+		t.Errorf("expected config.yaml under %s, got %s", custom, path)
+	}
+	if _, err := os.Stat(filepath.Join(custom, "personas")); err != nil {
+		t.Errorf("expected personas directory under %s: %v", custom, err)
+	}
+
+	// Relative overrides resolve against the working directory
+	t.Setenv(DirEnv, "relative-config")
+	dir, err = Dir()
+	if err != nil {
+		t.Fatalf("Dir() failed: %v", err)
+	}
+	if !filepath.IsAbs(dir) {
+		t.Errorf("expected an absolute path, got %s", dir)
+	}
+}
+
+// TestProfiles verifies the active profile overlays its settings on the base
+// config, leaving the rest alone, and survives a save of the base settings.
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(DirEnv, dir)
+
+	data := `model: base-model
+default_persona: default
+daemon:
+  rate: 3
+  rate_period: day
+  personas: [default, dramatic]
+profiles:
+  work:
+    default_persona: prof_whitlock
+    daemon:
+      rate: 1
+      personas: [prof_whitlock]
+  home:
+    daemon:
+      rate: 12
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	t.Setenv(ProfileEnv, "")
+	base, err := Load()
+	if err != nil {
+		t.Fatalf("Load() without a profile failed: %v", err)
+	}
+	if base.DefaultPersona != "default" || base.Daemon.Rate != 3 || len(base.Daemon.Personas) != 2 {
+		t.Errorf("expected the base settings unchanged, got persona %q, rate %d, personas %v",
+			base.DefaultPersona, base.Daemon.Rate, base.Daemon.Personas)
+	}
+
+	t.Setenv(ProfileEnv, "work")
+	work, err := Load()
+	if err != nil {
+		t.Fatalf("Load() with the work profile failed: %v", err)
+	}
+	if work.DefaultPersona != "prof_whitlock" || work.Daemon.Rate != 1 {
+		t.Errorf("expected the profile's values, got persona %q, rate %d", work.DefaultPersona, work.Daemon.Rate)
+	}
+	if len(work.Daemon.Personas) != 1 || work.Daemon.Personas[0] != "prof_whitlock" {
+		t.Errorf("expected the profile's persona list to replace the base one, got %v", work.Daemon.Personas)
+	}
+	if work.Model != "base-model" || work.Daemon.RatePeriod != "day" || work.Daemon.Selection != "random" {
+		t.Errorf("expected unset values from the base and defaults, got model %q, period %q, selection %q",
+			work.Model, work.Daemon.RatePeriod, work.Daemon.Selection)
+	}
+
+	t.Setenv(ProfileEnv, "gym")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "home, work") {
+		t.Errorf("expected an unknown profile error listing the profiles, got %v", err)
+	}
+
+	// Saving the base settings keeps the profiles
+	raw, err := LoadRaw()
+	if err != nil {
+		t.Fatalf("LoadRaw() failed: %v", err)
+	}
+	raw.Model = "new-model"
+	if err := Save(raw); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	t.Setenv(ProfileEnv, "home")
+	home, err := Load()
+	if err != nil {
+		t.Fatalf("Load() after Save() failed: %v", err)
+	}
+	if home.Model != "new-model" || home.Daemon.Rate != 12 {
+		t.Errorf("expected the saved base with the home profile, got model %q, rate %d", home.Model, home.Daemon.Rate)
+	}
+}
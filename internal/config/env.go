@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ExpandEnv replaces ${VAR} and $VAR references in every string setting with
+// the variable's value. Values without a $ are left exactly as written, and
+// unset variables expand to empty; their names are returned, sorted, so the
+// caller can warn about them
+func ExpandEnv(cfg *Config) []string {
+	unset := map[string]bool{}
+	expandValue(reflect.ValueOf(cfg).Elem(), unset)
+
+	names := make([]string, 0, len(unset))
+	for name := range unset {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// expandValue walks strings, string slices, and nested config sections
+func expandValue(v reflect.Value, unset map[string]bool) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			expandValue(v.Elem(), unset)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				expandValue(v.Field(i), unset)
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			expandValue(v.Index(i), unset)
+		}
+	case reflect.String:
+		if strings.Contains(v.String(), "$") {
+			v.SetString(expandString(v.String(), unset))
+		}
+	}
+}
+
+// expandString expands one value, recording variables that aren't set
+func expandString(s string, unset map[string]bool) string {
+	return os.Expand(s, func(name string) string {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			unset[name] = true
+		}
+		return value
+	})
+}
@@ -0,0 +1,515 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cldixon/jernel/internal/timefmt"
+)
+
+// RatePeriods lists the supported daemon rate periods
+var RatePeriods = []string{"minute", "hour", "day", "week", "month"}
+
+// EntryLengths lists the supported entry_length values
+var EntryLengths = []string{"short", "medium", "long"}
+
+// field describes how to read and write a single config key
+type field struct {
+	get func(cfg *Config) string
+	set func(cfg *Config, value string) error
+}
+
+// fields maps dotted config keys to their accessors
+var fields = map[string]field{
+	"provider": {
+		get: func(cfg *Config) string { return cfg.Provider },
+		set: func(cfg *Config, value string) error {
+			if value != "anthropic" {
+				return fmt.Errorf("unsupported provider: %s (must be anthropic)", value)
+			}
+			cfg.Provider = value
+			return nil
+		},
+	},
+	"model": {
+		get: func(cfg *Config) string { return cfg.Model },
+		set: func(cfg *Config, value string) error {
+			if value == "" {
+				return fmt.Errorf("model cannot be empty")
+			}
+			cfg.Model = value
+			return nil
+		},
+	},
+	"base_url": {
+		get: func(cfg *Config) string { return cfg.BaseURL },
+		set: func(cfg *Config, value string) error {
+			if value != "" {
+				u, err := url.Parse(value)
+				if err != nil || u.Scheme == "" || u.Host == "" {
+					return fmt.Errorf("invalid base_url: %s (must be an absolute URL, or empty for the default)", value)
+				}
+			}
+			cfg.BaseURL = value
+			return nil
+		},
+	},
+	"api_key_file": {
+		get: func(cfg *Config) string { return cfg.APIKeyFile },
+		set: func(cfg *Config, value string) error {
+			cfg.APIKeyFile = value
+			return nil
+		},
+	},
+	"api_key_keychain": {
+		get: func(cfg *Config) string { return strconv.FormatBool(cfg.APIKeyKeychain) },
+		set: func(cfg *Config, value string) error {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid api_key_keychain: %s (must be true or false)", value)
+			}
+			cfg.APIKeyKeychain = b
+			return nil
+		},
+	},
+	"default_persona": {
+		get: func(cfg *Config) string { return cfg.DefaultPersona },
+		set: func(cfg *Config, value string) error {
+			if value == "" {
+				return fmt.Errorf("default_persona cannot be empty")
+			}
+			cfg.DefaultPersona = value
+			return nil
+		},
+	},
+	"context_entries": {
+		get: func(cfg *Config) string { return strconv.Itoa(cfg.ContextEntries) },
+		set: func(cfg *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid context_entries: %s (must be a non-negative integer)", value)
+			}
+			cfg.ContextEntries = n
+			return nil
+		},
+	},
+	"context_max_chars": {
+		get: func(cfg *Config) string { return strconv.Itoa(cfg.ContextMaxChars) },
+		set: func(cfg *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid context_max_chars: %s (must be a non-negative integer, 0 for no limit)", value)
+			}
+			cfg.ContextMaxChars = n
+			return nil
+		},
+	},
+	"context_budget_chars": {
+		get: func(cfg *Config) string { return strconv.Itoa(cfg.ContextBudgetChars) },
+		set: func(cfg *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid context_budget_chars: %s (must be a non-negative integer, 0 for no limit)", value)
+			}
+			cfg.ContextBudgetChars = n
+			return nil
+		},
+	},
+	"compress_metrics": {
+		get: func(cfg *Config) string { return strconv.FormatBool(cfg.CompressMetrics) },
+		set: func(cfg *Config, value string) error {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid compress_metrics: %s (must be true or false)", value)
+			}
+			cfg.CompressMetrics = b
+			return nil
+		},
+	},
+	"keep_revisions": {
+		get: func(cfg *Config) string { return strconv.FormatBool(cfg.KeepRevisions) },
+		set: func(cfg *Config, value string) error {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid keep_revisions: %s (must be true or false)", value)
+			}
+			cfg.KeepRevisions = b
+			return nil
+		},
+	},
+	"classify_mood": {
+		get: func(cfg *Config) string { return strconv.FormatBool(cfg.ClassifyMood) },
+		set: func(cfg *Config, value string) error {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid classify_mood: %s (must be true or false)", value)
+			}
+			cfg.ClassifyMood = b
+			return nil
+		},
+	},
+	"persona_placement": {
+		get: func(cfg *Config) string { return cfg.PersonaPlacement },
+		set: func(cfg *Config, value string) error {
+			if err := oneOf("persona_placement", value, []string{"user", "system"}); err != nil {
+				return err
+			}
+			cfg.PersonaPlacement = value
+			return nil
+		},
+	},
+	"entry_length": {
+		get: func(cfg *Config) string { return cfg.EntryLength },
+		set: func(cfg *Config, value string) error {
+			if err := oneOf("entry_length", value, EntryLengths); err != nil {
+				return err
+			}
+			cfg.EntryLength = value
+			return nil
+		},
+	},
+	"language": {
+		get: func(cfg *Config) string { return cfg.Language },
+		set: func(cfg *Config, value string) error {
+			if strings.TrimSpace(value) == "" {
+				return fmt.Errorf("invalid language: must not be empty (e.g. English or Spanish)")
+			}
+			cfg.Language = strings.TrimSpace(value)
+			return nil
+		},
+	},
+	"display_timezone": {
+		get: func(cfg *Config) string { return cfg.DisplayTimezone },
+		set: func(cfg *Config, value string) error {
+			if _, err := timefmt.LoadLocation(value); err != nil {
+				return fmt.Errorf("invalid display_timezone: %s (must be an IANA name like Europe/Berlin, or local)", value)
+			}
+			cfg.DisplayTimezone = value
+			return nil
+		},
+	},
+	"daemon.rate": {
+		get: func(cfg *Config) string { return strconv.Itoa(cfg.Daemon.Rate) },
+		set: func(cfg *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid daemon.rate: %s (must be a positive integer)", value)
+			}
+			cfg.Daemon.Rate = n
+			return nil
+		},
+	},
+	"daemon.rate_period": {
+		get: func(cfg *Config) string { return cfg.Daemon.RatePeriod },
+		set: func(cfg *Config, value string) error {
+			if err := oneOf("daemon.rate_period", value, RatePeriods); err != nil {
+				return err
+			}
+			cfg.Daemon.RatePeriod = value
+			return nil
+		},
+	},
+	"daemon.personas": {
+		get: func(cfg *Config) string { return strings.Join(cfg.Daemon.Personas, ",") },
+		set: func(cfg *Config, value string) error {
+			personas := []string{}
+			for _, name := range strings.Split(value, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					personas = append(personas, name)
+				}
+			}
+			cfg.Daemon.Personas = personas
+			return nil
+		},
+	},
+	"daemon.mode": {
+		get: func(cfg *Config) string { return cfg.Daemon.Mode },
+		set: func(cfg *Config, value string) error {
+			if err := oneOf("daemon.mode", value, []string{"single", "all"}); err != nil {
+				return err
+			}
+			cfg.Daemon.Mode = value
+			return nil
+		},
+	},
+	"daemon.selection": {
+		get: func(cfg *Config) string { return cfg.Daemon.Selection },
+		set: func(cfg *Config, value string) error {
+			if err := oneOf("daemon.selection", value, []string{"random", "round_robin"}); err != nil {
+				return err
+			}
+			cfg.Daemon.Selection = value
+			return nil
+		},
+	},
+	"daemon.jitter": {
+		get: func(cfg *Config) string { return strconv.FormatFloat(cfg.Daemon.Jitter, 'g', -1, 64) },
+		set: func(cfg *Config, value string) error {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil || f < 0 || f > 1 {
+				return fmt.Errorf("invalid daemon.jitter: %s (must be between 0 and 1)", value)
+			}
+			cfg.Daemon.Jitter = f
+			return nil
+		},
+	},
+	"daemon.min_interval": {
+		get: func(cfg *Config) string { return cfg.Daemon.MinInterval },
+		set: func(cfg *Config, value string) error {
+			if value != "" {
+				d, err := time.ParseDuration(value)
+				if err != nil || d < 0 {
+					return fmt.Errorf("invalid daemon.min_interval: %s (must be a duration like 30m or 2h, or empty)", value)
+				}
+			}
+			cfg.Daemon.MinInterval = value
+			return nil
+		},
+	},
+	"daemon.log_format": {
+		get: func(cfg *Config) string { return cfg.Daemon.LogFormat },
+		set: func(cfg *Config, value string) error {
+			if err := oneOf("daemon.log_format", value, []string{"text", "json"}); err != nil {
+				return err
+			}
+			cfg.Daemon.LogFormat = value
+			return nil
+		},
+	},
+	"daemon.catch_up": {
+		get: func(cfg *Config) string { return strconv.FormatBool(cfg.Daemon.CatchUp) },
+		set: func(cfg *Config, value string) error {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid daemon.catch_up: %s (must be true or false)", value)
+			}
+			cfg.Daemon.CatchUp = b
+			return nil
+		},
+	},
+	"daemon.metrics_addr": {
+		get: func(cfg *Config) string { return cfg.Daemon.MetricsAddr },
+		set: func(cfg *Config, value string) error {
+			if value != "" {
+				if _, _, err := net.SplitHostPort(value); err != nil {
+					return fmt.Errorf("invalid daemon.metrics_addr: %s (must be host:port or :port, or empty to disable)", value)
+				}
+			}
+			cfg.Daemon.MetricsAddr = value
+			return nil
+		},
+	},
+	"daemon.webhook_url": {
+		get: func(cfg *Config) string { return cfg.Daemon.WebhookURL },
+		set: func(cfg *Config, value string) error {
+			if value != "" {
+				u, err := url.Parse(value)
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Errorf("invalid daemon.webhook_url: %s (must be an http or https URL, or empty to disable)", value)
+				}
+			}
+			cfg.Daemon.WebhookURL = value
+			return nil
+		},
+	},
+	"metrics.fan_command": {
+		get: func(cfg *Config) string { return cfg.Metrics.FanCommand },
+		set: func(cfg *Config, value string) error {
+			cfg.Metrics.FanCommand = value
+			return nil
+		},
+	},
+	"metrics.machine_type_override": {
+		get: func(cfg *Config) string { return cfg.Metrics.MachineTypeOverride },
+		set: func(cfg *Config, value string) error {
+			if value != "" {
+				if err := oneOf("metrics.machine_type_override", value, []string{"laptop", "desktop", "server", "virtual_machine", "container"}); err != nil {
+					return err
+				}
+			}
+			cfg.Metrics.MachineTypeOverride = value
+			return nil
+		},
+	},
+	"metrics.cpu_sample_ms": {
+		get: func(cfg *Config) string { return strconv.Itoa(cfg.Metrics.CPUSampleMS) },
+		set: func(cfg *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid metrics.cpu_sample_ms: %s (must be a non-negative integer, 0 for no wait)", value)
+			}
+			cfg.Metrics.CPUSampleMS = n
+			return nil
+		},
+	},
+	"post_process.trim": {
+		get: func(cfg *Config) string { return strconv.FormatBool(cfg.PostProcess.Trim) },
+		set: func(cfg *Config, value string) error {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid post_process.trim: %s (must be true or false)", value)
+			}
+			cfg.PostProcess.Trim = b
+			return nil
+		},
+	},
+	"post_process.strip_fences": {
+		get: func(cfg *Config) string { return strconv.FormatBool(cfg.PostProcess.StripFences) },
+		set: func(cfg *Config, value string) error {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid post_process.strip_fences: %s (must be true or false)", value)
+			}
+			cfg.PostProcess.StripFences = b
+			return nil
+		},
+	},
+	"post_process.max_paragraphs": {
+		get: func(cfg *Config) string { return strconv.Itoa(cfg.PostProcess.MaxParagraphs) },
+		set: func(cfg *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid post_process.max_paragraphs: %s (must be a non-negative integer, 0 for no limit)", value)
+			}
+			cfg.PostProcess.MaxParagraphs = n
+			return nil
+		},
+	},
+	"validate.min_chars": {
+		get: func(cfg *Config) string { return strconv.Itoa(cfg.Validate.MinChars) },
+		set: func(cfg *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid validate.min_chars: %s (must be a non-negative integer, 0 for no minimum)", value)
+			}
+			cfg.Validate.MinChars = n
+			return nil
+		},
+	},
+	"validate.refusal_phrases": {
+		get: func(cfg *Config) string { return strings.Join(cfg.Validate.RefusalPhrases, ",") },
+		set: func(cfg *Config, value string) error {
+			phrases := []string{}
+			for _, phrase := range strings.Split(value, ",") {
+				if phrase = strings.TrimSpace(phrase); phrase != "" {
+					phrases = append(phrases, phrase)
+				}
+			}
+			cfg.Validate.RefusalPhrases = phrases
+			return nil
+		},
+	},
+	"validate.dedup": {
+		get: func(cfg *Config) string { return cfg.Validate.Dedup },
+		set: func(cfg *Config, value string) error {
+			if err := oneOf("validate.dedup", value, []string{"off", "retry", "skip"}); err != nil {
+				return err
+			}
+			cfg.Validate.Dedup = value
+			return nil
+		},
+	},
+	"validate.dedup_similarity": {
+		get: func(cfg *Config) string { return strconv.FormatFloat(cfg.Validate.DedupSimilarity, 'g', -1, 64) },
+		set: func(cfg *Config, value string) error {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil || f <= 0 || f > 1 {
+				return fmt.Errorf("invalid validate.dedup_similarity: %s (must be above 0 and at most 1)", value)
+			}
+			cfg.Validate.DedupSimilarity = f
+			return nil
+		},
+	},
+	"tui.timestamps": {
+		get: func(cfg *Config) string { return cfg.TUI.Timestamps },
+		set: func(cfg *Config, value string) error {
+			if err := oneOf("tui.timestamps", value, []string{"relative", "absolute"}); err != nil {
+				return err
+			}
+			cfg.TUI.Timestamps = value
+			return nil
+		},
+	},
+	"tui.entry_limit": {
+		get: func(cfg *Config) string { return strconv.Itoa(cfg.TUI.EntryLimit) },
+		set: func(cfg *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid tui.entry_limit: %s (must be a non-negative integer, 0 for no limit)", value)
+			}
+			cfg.TUI.EntryLimit = n
+			return nil
+		},
+	},
+}
+
+// Keys returns all settable config keys in sorted order
+func Keys() []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetValue returns the value of a config key as a string
+func GetValue(cfg *Config, key string) (string, error) {
+	f, err := lookupField(key)
+	if err != nil {
+		return "", err
+	}
+	fillDefaults(cfg)
+	return f.get(cfg), nil
+}
+
+// SetValue validates and assigns a config key from its string form
+func SetValue(cfg *Config, key string, value string) error {
+	f, err := lookupField(key)
+	if err != nil {
+		return err
+	}
+	fillDefaults(cfg)
+	return f.set(cfg, value)
+}
+
+func lookupField(key string) (field, error) {
+	f, ok := fields[key]
+	if !ok {
+		return field{}, fmt.Errorf("unknown config key: %s (valid keys: %s)", key, strings.Join(Keys(), ", "))
+	}
+	return f, nil
+}
+
+func oneOf(key string, value string, allowed []string) error {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid %s: %s (must be one of: %s)", key, value, strings.Join(allowed, ", "))
+}
+
+// fillDefaults ensures nested sections exist so accessors can dereference them
+func fillDefaults(cfg *Config) {
+	if cfg.Daemon == nil {
+		cfg.Daemon = DefaultDaemonConfig()
+	}
+	if cfg.Metrics == nil {
+		cfg.Metrics = DefaultMetricsConfig()
+	}
+	if cfg.TUI == nil {
+		cfg.TUI = DefaultTUIConfig()
+	}
+	if cfg.PostProcess == nil {
+		cfg.PostProcess = DefaultPostProcessConfig()
+	}
+	if cfg.Validate == nil {
+		cfg.Validate = DefaultValidateConfig()
+	}
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profiles maps profile names to their settings, kept as YAML until one is applied
+type Profiles map[string]yaml.Node
+
+// ProfileEnv is the environment variable that selects a profile from config.yaml
+// The --profile flag sets it too, so child processes use the same profile
+const ProfileEnv = "JERNEL_PROFILE"
+
+// ActiveProfile returns the profile named by ProfileEnv (empty = base settings only)
+func ActiveProfile() string {
+	return strings.TrimSpace(os.Getenv(ProfileEnv))
+}
+
+// ProfileNames returns the profiles defined in cfg, sorted
+func ProfileNames(cfg *Config) []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProfile overlays the named profile on cfg. Settings the profile sets
+// replace the base ones (lists included), nested sections merge key by key,
+// and everything else is left as is. An empty name leaves cfg unchanged
+func ApplyProfile(cfg *Config, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	node, ok := cfg.Profiles[name]
+	if !ok {
+		if len(cfg.Profiles) == 0 {
+			return fmt.Errorf("unknown profile '%s' (config.yaml defines no profiles)", name)
+		}
+		return fmt.Errorf("unknown profile '%s' (defined: %s)", name, strings.Join(ProfileNames(cfg), ", "))
+	}
+
+	// Profiles don't nest, so keep the base list of them
+	profiles := cfg.Profiles
+	if err := node.Decode(cfg); err != nil {
+		return fmt.Errorf("failed to apply profile '%s': %w", name, err)
+	}
+	cfg.Profiles = profiles
+
+	fillDefaults(cfg)
+	return nil
+}
@@ -3,32 +3,79 @@ package daemon
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
-	"log"
 	"math/big"
+	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/cldixon/jernel/internal/config"
 	"github.com/cldixon/jernel/internal/entry"
+	"github.com/cldixon/jernel/internal/prompt"
 )
 
+// Generation modes for each trigger
+const (
+	ModeSingle = "single" // one randomly selected persona
+	ModeAll    = "all"    // every configured persona
+)
+
+// ValidateMode checks that a daemon mode is supported (empty means single)
+func ValidateMode(mode string) error {
+	switch mode {
+	case "", ModeSingle, ModeAll:
+		return nil
+	default:
+		return fmt.Errorf("invalid daemon mode: %s (must be single or all)", mode)
+	}
+}
+
+// Persona selection strategies for single mode
+const (
+	SelectionRandom     = "random"      // a random configured persona each trigger
+	SelectionRoundRobin = "round_robin" // each configured persona in turn
+)
+
+// ValidateSelection checks that a persona selection strategy is supported (empty means random)
+func ValidateSelection(selection string) error {
+	switch selection {
+	case "", SelectionRandom, SelectionRoundRobin:
+		return nil
+	default:
+		return fmt.Errorf("invalid daemon selection: %s (must be random or round_robin)", selection)
+	}
+}
+
 // Daemon manages autonomous journal entry generation
 type Daemon struct {
 	cfg      *config.Config
 	state    *State
+	mu       sync.Mutex // guards state while the metrics server reads it
+	server   *http.Server
 	shutdown chan struct{}
+	reload   chan struct{}
 	done     chan struct{}
-	logger   *log.Logger
+	logger   *logger
+
+	minInterval time.Duration // floor for the wait between triggers, from daemon.min_interval
+
+	webhooks sync.WaitGroup // deliveries still in flight, waited for before shutdown
+
+	// LoadConfig reads the settings applied by Reload (defaults to config.Load)
+	LoadConfig func() (*config.Config, error)
 }
 
 // New creates a new daemon instance
 func New(cfg *config.Config) *Daemon {
 	return &Daemon{
-		cfg:      cfg,
-		shutdown: make(chan struct{}),
-		done:     make(chan struct{}),
-		logger:   log.New(os.Stdout, "[jernel-daemon] ", log.LstdFlags),
+		cfg:        cfg,
+		shutdown:   make(chan struct{}),
+		reload:     make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		logger:     newLogger(os.Stdout, cfg.Daemon.LogFormat),
+		LoadConfig: config.Load,
 	}
 }
 
@@ -43,33 +90,101 @@ func (d *Daemon) Start(ctx context.Context) error {
 		return fmt.Errorf("daemon already running with PID %d", pid)
 	}
 
+	if err := ValidateMode(d.cfg.Daemon.Mode); err != nil {
+		return err
+	}
+	if err := ValidateSelection(d.cfg.Daemon.Selection); err != nil {
+		return err
+	}
+	if err := ValidateLogFormat(d.cfg.Daemon.LogFormat); err != nil {
+		return err
+	}
+	if d.minInterval, err = ParseMinInterval(d.cfg.Daemon.MinInterval); err != nil {
+		return err
+	}
+	if err := ValidateWebhookURL(d.cfg.Daemon.WebhookURL); err != nil {
+		return err
+	}
+
 	// Write PID file
 	if err := WritePID(); err != nil {
 		return fmt.Errorf("failed to write PID file: %w", err)
 	}
 
 	// Initialize state
-	nextTrigger, err := CalculateNextTrigger(d.cfg.Daemon.Rate, d.cfg.Daemon.RatePeriod)
+	nextTrigger, err := CalculateNextTrigger(d.cfg.Daemon.Rate, d.cfg.Daemon.RatePeriod, d.cfg.Daemon.Jitter, d.minInterval)
 	if err != nil {
 		RemovePID()
 		return fmt.Errorf("failed to calculate next trigger: %w", err)
 	}
 
+	// Pick up the previous run's schedule, catching up on a missed trigger,
+	// and its place in the round-robin rotation
+	prev, err := LoadState()
+	if err != nil {
+		d.logger.Warn("Failed to load previous state", "error", err)
+	}
+	var missed time.Time
+	if d.cfg.Daemon.CatchUp {
+		nextTrigger, missed = resumeTrigger(prev, time.Now(), nextTrigger)
+	}
+	lastIndex := -1
+	var lastEntryID int64
+	if prev != nil {
+		lastIndex = prev.LastIndex
+		lastEntryID = prev.LastEntryID
+	}
+
 	d.state = &State{
 		PID:              os.Getpid(),
 		StartedAt:        time.Now(),
 		NextTrigger:      nextTrigger,
 		EntriesGenerated: 0,
+		Heartbeat:        time.Now(),
+		LastIndex:        lastIndex,
+		LastEntryID:      lastEntryID,
+	}
+
+	// Bind the metrics listener before the state file is rewritten, so a busy
+	// port leaves the previous run's state (rotation, catch-up, undo) intact
+	if d.cfg.Daemon.MetricsAddr != "" {
+		if err := d.startMetricsServer(d.cfg.Daemon.MetricsAddr); err != nil {
+			RemovePID()
+			return err
+		}
 	}
 
 	if err := SaveState(d.state); err != nil {
+		d.stopMetricsServer()
 		RemovePID()
 		return fmt.Errorf("failed to save initial state: %w", err)
 	}
 
-	d.logger.Printf("Daemon started (PID: %d)", d.state.PID)
-	d.logger.Printf("Rate: %d entries per %s", d.cfg.Daemon.Rate, d.cfg.Daemon.RatePeriod)
-	d.logger.Printf("Next entry scheduled for: %s", d.state.NextTrigger.Format(time.RFC1123))
+	mode := d.cfg.Daemon.Mode
+	if mode == "" {
+		mode = ModeSingle
+	}
+	d.logger.Info("Daemon started", "pid", d.state.PID)
+	d.logger.Info("Schedule configured",
+		"rate", d.cfg.Daemon.Rate,
+		"rate_period", d.cfg.Daemon.RatePeriod,
+		"jitter", d.cfg.Daemon.Jitter,
+		"mode", mode,
+		"selection", d.selection())
+	if avg, err := AverageInterval(d.cfg.Daemon.Rate, d.cfg.Daemon.RatePeriod); err == nil && d.minInterval > avg {
+		d.logger.Warn("min_interval exceeds the average interval, so entries will come less often than the configured rate",
+			"min_interval", d.minInterval.String(), "average_interval", avg.String())
+	}
+	if !missed.IsZero() {
+		d.logger.Info("Previous run missed a trigger, catching up now", "missed_trigger", missed)
+	} else {
+		d.logger.Info("Next entry scheduled", "next_trigger", d.state.NextTrigger)
+	}
+
+	// Warn about template errors now rather than at the first trigger
+	if err := prompt.ValidateMessagePrompt(); err != nil {
+		d.logger.Warn("Message prompt is invalid", "error", err)
+	}
 
 	// Run main loop
 	go d.run(ctx)
@@ -77,11 +192,72 @@ func (d *Daemon) Start(ctx context.Context) error {
 	return nil
 }
 
+// RunOnce generates the entries for a single trigger and returns, for cron-driven
+// setups. It writes no PID file and runs no schedule, but records the entries in
+// the state file so status and undo see them. It refuses while a daemon is running
+func (d *Daemon) RunOnce(ctx context.Context) error {
+	running, pid, err := IsRunning()
+	if err != nil {
+		return fmt.Errorf("failed to check running state: %w", err)
+	}
+	if running {
+		return fmt.Errorf("daemon already running with PID %d", pid)
+	}
+	if err := ValidateMode(d.cfg.Daemon.Mode); err != nil {
+		return err
+	}
+	if err := ValidateSelection(d.cfg.Daemon.Selection); err != nil {
+		return err
+	}
+	if err := ValidateLogFormat(d.cfg.Daemon.LogFormat); err != nil {
+		return err
+	}
+	if err := ValidateWebhookURL(d.cfg.Daemon.WebhookURL); err != nil {
+		return err
+	}
+
+	// Keep the previous run's schedule so a later start can still catch up,
+	// and its place in the round-robin rotation
+	prev, err := LoadState()
+	if err != nil {
+		d.logger.Warn("Failed to load previous state", "error", err)
+	}
+	if prev == nil {
+		prev = &State{LastIndex: -1}
+	}
+	d.state = prev
+
+	genErr := d.generateEntry(ctx)
+	d.webhooks.Wait()
+	d.mu.Lock()
+	err = SaveState(d.state)
+	d.mu.Unlock()
+	if err != nil {
+		d.logger.Warn("Failed to save state", "error", err)
+	}
+	return genErr
+}
+
+// resumeTrigger chooses the first trigger for a new run: now if the previous
+// run's trigger already passed (also returned as missed), otherwise fresh.
+// Only one entry is caught up however long the daemon was down
+func resumeTrigger(prev *State, now time.Time, fresh time.Time) (next time.Time, missed time.Time) {
+	if prev == nil || prev.NextTrigger.IsZero() || prev.NextTrigger.After(now) {
+		return fresh, time.Time{}
+	}
+	return now, prev.NextTrigger
+}
+
 // run is the main daemon loop
 func (d *Daemon) run(ctx context.Context) {
 	defer close(d.done)
 	defer d.cleanup()
+	defer d.webhooks.Wait()
+
+	heartbeat := time.NewTicker(HeartbeatInterval)
+	defer heartbeat.Stop()
 
+loop:
 	for {
 		// Calculate time until next trigger
 		waitDuration := time.Until(d.state.NextTrigger)
@@ -89,66 +265,250 @@ func (d *Daemon) run(ctx context.Context) {
 			waitDuration = 0
 		}
 
-		d.logger.Printf("Waiting %s until next entry...", waitDuration.Round(time.Second))
-
-		select {
-		case <-ctx.Done():
-			d.logger.Println("Context cancelled, shutting down...")
-			return
-		case <-d.shutdown:
-			d.logger.Println("Shutdown signal received...")
-			return
-		case <-time.After(waitDuration):
-			// Time to generate an entry
-			if err := d.generateEntry(ctx); err != nil {
-				d.logger.Printf("Error generating entry: %v", err)
+		d.logger.Info("Waiting until next entry", "wait", waitDuration.Round(time.Second))
+		trigger := time.NewTimer(waitDuration)
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				trigger.Stop()
+				d.logger.Info("Context cancelled, shutting down")
+				return
+			case <-d.shutdown:
+				trigger.Stop()
+				d.logger.Info("Shutdown signal received")
+				return
+			case <-d.reload:
+				// A new schedule replaces the pending trigger instead of firing it
+				if d.reloadConfig() {
+					trigger.Stop()
+					continue loop
+				}
+			case <-heartbeat.C:
+				d.beat()
+				// Timers don't advance while the machine sleeps, so compare
+				// wall clocks to fire a trigger that passed during a suspend
+				if !time.Now().Round(0).Before(d.state.NextTrigger.Round(0)) {
+					trigger.Stop()
+					break wait
+				}
+			case <-trigger.C:
+				break wait
 			}
+		}
 
-			// Schedule next trigger
-			nextTrigger, err := CalculateNextTrigger(d.cfg.Daemon.Rate, d.cfg.Daemon.RatePeriod)
-			if err != nil {
-				d.logger.Printf("Error calculating next trigger: %v", err)
-				continue
-			}
+		// Time to generate an entry
+		d.generateEntry(ctx)
 
-			d.state.NextTrigger = nextTrigger
-			if err := SaveState(d.state); err != nil {
-				d.logger.Printf("Error saving state: %v", err)
-			}
+		// Schedule next trigger
+		nextTrigger, err := CalculateNextTrigger(d.cfg.Daemon.Rate, d.cfg.Daemon.RatePeriod, d.cfg.Daemon.Jitter, d.minInterval)
+		if err != nil {
+			d.logger.Error("Failed to calculate next trigger", "error", err)
+			continue
+		}
 
-			d.logger.Printf("Next entry scheduled for: %s", d.state.NextTrigger.Format(time.RFC1123))
+		d.mu.Lock()
+		d.state.NextTrigger = nextTrigger
+		d.state.Heartbeat = time.Now()
+		err = SaveState(d.state)
+		d.mu.Unlock()
+		if err != nil {
+			d.logger.Error("Failed to save state", "error", err)
 		}
+
+		d.logger.Info("Next entry scheduled", "next_trigger", nextTrigger)
+	}
+}
+
+// Reload asks the main loop to re-read config with LoadConfig and reschedule
+// the next entry; it returns immediately and is safe to call from a signal handler
+func (d *Daemon) Reload() {
+	select {
+	case d.reload <- struct{}{}:
+	default: // a reload is already pending
 	}
 }
 
-// generateEntry creates a new journal entry
+// reloadConfig swaps in freshly loaded settings and reschedules from now,
+// reporting whether it did. Invalid settings are logged and the old ones kept.
+// Only the main loop reads d.cfg, so swapping it here needs no lock
+func (d *Daemon) reloadConfig() bool {
+	cfg, err := d.LoadConfig()
+	if err == nil {
+		err = ValidateMode(cfg.Daemon.Mode)
+	}
+	if err == nil {
+		err = ValidateSelection(cfg.Daemon.Selection)
+	}
+	if err == nil {
+		err = ValidateWebhookURL(cfg.Daemon.WebhookURL)
+	}
+	var minInterval time.Duration
+	if err == nil {
+		minInterval, err = ParseMinInterval(cfg.Daemon.MinInterval)
+	}
+	var nextTrigger time.Time
+	if err == nil {
+		nextTrigger, err = CalculateNextTrigger(cfg.Daemon.Rate, cfg.Daemon.RatePeriod, cfg.Daemon.Jitter, minInterval)
+	}
+	if err != nil {
+		d.logger.Error("Failed to reload config, keeping the current settings", "error", err)
+		return false
+	}
+
+	// The logger and metrics server are set up once at start
+	if cfg.Daemon.LogFormat != d.cfg.Daemon.LogFormat || cfg.Daemon.MetricsAddr != d.cfg.Daemon.MetricsAddr {
+		d.logger.Warn("log_format and metrics_addr changes take effect after a restart")
+	}
+	d.cfg = cfg
+	d.minInterval = minInterval
+
+	d.mu.Lock()
+	d.state.NextTrigger = nextTrigger
+	err = SaveState(d.state)
+	d.mu.Unlock()
+	if err != nil {
+		d.logger.Error("Failed to save state", "error", err)
+	}
+
+	mode := cfg.Daemon.Mode
+	if mode == "" {
+		mode = ModeSingle
+	}
+	d.logger.Info("Config reloaded",
+		"rate", cfg.Daemon.Rate,
+		"rate_period", cfg.Daemon.RatePeriod,
+		"jitter", cfg.Daemon.Jitter,
+		"mode", mode,
+		"selection", d.selection(),
+		"personas", len(cfg.Daemon.Personas))
+	d.logger.Info("Next entry scheduled", "next_trigger", nextTrigger)
+	return true
+}
+
+// beat records a heartbeat so supervisors can tell the loop is alive
+func (d *Daemon) beat() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.state.Heartbeat = time.Now()
+	if err := SaveState(d.state); err != nil {
+		d.logger.Warn("Failed to save heartbeat", "error", err)
+	}
+}
+
+// generateEntry creates journal entries for the personas selected for this trigger
+// Failures are logged per persona so one bad persona doesn't stop the others,
+// and returned joined for callers that report them
 func (d *Daemon) generateEntry(ctx context.Context) error {
-	// Select persona
-	personaName := d.selectPersona()
+	var errs []error
+	for _, personaName := range d.selectPersonas() {
+		if err := d.generateForPersona(ctx, personaName); err != nil {
+			d.logger.Error("Failed to generate entry", "persona", personaName, "error", err)
+			d.mu.Lock()
+			d.state.Errors++
+			d.mu.Unlock()
+			errs = append(errs, fmt.Errorf("persona '%s': %w", personaName, err))
+		}
+		// Each persona can take several LLM calls, so beat between them to
+		// keep a long "all" trigger from looking stale to daemon ping
+		d.beat()
+	}
+	return errors.Join(errs...)
+}
+
+// RejectedRetries is how many more times a persona is asked to write when its
+// entry is rejected as a refusal or too short
+const RejectedRetries = 2
+
+// isRejected reports whether err is generated content failing validation
+func isRejected(err error) bool {
+	var rejected *entry.RejectedError
+	return errors.As(err, &rejected)
+}
 
-	d.logger.Printf("Generating entry with persona: %s", personaName)
+// generateForPersona creates a single journal entry with the given persona
+func (d *Daemon) generateForPersona(ctx context.Context, personaName string) error {
+	d.logger.Info("Generating entry", "persona", personaName)
 
-	// Generate entry using the entry package
-	result, err := entry.Generate(ctx, d.cfg, personaName)
+	// Generate entry using the entry package; in "all" mode the personas
+	// writing on one trigger share a metrics snapshot
+	var opts entry.Options
+	if d.cfg.Daemon.Mode == ModeAll {
+		opts.MetricsMaxAge = entry.BatchMetricsMaxAge
+	}
+	result, err := entry.GenerateWithOptions(ctx, d.cfg, personaName, opts)
+	for attempt := 1; attempt <= RejectedRetries && isRejected(err); attempt++ {
+		d.logger.Warn("Generated entry rejected, retrying",
+			"persona", personaName,
+			"attempt", attempt,
+			"error", err)
+		result, err = entry.GenerateWithOptions(ctx, d.cfg, personaName, opts)
+	}
+	// Skipping a repeat is the configured outcome, not a failure
+	if errors.Is(err, entry.ErrDuplicate) {
+		d.logger.Info("Skipped entry that repeats the persona's last one", "persona", personaName, "reason", err)
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
 	// Update state
+	d.mu.Lock()
 	d.state.EntriesGenerated++
 	d.state.LastEntryAt = time.Now()
 	d.state.LastPersona = personaName
+	d.state.LastEntryID = result.Entry.ID
+	generated := d.state.EntriesGenerated
+	err = SaveState(d.state)
+	d.mu.Unlock()
 
-	if err := SaveState(d.state); err != nil {
-		d.logger.Printf("Warning: failed to save state: %v", err)
+	if err != nil {
+		d.logger.Warn("Failed to save state", "error", err)
 	}
 
-	d.logger.Printf("Entry #%d created (ID: %d, persona: %s)",
-		d.state.EntriesGenerated, result.Entry.ID, personaName)
+	d.logger.Info("Entry created",
+		"entry_id", result.Entry.ID,
+		"persona", personaName,
+		"entries_generated", generated)
+
+	// Deliver in the background so a slow endpoint doesn't hold up the schedule;
+	// problems are logged, never counted as a failed generation
+	if url := d.cfg.Daemon.WebhookURL; url != "" {
+		payload := NewWebhookPayload(result.Entry)
+		d.webhooks.Add(1)
+		go func() {
+			defer d.webhooks.Done()
+			if err := SendWebhook(ctx, url, payload); err != nil {
+				d.logger.Warn("Failed to deliver webhook", "entry_id", payload.EntryID, "error", err)
+			}
+		}()
+	}
 
 	return nil
 }
 
+// selectPersonas returns the personas that should write on this trigger
+func (d *Daemon) selectPersonas() []string {
+	if d.cfg.Daemon.Mode == ModeAll {
+		if len(d.cfg.Daemon.Personas) == 0 {
+			return []string{d.cfg.DefaultPersona}
+		}
+		return d.cfg.Daemon.Personas
+	}
+	return []string{d.selectPersona()}
+}
+
+// selection returns the persona selection strategy, defaulting to random
+func (d *Daemon) selection() string {
+	if d.cfg.Daemon.Selection == "" {
+		return SelectionRandom
+	}
+	return d.cfg.Daemon.Selection
+}
+
 // selectPersona chooses a persona for the next entry
 func (d *Daemon) selectPersona() string {
 	personas := d.cfg.Daemon.Personas
@@ -158,6 +518,19 @@ func (d *Daemon) selectPersona() string {
 		return d.cfg.DefaultPersona
 	}
 
+	// Round robin advances past the last pick, which is saved with the state
+	// so the rotation carries across restarts
+	if d.selection() == SelectionRoundRobin {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		next := (d.state.LastIndex + 1) % len(personas)
+		if next < 0 {
+			next = 0
+		}
+		d.state.LastIndex = next
+		return personas[next]
+	}
+
 	// Random selection from configured personas
 	if len(personas) == 1 {
 		return personas[0]
@@ -182,17 +555,23 @@ func (d *Daemon) Wait() {
 	<-d.done
 }
 
-// cleanup removes PID and state files on shutdown
+// snapshotState returns a copy of the current state for concurrent readers
+func (d *Daemon) snapshotState() State {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return *d.state
+}
+
+// cleanup stops the metrics server and removes the PID file on shutdown
+// The state file is kept so the next start can tell whether a trigger was missed
 func (d *Daemon) cleanup() {
-	d.logger.Println("Cleaning up...")
+	d.logger.Info("Cleaning up")
 
-	if err := RemovePID(); err != nil {
-		d.logger.Printf("Warning: failed to remove PID file: %v", err)
-	}
+	d.stopMetricsServer()
 
-	if err := RemoveState(); err != nil {
-		d.logger.Printf("Warning: failed to remove state file: %v", err)
+	if err := RemovePID(); err != nil {
+		d.logger.Warn("Failed to remove PID file", "error", err)
 	}
 
-	d.logger.Println("Daemon stopped")
+	d.logger.Info("Daemon stopped")
 }
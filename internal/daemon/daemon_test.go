@@ -1,33 +1,38 @@
 package daemon
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/cldixon/jernel/internal/config"
+	"github.com/cldixon/jernel/internal/store"
 )
 
-// setupTestEnv creates a temporary home directory for testing
+// setupTestEnv creates a temporary config directory for testing
 func setupTestEnv(t *testing.T) func() {
 	t.Helper()
 
-	tmpHome, err := os.MkdirTemp("", "jernel-daemon-test-*")
+	configDir, err := os.MkdirTemp("", "jernel-daemon-test-*")
 	if err != nil {
 		t.Fatalf("failed to create temp dir: %v", err)
 	}
-
-	origHome := os.Getenv("HOME")
-	os.Setenv("HOME", tmpHome)
-
-	// Create config directory
-	configDir := filepath.Join(tmpHome, ".config", "jernel")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		t.Fatalf("failed to create config dir: %v", err)
-	}
+	t.Setenv(config.DirEnv, configDir)
 
 	return func() {
-		os.Setenv("HOME", origHome)
-		os.RemoveAll(tmpHome)
+		os.RemoveAll(configDir)
 	}
 }
 
@@ -41,6 +46,9 @@ func TestPeriodToDuration(t *testing.T) {
 		{"hour", time.Hour, false},
 		{"day", 24 * time.Hour, false},
 		{"week", 7 * 24 * time.Hour, false},
+		{"minute", time.Minute, false},
+		{"month", 30 * 24 * time.Hour, false},
+		{"fortnight", 0, true},
 		{"invalid", 0, true},
 		{"", 0, true},
 	}
@@ -65,29 +73,35 @@ func TestPeriodToDuration(t *testing.T) {
 	}
 }
 
-// TestCalculateNextIntervalRange verifies intervals are within expected bounds.
+// TestCalculateNextIntervalRange verifies intervals stay within the window
+// set by jitter, and that jitter 0 always returns the average.
 func TestCalculateNextIntervalRange(t *testing.T) {
 	tests := []struct {
 		name        string
 		rate        int
 		period      string
+		jitter      float64
 		expectedAvg time.Duration
 	}{
-		{"3 per day", 3, "day", 8 * time.Hour},
-		{"1 per hour", 1, "hour", time.Hour},
-		{"6 per day", 6, "day", 4 * time.Hour},
-		{"14 per week", 14, "week", 12 * time.Hour},
+		{"3 per day", 3, "day", 1, 8 * time.Hour},
+		{"1 per hour", 1, "hour", 1, time.Hour},
+		{"6 per day", 6, "day", 1, 4 * time.Hour},
+		{"14 per week", 14, "week", 1, 12 * time.Hour},
+		{"3 per day, half jitter", 3, "day", 0.5, 8 * time.Hour},
+		{"1 per hour, slight jitter", 1, "hour", 0.1, time.Hour},
+		{"3 per day, no jitter", 3, "day", 0, 8 * time.Hour},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			// Calculate min/max bounds (0.5x to 1.5x average)
-			minBound := tc.expectedAvg / 2
-			maxBound := tc.expectedAvg + tc.expectedAvg/2
+			// Window is ±jitter/2 around the average (0.5x to 1.5x at jitter 1)
+			spread := time.Duration(float64(tc.expectedAvg) * tc.jitter / 2)
+			minBound := tc.expectedAvg - spread
+			maxBound := tc.expectedAvg + spread
 
 			// Run multiple iterations to test randomness stays in bounds
 			for i := 0; i < 100; i++ {
-				interval, err := CalculateNextInterval(tc.rate, tc.period)
+				interval, err := CalculateNextInterval(tc.rate, tc.period, tc.jitter, 0)
 				if err != nil {
 					t.Fatalf("CalculateNextInterval failed: %v", err)
 				}
@@ -98,6 +112,9 @@ func TestCalculateNextIntervalRange(t *testing.T) {
 				if interval > maxBound {
 					t.Errorf("interval %v above maximum %v", interval, maxBound)
 				}
+				if tc.jitter == 0 && interval != tc.expectedAvg {
+					t.Fatalf("expected exact interval %v with no jitter, got %v", tc.expectedAvg, interval)
+				}
 			}
 		})
 	}
@@ -109,7 +126,7 @@ func TestCalculateNextIntervalRandomness(t *testing.T) {
 	seen := make(map[time.Duration]bool)
 
 	for i := 0; i < 50; i++ {
-		interval, err := CalculateNextInterval(3, "day")
+		interval, err := CalculateNextInterval(3, "day", 1, 0)
 		if err != nil {
 			t.Fatalf("CalculateNextInterval failed: %v", err)
 		}
@@ -128,15 +145,18 @@ func TestCalculateNextIntervalInvalidInputs(t *testing.T) {
 		name   string
 		rate   int
 		period string
+		jitter float64
 	}{
-		{"zero rate", 0, "day"},
-		{"negative rate", -1, "day"},
-		{"invalid period", 3, "invalid"},
+		{"zero rate", 0, "day", 1},
+		{"negative rate", -1, "day", 1},
+		{"invalid period", 3, "invalid", 1},
+		{"negative jitter", 3, "day", -0.5},
+		{"jitter above one", 3, "day", 1.5},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			_, err := CalculateNextInterval(tc.rate, tc.period)
+			_, err := CalculateNextInterval(tc.rate, tc.period, tc.jitter, 0)
 			if err == nil {
 				t.Error("expected error, got nil")
 			}
@@ -144,11 +164,67 @@ func TestCalculateNextIntervalInvalidInputs(t *testing.T) {
 	}
 }
 
+// TestCalculateNextIntervalMinInterval verifies short intervals are raised to
+// the floor while longer ones are left alone.
+func TestCalculateNextIntervalMinInterval(t *testing.T) {
+	// 24/day with full jitter ranges from 30m to 90m
+	floor := 75 * time.Minute
+	sawClamped := false
+	for i := 0; i < 200; i++ {
+		interval, err := CalculateNextInterval(24, "day", 1, floor)
+		if err != nil {
+			t.Fatalf("CalculateNextInterval failed: %v", err)
+		}
+		if interval < floor {
+			t.Fatalf("interval %s below the %s floor", interval, floor)
+		}
+		if interval > 90*time.Minute {
+			t.Fatalf("interval %s above the jitter window", interval)
+		}
+		if interval == floor {
+			sawClamped = true
+		}
+	}
+	if !sawClamped {
+		t.Error("expected some intervals to be clamped to the floor")
+	}
+
+	// A floor above the whole window always wins
+	interval, err := CalculateNextInterval(24, "day", 0, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("CalculateNextInterval failed: %v", err)
+	}
+	if interval != 2*time.Hour {
+		t.Errorf("expected the 2h floor, got %s", interval)
+	}
+
+	// No floor leaves the exact interval untouched
+	interval, _ = CalculateNextInterval(24, "day", 0, 0)
+	if interval != time.Hour {
+		t.Errorf("expected 1h without a floor, got %s", interval)
+	}
+}
+
+// TestParseMinInterval verifies min_interval parsing, with empty meaning no floor.
+func TestParseMinInterval(t *testing.T) {
+	if d, err := ParseMinInterval(""); err != nil || d != 0 {
+		t.Errorf("expected no floor for empty value, got %s (err %v)", d, err)
+	}
+	if d, err := ParseMinInterval("45m"); err != nil || d != 45*time.Minute {
+		t.Errorf("expected 45m, got %s (err %v)", d, err)
+	}
+	for _, value := range []string{"-1m", "soon"} {
+		if _, err := ParseMinInterval(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
+
 // TestCalculateNextTrigger verifies trigger time is in the future.
 func TestCalculateNextTrigger(t *testing.T) {
 	now := time.Now()
 
-	trigger, err := CalculateNextTrigger(3, "day")
+	trigger, err := CalculateNextTrigger(3, "day", 1, 0)
 	if err != nil {
 		t.Fatalf("CalculateNextTrigger failed: %v", err)
 	}
@@ -256,6 +332,222 @@ func TestStateRemove(t *testing.T) {
 	}
 }
 
+// syncBuffer is a bytes.Buffer safe to read while the daemon loop logs to it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestResumeTrigger verifies a trigger missed by the previous run fires once
+// right away, while a pending or absent one leaves the fresh schedule alone.
+func TestResumeTrigger(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	fresh := now.Add(8 * time.Hour)
+
+	tests := []struct {
+		name       string
+		prev       *State
+		wantNext   time.Time
+		wantMissed time.Time
+	}{
+		{"no previous run", nil, fresh, time.Time{}},
+		{"no trigger recorded", &State{}, fresh, time.Time{}},
+		{"trigger still pending", &State{NextTrigger: now.Add(time.Hour)}, fresh, time.Time{}},
+		{"trigger in the past", &State{NextTrigger: now.Add(-time.Hour)}, now, now.Add(-time.Hour)},
+		{"long sleep catches up once", &State{NextTrigger: now.AddDate(0, 0, -30)}, now, now.AddDate(0, 0, -30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, missed := resumeTrigger(tt.prev, now, fresh)
+			if !next.Equal(tt.wantNext) {
+				t.Errorf("expected next %v, got %v", tt.wantNext, next)
+			}
+			if !missed.Equal(tt.wantMissed) {
+				t.Errorf("expected missed %v, got %v", tt.wantMissed, missed)
+			}
+		})
+	}
+}
+
+// TestStartCatchesUpMissedTrigger verifies a daemon started after its
+// previous trigger passed generates right away, and that shutdown keeps the
+// state file for the next start.
+func TestStartCatchesUpMissedTrigger(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	missed := time.Now().Add(-2 * time.Hour)
+	if err := SaveState(&State{PID: 1, NextTrigger: missed}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Daemon.Rate = 1
+	cfg.Daemon.RatePeriod = "week"
+
+	var buf syncBuffer
+	d := New(cfg)
+	d.logger = newLogger(&buf, LogFormatJSON)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := d.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if time.Until(d.snapshotState().NextTrigger) > time.Minute {
+		t.Error("expected the first trigger to fire right away")
+	}
+
+	// The catch-up generation fails fast (no persona files), then the loop
+	// waits a week for the next trigger until cancelled
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(buf.String(), "Failed to generate entry") {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the catch-up generation")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	d.Wait()
+
+	if !strings.Contains(buf.String(), "catching up") {
+		t.Errorf("expected a catch-up log line, got %q", buf.String())
+	}
+
+	state, err := LoadState()
+	if err != nil || state == nil {
+		t.Fatalf("expected state file to survive shutdown, got %v (err %v)", state, err)
+	}
+	if !state.NextTrigger.After(time.Now()) {
+		t.Error("expected a future trigger saved after the catch-up")
+	}
+}
+
+// TestStartCatchUpDisabled verifies catch_up: false ignores a missed trigger.
+func TestStartCatchUpDisabled(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	if err := SaveState(&State{PID: 1, NextTrigger: time.Now().Add(-2 * time.Hour)}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Daemon.Rate = 1
+	cfg.Daemon.RatePeriod = "week"
+	cfg.Daemon.CatchUp = false
+
+	d := New(cfg)
+	d.logger = newLogger(io.Discard, LogFormatJSON)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := d.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	next := d.snapshotState().NextTrigger
+	cancel()
+	d.Wait()
+
+	if time.Until(next) < time.Hour {
+		t.Errorf("expected a fresh trigger well in the future, got %v", next)
+	}
+}
+
+// TestReloadReschedules verifies a reload swaps in the new schedule and that
+// a config that fails to load leaves the current one in place.
+func TestReloadReschedules(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	cfg := config.DefaultConfig()
+	cfg.Daemon.Rate = 1
+	cfg.Daemon.RatePeriod = "week"
+	cfg.Daemon.CatchUp = false
+
+	var buf syncBuffer
+	d := New(cfg)
+	d.logger = newLogger(&buf, LogFormatJSON)
+	d.LoadConfig = func() (*config.Config, error) {
+		return nil, errors.New("bad yaml")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		d.Wait()
+	}()
+	if err := d.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	weekly := d.snapshotState().NextTrigger
+
+	waitForLog := func(text string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !strings.Contains(buf.String(), text) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %q in %q", text, buf.String())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	d.Reload()
+	waitForLog("Failed to reload config")
+	if !d.snapshotState().NextTrigger.Equal(weekly) {
+		t.Error("expected a failed reload to keep the current trigger")
+	}
+
+	d.LoadConfig = func() (*config.Config, error) {
+		hourly := config.DefaultConfig()
+		hourly.Daemon.Rate = 1
+		hourly.Daemon.RatePeriod = "hour"
+		return hourly, nil
+	}
+	d.Reload()
+	waitForLog("Config reloaded")
+	if time.Until(d.snapshotState().NextTrigger) > 2*time.Hour {
+		t.Errorf("expected an hourly trigger after the reload, got %v", d.snapshotState().NextTrigger)
+	}
+}
+
+// TestRunOnceRefusesWhileRunning verifies run-once won't write alongside a
+// running daemon and leaves no PID file of its own.
+func TestRunOnceRefusesWhileRunning(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	cfg := config.DefaultConfig()
+	if err := WritePID(); err != nil {
+		t.Fatalf("WritePID failed: %v", err)
+	}
+	err := New(cfg).RunOnce(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Errorf("expected an already-running error, got %v", err)
+	}
+	RemovePID()
+
+	cfg.Daemon.Mode = "everyone"
+	if err := New(cfg).RunOnce(context.Background()); err == nil {
+		t.Error("expected an invalid mode to be rejected")
+	}
+	if _, err := ReadPID(); err == nil {
+		t.Error("run-once should not write a PID file")
+	}
+}
+
 // TestPIDFileOperations verifies PID file write/read/remove cycle.
 func TestPIDFileOperations(t *testing.T) {
 	cleanup := setupTestEnv(t)
@@ -353,3 +645,462 @@ func TestIsRunningCleansStaleFile(t *testing.T) {
 		t.Error("stale PID file should have been removed")
 	}
 }
+
+// TestValidateMode verifies daemon mode validation.
+func TestValidateMode(t *testing.T) {
+	for _, mode := range []string{"", "single", "all"} {
+		if err := ValidateMode(mode); err != nil {
+			t.Errorf("expected mode %q to be valid: %v", mode, err)
+		}
+	}
+	if err := ValidateMode("some"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
+// TestValidateSelection verifies the supported persona selection strategies.
+func TestValidateSelection(t *testing.T) {
+	for _, selection := range []string{"", "random", "round_robin"} {
+		if err := ValidateSelection(selection); err != nil {
+			t.Errorf("expected selection %q to be valid: %v", selection, err)
+		}
+	}
+	if err := ValidateSelection("fair"); err == nil {
+		t.Error("expected error for invalid selection")
+	}
+}
+
+// TestValidateWebhookURL verifies webhooks accept http and https URLs or empty.
+func TestValidateWebhookURL(t *testing.T) {
+	for _, u := range []string{"", "https://ntfy.sh/my-jernel", "http://localhost:8080/hook"} {
+		if err := ValidateWebhookURL(u); err != nil {
+			t.Errorf("expected webhook URL %q to be valid: %v", u, err)
+		}
+	}
+	for _, u := range []string{"ftp://example.com/hook", "ntfy.sh/my-jernel", "https://"} {
+		if err := ValidateWebhookURL(u); err == nil {
+			t.Errorf("expected error for webhook URL %q", u)
+		}
+	}
+}
+
+// TestLoggerJSON verifies json output is one parseable object per line with
+// level, message, and fields.
+func TestLoggerJSON(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger(&buf, LogFormatJSON)
+	l.Info("Entry created", "entry_id", int64(42), "persona", "default")
+	l.Error("Failed to generate entry", "persona", "dramatic", "error", errors.New("boom"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %q", len(lines), buf.String())
+	}
+
+	var first map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("log line is not JSON: %v", err)
+	}
+	if first["level"] != "INFO" || first["msg"] != "Entry created" {
+		t.Errorf("unexpected level/msg: %v", first)
+	}
+	if first["entry_id"] != float64(42) || first["persona"] != "default" {
+		t.Errorf("missing fields: %v", first)
+	}
+	if _, ok := first["time"]; !ok {
+		t.Error("expected a timestamp")
+	}
+
+	var second map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatalf("log line is not JSON: %v", err)
+	}
+	if second["level"] != "ERROR" || second["error"] != "boom" {
+		t.Errorf("unexpected error line: %v", second)
+	}
+}
+
+// TestLoggerText verifies text output keeps the prefix and appends fields.
+func TestLoggerText(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger(&buf, LogFormatText)
+	l.Warn("Failed to save state", "error", errors.New("disk full"), "persona", "default")
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "[jernel-daemon] ") {
+		t.Errorf("expected prefix, got %q", out)
+	}
+	if !strings.Contains(out, `Warning: Failed to save state error="disk full" persona=default`) {
+		t.Errorf("unexpected text line: %q", out)
+	}
+}
+
+// TestValidateLogFormat verifies log format validation.
+func TestValidateLogFormat(t *testing.T) {
+	for _, format := range []string{"", "text", "json"} {
+		if err := ValidateLogFormat(format); err != nil {
+			t.Errorf("expected format %q to be valid: %v", format, err)
+		}
+	}
+	if err := ValidateLogFormat("xml"); err == nil {
+		t.Error("expected error for invalid format")
+	}
+}
+
+// TestSelectPersonasByMode verifies single mode picks one persona while
+// all mode returns every configured persona.
+func TestSelectPersonasByMode(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Daemon.Personas = []string{"alice", "bob", "carol"}
+
+	d := New(cfg)
+
+	cfg.Daemon.Mode = ModeSingle
+	selected := d.selectPersonas()
+	if len(selected) != 1 {
+		t.Fatalf("single mode should select 1 persona, got %d", len(selected))
+	}
+
+	cfg.Daemon.Mode = ModeAll
+	selected = d.selectPersonas()
+	if !reflect.DeepEqual(selected, cfg.Daemon.Personas) {
+		t.Errorf("all mode should select every persona, got %v", selected)
+	}
+
+	// All mode with no configured personas falls back to the default
+	cfg.Daemon.Personas = nil
+	selected = d.selectPersonas()
+	if !reflect.DeepEqual(selected, []string{cfg.DefaultPersona}) {
+		t.Errorf("expected default persona, got %v", selected)
+	}
+}
+
+// TestSelectPersonaRoundRobin verifies round robin gives every persona exactly
+// one turn per cycle, in order, and shrinking the list keeps picks in range.
+func TestSelectPersonaRoundRobin(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Daemon.Personas = []string{"alice", "bob", "carol"}
+	cfg.Daemon.Selection = SelectionRoundRobin
+
+	d := New(cfg)
+	d.state = &State{LastIndex: -1}
+
+	counts := map[string]int{}
+	var order []string
+	for range 3 * len(cfg.Daemon.Personas) {
+		name := d.selectPersona()
+		counts[name]++
+		order = append(order, name)
+	}
+	for _, name := range cfg.Daemon.Personas {
+		if counts[name] != 3 {
+			t.Errorf("expected %s to write 3 times over 3 cycles, got %d (order %v)", name, counts[name], order)
+		}
+	}
+	if !reflect.DeepEqual(order[:3], cfg.Daemon.Personas) {
+		t.Errorf("expected personas in configured order, got %v", order[:3])
+	}
+	if d.state.LastIndex != 2 {
+		t.Errorf("expected last_index 2 after full cycles, got %d", d.state.LastIndex)
+	}
+
+	// A reload that drops personas wraps instead of running off the end
+	d.state.LastIndex = 2
+	cfg.Daemon.Personas = []string{"alice", "bob"}
+	if got := d.selectPersona(); got != "alice" && got != "bob" {
+		t.Errorf("expected a remaining persona, got %s", got)
+	}
+}
+
+// TestStartKeepsRoundRobinPosition verifies a restart continues the rotation
+// from the persisted last_index and keeps the undo target.
+func TestStartKeepsRoundRobinPosition(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	if err := SaveState(&State{LastIndex: 1, LastEntryID: 42}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Daemon.Rate = 1
+	cfg.Daemon.RatePeriod = "week"
+	cfg.Daemon.CatchUp = false
+	cfg.Daemon.Personas = []string{"alice", "bob", "carol"}
+	cfg.Daemon.Selection = SelectionRoundRobin
+
+	var buf syncBuffer
+	d := New(cfg)
+	d.logger = newLogger(&buf, LogFormatJSON)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		d.Wait()
+	}()
+	if err := d.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if got := d.selectPersona(); got != "carol" {
+		t.Errorf("expected the rotation to resume with carol, got %s", got)
+	}
+	if got := d.snapshotState().LastEntryID; got != 42 {
+		t.Errorf("expected the undo target to survive a restart, got %d", got)
+	}
+}
+
+// TestStartMetricsPortBusyKeepsState verifies a metrics listener that can't
+// bind fails the start without touching the previous run's state file.
+func TestStartMetricsPortBusyKeepsState(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer busy.Close()
+
+	if err := SaveState(&State{LastIndex: 1, LastEntryID: 42}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Daemon.Rate = 1
+	cfg.Daemon.RatePeriod = "week"
+	cfg.Daemon.MetricsAddr = busy.Addr().String()
+
+	var buf syncBuffer
+	d := New(cfg)
+	d.logger = newLogger(&buf, LogFormatJSON)
+	if err := d.Start(context.Background()); err == nil {
+		t.Fatal("expected Start to fail on a busy metrics port")
+	}
+
+	state, err := LoadState()
+	if err != nil || state == nil {
+		t.Fatalf("expected the previous state to survive, got %v (%v)", state, err)
+	}
+	if state.LastIndex != 1 || state.LastEntryID != 42 {
+		t.Errorf("expected last_index 1 and last_entry_id 42, got %d and %d", state.LastIndex, state.LastEntryID)
+	}
+	if running, _, _ := IsRunning(); running {
+		t.Error("expected the PID file to be removed after a failed start")
+	}
+}
+
+// TestGenerateEntryBeatsPerPersona verifies the heartbeat advances while a
+// trigger works through its personas, even when they fail.
+func TestGenerateEntryBeatsPerPersona(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	cfg := config.DefaultConfig()
+	cfg.Daemon.Mode = ModeAll
+	cfg.Daemon.Personas = []string{"ghost", "phantom"}
+
+	var buf syncBuffer
+	d := New(cfg)
+	d.logger = newLogger(&buf, LogFormatJSON)
+	old := time.Now().Add(-time.Hour)
+	d.state = &State{Heartbeat: old, LastIndex: -1}
+
+	if err := d.generateEntry(context.Background()); err == nil {
+		t.Fatal("expected missing personas to fail")
+	}
+	if !d.state.Heartbeat.After(old) {
+		t.Error("expected the heartbeat to advance during the trigger")
+	}
+	state, err := LoadState()
+	if err != nil || state == nil || !state.Heartbeat.After(old) {
+		t.Errorf("expected the saved heartbeat to advance, got %+v (%v)", state, err)
+	}
+}
+
+// TestMetricsEndpoint verifies the metrics handler reports daemon state in
+// Prometheus text format.
+func TestMetricsEndpoint(t *testing.T) {
+	d := New(config.DefaultConfig())
+	d.state = &State{
+		StartedAt:        time.Unix(1700000000, 0),
+		NextTrigger:      time.Unix(1700003600, 0),
+		EntriesGenerated: 7,
+		LastEntryAt:      time.Unix(1700001800, 0),
+		Errors:           2,
+	}
+
+	rec := httptest.NewRecorder()
+	d.metricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if rec.Code != 200 {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("expected text/plain content type, got %q", ct)
+	}
+
+	body := rec.Body.String()
+	expected := []string{
+		"# TYPE jernel_daemon_entries_generated_total counter",
+		"jernel_daemon_entries_generated_total 7\n",
+		"jernel_daemon_errors_total 2\n",
+		"jernel_daemon_start_timestamp_seconds 1700000000\n",
+		"jernel_daemon_last_entry_timestamp_seconds 1700001800\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("expected metrics to contain %q\n%s", line, body)
+		}
+	}
+}
+
+// TestStateStale verifies heartbeat and schedule based staleness checks.
+func TestStateStale(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		state State
+		stale bool
+	}{
+		{"fresh", State{Heartbeat: now.Add(-30 * time.Second), NextTrigger: now.Add(time.Hour)}, false},
+		{"no heartbeat", State{NextTrigger: now.Add(time.Hour)}, true},
+		{"old heartbeat", State{Heartbeat: now.Add(-StaleAfter - time.Minute), NextTrigger: now.Add(time.Hour)}, true},
+		{"generating", State{Heartbeat: now.Add(-time.Minute), NextTrigger: now.Add(-time.Minute)}, false},
+		{"overdue", State{Heartbeat: now.Add(-time.Minute), NextTrigger: now.Add(-StaleAfter - time.Minute)}, true},
+	}
+
+	for _, tt := range tests {
+		stale, reason := tt.state.Stale(now)
+		if stale != tt.stale {
+			t.Errorf("%s: expected stale=%v, got %v (%s)", tt.name, tt.stale, stale, reason)
+		}
+		if stale && reason == "" {
+			t.Errorf("%s: expected a reason for stale state", tt.name)
+		}
+	}
+}
+
+// TestPingNotRunning verifies ping fails when no daemon is running.
+func TestPingNotRunning(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	if _, err := Ping(); err == nil {
+		t.Error("expected ping to fail without a running daemon")
+	}
+}
+
+// TestNewService verifies a service definition is generated per platform
+// and unsupported platforms are refused.
+func TestNewService(t *testing.T) {
+	exe := "/opt/my tools/jernel"
+
+	linux, err := NewService("linux", exe, "/home/me", "/home/me/.config/jernel", "")
+	if err != nil {
+		t.Fatalf("NewService(linux) failed: %v", err)
+	}
+	if linux.Path != "/home/me/.config/systemd/user/jernel.service" {
+		t.Errorf("unexpected unit path: %s", linux.Path)
+	}
+	if !strings.Contains(linux.Content, `ExecStart="/opt/my tools/jernel" daemon start`) {
+		t.Errorf("expected quoted ExecStart in unit:\n%s", linux.Content)
+	}
+
+	darwin, err := NewService("darwin", "/usr/local/bin/jernel", "/Users/me", "/Users/me/.config/jernel", "")
+	if err != nil {
+		t.Fatalf("NewService(darwin) failed: %v", err)
+	}
+	if darwin.Path != "/Users/me/Library/LaunchAgents/"+ServiceLabel+".plist" {
+		t.Errorf("unexpected plist path: %s", darwin.Path)
+	}
+	if !strings.Contains(darwin.Content, "<string>/usr/local/bin/jernel</string>") {
+		t.Errorf("expected executable in plist:\n%s", darwin.Content)
+	}
+
+	custom, err := NewService("linux", "/usr/bin/jernel", "/home/me", "/srv/jernel", "work")
+	if err != nil {
+		t.Fatalf("NewService(linux, custom dir) failed: %v", err)
+	}
+	if !strings.Contains(custom.Content, "ExecStart=/usr/bin/jernel --config-dir /srv/jernel --profile work daemon start") {
+		t.Errorf("expected --config-dir and --profile in ExecStart:\n%s", custom.Content)
+	}
+	if strings.Contains(linux.Content, "--config-dir") {
+		t.Errorf("expected no --config-dir for the default directory:\n%s", linux.Content)
+	}
+
+	if _, err := NewService("windows", exe, "C:\\Users\\me", "", ""); err == nil {
+		t.Error("expected error for unsupported platform")
+	}
+}
+
+// TestServiceInstallUninstall verifies install refuses to overwrite without
+// force and uninstall removes the file.
+func TestServiceInstallUninstall(t *testing.T) {
+	dir := t.TempDir()
+	svc := &Service{Path: filepath.Join(dir, "units", "jernel.service"), Content: "unit"}
+
+	if err := svc.Install(false); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if err := svc.Install(false); err == nil {
+		t.Error("expected second install to fail without force")
+	}
+	if err := svc.Install(true); err != nil {
+		t.Errorf("expected forced install to succeed: %v", err)
+	}
+
+	if err := svc.Uninstall(); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(svc.Path); !os.IsNotExist(err) {
+		t.Error("expected service file to be removed")
+	}
+	if err := svc.Uninstall(); err == nil {
+		t.Error("expected uninstall to fail when nothing is installed")
+	}
+}
+
+// TestSendWebhookRetriesOnce verifies a failed delivery is retried once with
+// the same JSON payload, and that two failures are reported.
+func TestSendWebhookRetriesOnce(t *testing.T) {
+	webhookRetryDelay = 0
+	t.Cleanup(func() { webhookRetryDelay = 2 * time.Second })
+
+	var mu sync.Mutex
+	var bodies []WebhookPayload
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		bodies = append(bodies, payload)
+		code := status
+		status = http.StatusOK
+		mu.Unlock()
+		w.WriteHeader(code)
+	}))
+	defer server.Close()
+
+	e := &store.Entry{ID: 7, Persona: "poet", CreatedAt: time.Now(), Content: strings.Repeat("word ", 100)}
+	if err := SendWebhook(context.Background(), server.URL, NewWebhookPayload(e)); err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(bodies))
+	}
+	if bodies[1].EntryID != 7 || bodies[1].Persona != "poet" {
+		t.Errorf("unexpected payload: %+v", bodies[1])
+	}
+	if n := len([]rune(bodies[1].Preview)); n > WebhookPreviewLen+1 {
+		t.Errorf("expected preview of at most %d characters, got %d", WebhookPreviewLen+1, n)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := SendWebhook(context.Background(), failing.URL, NewWebhookPayload(e)); err == nil {
+		t.Error("expected an error after two failed attempts")
+	}
+}
@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+)
+
+// HeartbeatInterval is how often the daemon refreshes its heartbeat while idle
+const HeartbeatInterval = time.Minute
+
+// StaleAfter is how old a heartbeat can get before the daemon is considered
+// unhealthy; it allows for a few missed beats plus a slow generation
+const StaleAfter = 5 * time.Minute
+
+// Stale reports whether a running daemon has stopped making progress,
+// along with a human-readable reason
+func (s *State) Stale(now time.Time) (bool, string) {
+	if s.Heartbeat.IsZero() {
+		return true, "no heartbeat recorded"
+	}
+
+	if age := now.Sub(s.Heartbeat); age > StaleAfter {
+		return true, fmt.Sprintf("last heartbeat %s ago", age.Round(time.Second))
+	}
+
+	// An entry that is long overdue means the loop is stuck mid-generation
+	if overdue := now.Sub(s.NextTrigger); overdue > StaleAfter {
+		return true, fmt.Sprintf("scheduled entry overdue by %s", overdue.Round(time.Second))
+	}
+
+	return false, ""
+}
+
+// Ping checks that the daemon is running and its heartbeat is fresh
+func Ping() (*State, error) {
+	running, pid, err := IsRunning()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check daemon status: %w", err)
+	}
+	if !running {
+		return nil, fmt.Errorf("daemon is not running")
+	}
+
+	state, err := LoadState()
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, fmt.Errorf("daemon (PID %d) has no state file", pid)
+	}
+
+	if stale, reason := state.Stale(time.Now()); stale {
+		return state, fmt.Errorf("daemon (PID %d) is stale: %s", pid, reason)
+	}
+
+	return state, nil
+}
@@ -0,0 +1,90 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/cldixon/jernel/internal/timefmt"
+)
+
+// Log output formats
+const (
+	LogFormatText = "text" // human-readable lines with a [jernel-daemon] prefix
+	LogFormatJSON = "json" // one JSON object per line for log pipelines
+)
+
+// ValidateLogFormat checks that a log format is supported (empty means text)
+func ValidateLogFormat(format string) error {
+	switch format {
+	case "", LogFormatText, LogFormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid daemon log format: %s (must be text or json)", format)
+	}
+}
+
+// logger writes daemon log lines with key/value fields in either format
+type logger struct {
+	json *slog.Logger // set for json output
+	text *log.Logger  // set for text output
+}
+
+// newLogger creates a logger writing to w in the given format
+func newLogger(w io.Writer, format string) *logger {
+	if format == LogFormatJSON {
+		return &logger{json: slog.New(slog.NewJSONHandler(w, nil))}
+	}
+	return &logger{text: log.New(w, "[jernel-daemon] ", log.LstdFlags)}
+}
+
+// Info logs a routine event
+func (l *logger) Info(msg string, args ...any) { l.log(slog.LevelInfo, msg, args...) }
+
+// Warn logs a problem the daemon can carry on from
+func (l *logger) Warn(msg string, args ...any) { l.log(slog.LevelWarn, msg, args...) }
+
+// Error logs a failed operation
+func (l *logger) Error(msg string, args ...any) { l.log(slog.LevelError, msg, args...) }
+
+// log writes msg with alternating key/value args
+func (l *logger) log(level slog.Level, msg string, args ...any) {
+	if l.json != nil {
+		l.json.Log(context.Background(), level, msg, args...)
+		return
+	}
+
+	var b strings.Builder
+	switch level {
+	case slog.LevelWarn:
+		b.WriteString("Warning: ")
+	case slog.LevelError:
+		b.WriteString("Error: ")
+	}
+	b.WriteString(msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&b, " %v=%s", args[i], textValue(args[i+1]))
+	}
+	l.text.Print(b.String())
+}
+
+// textValue formats a field value for text output, quoting values with spaces
+func textValue(v any) string {
+	var s string
+	switch v := v.(type) {
+	case time.Time:
+		s = timefmt.Format(v, time.RFC1123)
+	case error:
+		s = v.Error()
+	default:
+		s = fmt.Sprint(v)
+	}
+	if strings.ContainsAny(s, " \t\n\"") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
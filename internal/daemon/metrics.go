@@ -0,0 +1,93 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WriteMetrics writes daemon state in the Prometheus text exposition format
+func WriteMetrics(w io.Writer, state State) error {
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value float64
+	}{
+		{"jernel_daemon_entries_generated_total", "counter", "Journal entries generated since the daemon started.", float64(state.EntriesGenerated)},
+		{"jernel_daemon_errors_total", "counter", "Failed entry generations since the daemon started.", float64(state.Errors)},
+		{"jernel_daemon_start_timestamp_seconds", "gauge", "Unix time the daemon started.", unixSeconds(state.StartedAt)},
+		{"jernel_daemon_last_entry_timestamp_seconds", "gauge", "Unix time of the last generated entry (0 if none).", unixSeconds(state.LastEntryAt)},
+		{"jernel_daemon_next_trigger_timestamp_seconds", "gauge", "Unix time of the next scheduled entry.", unixSeconds(state.NextTrigger)},
+	}
+
+	for _, m := range metrics {
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n",
+			m.name, m.help, m.name, m.kind, m.name, strconv.FormatFloat(m.value, 'f', -1, 64))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unixSeconds(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.Unix())
+}
+
+// metricsHandler serves the current daemon state at /metrics
+func (d *Daemon) metricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := WriteMetrics(w, d.snapshotState()); err != nil {
+			d.logger.Warn("Failed to write metrics", "error", err)
+		}
+	})
+	return mux
+}
+
+// startMetricsServer listens on addr and serves metrics until stopMetricsServer
+// The listener is bound up front so a bad address fails Start immediately
+func (d *Daemon) startMetricsServer(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	d.server = &http.Server{
+		Handler:           d.metricsHandler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		if err := d.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			d.logger.Error("Metrics server failed", "error", err)
+		}
+	}()
+
+	d.logger.Info("Serving metrics", "url", fmt.Sprintf("http://%s/metrics", listener.Addr()))
+	return nil
+}
+
+// stopMetricsServer shuts the metrics server down if it was started
+func (d *Daemon) stopMetricsServer() {
+	if d.server == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := d.server.Shutdown(ctx); err != nil {
+		d.logger.Warn("Failed to stop metrics server", "error", err)
+	}
+}
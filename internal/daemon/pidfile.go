@@ -92,6 +92,16 @@ func IsRunning() (bool, int, error) {
 
 // StopRunning sends SIGTERM to the running daemon
 func StopRunning() error {
+	return signalRunning(syscall.SIGTERM)
+}
+
+// ReloadRunning sends SIGHUP to the running daemon, which re-reads its config
+func ReloadRunning() error {
+	return signalRunning(syscall.SIGHUP)
+}
+
+// signalRunning sends sig to the process in the PID file
+func signalRunning(sig os.Signal) error {
 	pid, err := ReadPID()
 	if err != nil {
 		return fmt.Errorf("failed to read PID: %w", err)
@@ -102,5 +112,5 @@ func StopRunning() error {
 		return fmt.Errorf("failed to find process: %w", err)
 	}
 
-	return process.Signal(syscall.SIGTERM)
+	return process.Signal(sig)
 }
@@ -8,22 +8,26 @@ import (
 )
 
 // PeriodToDuration converts a rate period string to a time.Duration
+// A month is 30 days; minute is mostly useful for trying out the scheduler
 func PeriodToDuration(period string) (time.Duration, error) {
 	switch period {
+	case "minute":
+		return time.Minute, nil
 	case "hour":
 		return time.Hour, nil
 	case "day":
 		return 24 * time.Hour, nil
 	case "week":
 		return 7 * 24 * time.Hour, nil
+	case "month":
+		return 30 * 24 * time.Hour, nil
 	default:
-		return 0, fmt.Errorf("invalid rate_period: %s (must be hour, day, or week)", period)
+		return 0, fmt.Errorf("invalid rate_period: %s (must be minute, hour, day, week, or month)", period)
 	}
 }
 
-// CalculateNextInterval returns a random duration for the next trigger
-// Based on rolling randomness: random value between 0.5x and 1.5x the average interval
-func CalculateNextInterval(rate int, period string) (time.Duration, error) {
+// AverageInterval returns the mean time between entries for a rate
+func AverageInterval(rate int, period string) (time.Duration, error) {
 	if rate <= 0 {
 		return 0, fmt.Errorf("rate must be positive, got %d", rate)
 	}
@@ -32,13 +36,52 @@ func CalculateNextInterval(rate int, period string) (time.Duration, error) {
 	if err != nil {
 		return 0, err
 	}
+	return periodDuration / time.Duration(rate), nil
+}
+
+// ParseMinInterval parses a min_interval setting; empty means no floor
+func ParseMinInterval(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid min_interval: %q (must be a duration like 30m or 2h)", value)
+	}
+	return d, nil
+}
+
+// CalculateNextInterval returns a random duration for the next trigger
+// Based on rolling randomness: jitter scales the window around the average
+// interval, from exact (0) up to 0.5x-1.5x the average (1). Intervals shorter
+// than minInterval are raised to it
+func CalculateNextInterval(rate int, period string, jitter float64, minInterval time.Duration) (time.Duration, error) {
+	interval, err := randomInterval(rate, period, jitter)
+	if err != nil {
+		return 0, err
+	}
+	if interval < minInterval {
+		return minInterval, nil
+	}
+	return interval, nil
+}
+
+// randomInterval picks an interval within the jitter window around the average
+func randomInterval(rate int, period string, jitter float64) (time.Duration, error) {
+	if jitter < 0 || jitter > 1 {
+		return 0, fmt.Errorf("jitter must be between 0 and 1, got %g", jitter)
+	}
 
 	// Average interval between entries
-	avgInterval := periodDuration / time.Duration(rate)
+	avgInterval, err := AverageInterval(rate, period)
+	if err != nil {
+		return 0, err
+	}
 
-	// Random interval between 0.5x and 1.5x average
-	minInterval := avgInterval / 2
-	maxInterval := avgInterval + avgInterval/2
+	// Random interval within ±jitter/2 of the average
+	spread := time.Duration(float64(avgInterval) * jitter / 2)
+	minInterval := avgInterval - spread
+	maxInterval := avgInterval + spread
 
 	// Calculate random offset within range
 	rangeSize := maxInterval - minInterval
@@ -71,8 +114,8 @@ func cryptoRandDuration(max time.Duration) (time.Duration, error) {
 }
 
 // CalculateNextTrigger returns the time for the next entry trigger
-func CalculateNextTrigger(rate int, period string) (time.Time, error) {
-	interval, err := CalculateNextInterval(rate, period)
+func CalculateNextTrigger(rate int, period string, jitter float64, minInterval time.Duration) (time.Time, error) {
+	interval, err := CalculateNextInterval(rate, period, jitter, minInterval)
 	if err != nil {
 		return time.Time{}, err
 	}
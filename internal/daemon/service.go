@@ -0,0 +1,212 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/cldixon/jernel/internal/config"
+)
+
+// ServiceLabel identifies the installed service on both platforms
+const ServiceLabel = "com.cldixon.jernel"
+
+// Service describes a generated service definition for the current platform
+type Service struct {
+	Path       string   // where the unit or plist is written
+	Content    string   // file contents
+	Enable     []string // commands that enable and start the service
+	Disable    []string // commands that stop and disable the service
+	Platform   string   // "systemd" or "launchd"
+	LogPath    string   // where daemon output goes (launchd only)
+	EnvFile    string   // optional environment file read by the unit (systemd only)
+	Executable string   // jernel binary the service runs
+}
+
+// NewService builds the service definition for goos, running executable as the daemon
+// Service files go under home; logs and environment files under configDir,
+// which is passed to the daemon with --config-dir when it isn't the default,
+// along with --profile when a profile is set
+func NewService(goos string, executable string, home string, configDir string, profile string) (*Service, error) {
+	args := []string{"daemon", "start"}
+	if profile != "" {
+		args = append([]string{"--profile", profile}, args...)
+	}
+	if configDir != filepath.Join(home, ".config", "jernel") {
+		args = append([]string{"--config-dir", configDir}, args...)
+	}
+
+	switch goos {
+	case "linux":
+		return systemdService(executable, args, home, configDir), nil
+	case "darwin":
+		return launchdService(executable, args, home, configDir), nil
+	default:
+		return nil, fmt.Errorf("daemon install is not supported on %s (only Linux with systemd and macOS with launchd)", goos)
+	}
+}
+
+// CurrentService builds the service definition for this machine and binary
+func CurrentService() (*Service, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate jernel executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	configDir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewService(runtime.GOOS, executable, home, configDir, config.ActiveProfile())
+}
+
+// Install writes the service definition, refusing to overwrite an existing one
+func (s *Service) Install(force bool) error {
+	if _, err := os.Stat(s.Path); err == nil && !force {
+		return fmt.Errorf("service already installed at %s (use --force to overwrite)", s.Path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create service directory: %w", err)
+	}
+
+	if err := os.WriteFile(s.Path, []byte(s.Content), 0644); err != nil {
+		return fmt.Errorf("failed to write service file: %w", err)
+	}
+	return nil
+}
+
+// Uninstall removes the service definition
+func (s *Service) Uninstall() error {
+	err := os.Remove(s.Path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no service installed at %s", s.Path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove service file: %w", err)
+	}
+	return nil
+}
+
+func systemdService(executable string, args []string, home string, configDir string) *Service {
+	envFile := filepath.Join(configDir, "daemon.env")
+
+	command := []string{systemdQuote(executable)}
+	for _, arg := range args {
+		command = append(command, systemdQuote(arg))
+	}
+
+	content := fmt.Sprintf(`[Unit]
+Description=jernel journal daemon
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+ExecStart=%s
+ExecReload=/bin/kill -HUP $MAINPID
+EnvironmentFile=-%s
+Restart=on-failure
+RestartSec=30
+
+[Install]
+WantedBy=default.target
+`, strings.Join(command, " "), envFile)
+
+	return &Service{
+		Path:       filepath.Join(home, ".config", "systemd", "user", "jernel.service"),
+		Content:    content,
+		Platform:   "systemd",
+		EnvFile:    envFile,
+		Executable: executable,
+		Enable: []string{
+			"systemctl --user daemon-reload",
+			"systemctl --user enable --now jernel.service",
+		},
+		Disable: []string{
+			"systemctl --user disable --now jernel.service",
+			"systemctl --user daemon-reload",
+		},
+	}
+}
+
+func launchdService(executable string, args []string, home string, configDir string) *Service {
+	path := filepath.Join(home, "Library", "LaunchAgents", ServiceLabel+".plist")
+	logPath := filepath.Join(configDir, "daemon.log")
+
+	var programArguments string
+	for _, arg := range append([]string{executable}, args...) {
+		programArguments += "\t\t<string>" + xmlEscape(arg) + "</string>\n"
+	}
+
+	content := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+%s	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>StandardOutPath</key>
+	<string>%s</string>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`, ServiceLabel, programArguments, xmlEscape(logPath), xmlEscape(logPath))
+
+	return &Service{
+		Path:       path,
+		Content:    content,
+		Platform:   "launchd",
+		LogPath:    logPath,
+		Executable: executable,
+		Enable: []string{
+			"launchctl load -w " + shellQuote(path),
+		},
+		Disable: []string{
+			"launchctl unload -w " + shellQuote(path),
+		},
+	}
+}
+
+// systemdQuote quotes a path for ExecStart when it contains spaces
+func systemdQuote(s string) string {
+	if !strings.ContainsAny(s, " \t") {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+func shellQuote(s string) string {
+	if !strings.ContainsAny(s, " \t'\"") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
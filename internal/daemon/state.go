@@ -18,6 +18,10 @@ type State struct {
 	EntriesGenerated int       `json:"entries_generated"`
 	LastEntryAt      time.Time `json:"last_entry_at,omitempty"`
 	LastPersona      string    `json:"last_persona,omitempty"`
+	LastEntryID      int64     `json:"last_entry_id,omitempty"` // target of daemon undo; kept across restarts
+	Errors           int       `json:"errors,omitempty"`        // failed generations since start
+	Heartbeat        time.Time `json:"heartbeat,omitempty"`     // refreshed every loop iteration
+	LastIndex        int       `json:"last_index"`              // position in daemon.personas of the last round-robin pick (-1 before the first)
 }
 
 // StatePath returns the path to the daemon state file
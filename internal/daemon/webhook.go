@@ -0,0 +1,111 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cldixon/jernel/internal/store"
+)
+
+// WebhookPreviewLen is how many characters of the entry are sent in a webhook
+const WebhookPreviewLen = 200
+
+// webhookTimeout bounds each delivery attempt
+const webhookTimeout = 5 * time.Second
+
+// webhookRetryDelay is the pause before the single retry, replaced in tests
+var webhookRetryDelay = 2 * time.Second
+
+// ValidateWebhookURL checks that a webhook URL is http or https (empty disables webhooks)
+func ValidateWebhookURL(webhookURL string) error {
+	if webhookURL == "" {
+		return nil
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid daemon.webhook_url: %s (must be an http or https URL, or empty to disable)", webhookURL)
+	}
+	return nil
+}
+
+// WebhookPayload is the JSON body posted to daemon.webhook_url for each new entry
+// Text and Content carry a readable summary for Slack and Discord incoming webhooks
+type WebhookPayload struct {
+	EntryID   int64     `json:"entry_id"`
+	Persona   string    `json:"persona"`
+	CreatedAt time.Time `json:"created_at"`
+	Preview   string    `json:"preview"`
+	Text      string    `json:"text"`
+	Content   string    `json:"content"`
+}
+
+// NewWebhookPayload builds the payload announcing an entry
+func NewWebhookPayload(e *store.Entry) WebhookPayload {
+	preview := strings.Join(strings.Fields(e.Content), " ")
+	if runes := []rune(preview); len(runes) > WebhookPreviewLen {
+		preview = strings.TrimSpace(string(runes[:WebhookPreviewLen])) + "…"
+	}
+	summary := fmt.Sprintf("New jernel entry #%d from %s: %s", e.ID, e.Persona, preview)
+
+	return WebhookPayload{
+		EntryID:   e.ID,
+		Persona:   e.Persona,
+		CreatedAt: e.CreatedAt,
+		Preview:   preview,
+		Text:      summary,
+		Content:   summary,
+	}
+}
+
+// SendWebhook posts payload to url as JSON, retrying once if the first attempt
+// fails or the response status is not 2xx
+func SendWebhook(ctx context.Context, url string, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	err = postWebhook(ctx, url, body)
+	if err == nil {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return err
+	case <-time.After(webhookRetryDelay):
+	}
+	if retryErr := postWebhook(ctx, url, body); retryErr != nil {
+		return fmt.Errorf("webhook failed twice: %w", retryErr)
+	}
+	return nil
+}
+
+// postWebhook makes one delivery attempt
+func postWebhook(ctx context.Context, url string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package entry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cldixon/jernel/internal/config"
+	"github.com/cldixon/jernel/internal/llm"
+	"github.com/cldixon/jernel/internal/metrics"
+	"github.com/cldixon/jernel/internal/prompt"
+	"github.com/cldixon/jernel/internal/store"
+	"github.com/cldixon/jernel/internal/timefmt"
+)
+
+// DigestPersona is the persona name digests are saved under
+const DigestPersona = "__digest__"
+
+// DigestTag is attached to every saved digest so it can be listed by tag
+const DigestTag = "digest"
+
+// DigestPeriods lists the supported digest ranges
+var DigestPeriods = []string{"day", "week", "month"}
+
+// DigestResult contains a saved digest and the range it covers
+type DigestResult struct {
+	Entry   *store.Entry
+	Start   time.Time
+	End     time.Time
+	Summary int // number of entries summarized
+}
+
+// DigestRange returns the [start, end) window for a digest period ending at end
+func DigestRange(period string, end time.Time) (time.Time, error) {
+	switch period {
+	case "day":
+		return end.AddDate(0, 0, -1), nil
+	case "week":
+		return end.AddDate(0, 0, -7), nil
+	case "month":
+		return end.AddDate(0, -1, 0), nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid period: %s (must be day, week, or month)", period)
+	}
+}
+
+// Digest summarizes recent entries into a single digest entry and saves it
+// Earlier digests are left out of the summary
+func Digest(ctx context.Context, cfg *config.Config, period string) (*DigestResult, error) {
+	end := timefmt.In(time.Now())
+	start, err := DigestRange(period, end)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := store.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+	db.SetCompressMetrics(cfg.CompressMetrics)
+
+	entries, err := db.ListRange(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch entries: %w", err)
+	}
+
+	var digestEntries []prompt.DigestEntry
+	for _, e := range entries {
+		if e.Persona == DigestPersona {
+			continue
+		}
+		digestEntries = append(digestEntries, prompt.DigestEntry{
+			Date:    timefmt.Format(e.CreatedAt, timefmt.Prompt),
+			Persona: e.Persona,
+			Content: e.Content,
+		})
+	}
+	if len(digestEntries) == 0 {
+		return nil, fmt.Errorf("no entries in the last %s to summarize", period)
+	}
+
+	// The snapshot records when the digest was written
+	snapshot, err := metrics.GatherWithOptions(metricsOptions(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to gather metrics: %w", err)
+	}
+
+	client, err := llm.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	result, err := client.GenerateDigest(ctx, prompt.NewDigestContext(period, start, end, digestEntries))
+	if err != nil {
+		return nil, err
+	}
+	result.Content = PostProcess(result.Content, postProcessOptions(cfg))
+
+	saved, err := db.SaveEntry(&store.Entry{
+		Persona:         DigestPersona,
+		Content:         result.Content,
+		CreatedAt:       snapshot.Timestamp,
+		ModelID:         result.ModelID,
+		MessageID:       result.MessageID,
+		MetricsSnapshot: snapshot,
+		PromptText:      result.PromptText,
+		TemplateHash:    result.TemplateHash,
+		Tags:            []string{DigestTag},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save digest: %w", err)
+	}
+
+	return &DigestResult{
+		Entry:   saved,
+		Start:   start,
+		End:     end,
+		Summary: len(digestEntries),
+	}, nil
+}
+
+// Recap summarizes the given entries, oldest first, into a short recap
+// Nothing is saved, and digests among the entries are left out
+func Recap(ctx context.Context, cfg *config.Config, entries []*store.Entry) (string, error) {
+	var recapEntries []prompt.DigestEntry
+	for _, e := range entries {
+		if e.Persona == DigestPersona {
+			continue
+		}
+		recapEntries = append(recapEntries, prompt.DigestEntry{
+			Date:    timefmt.Format(e.CreatedAt, timefmt.Prompt),
+			Persona: e.Persona,
+			Content: e.Content,
+		})
+	}
+	if len(recapEntries) == 0 {
+		return "", fmt.Errorf("no entries to summarize")
+	}
+
+	client, err := llm.NewClient(cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	first, last := entries[0].CreatedAt, entries[len(entries)-1].CreatedAt
+	result, err := client.GenerateRecap(ctx, prompt.NewDigestContext("", first, last, recapEntries))
+	if err != nil {
+		return "", err
+	}
+	return PostProcess(result.Content, postProcessOptions(cfg)), nil
+}
@@ -2,7 +2,10 @@ package entry
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/cldixon/jernel/internal/config"
 	"github.com/cldixon/jernel/internal/llm"
@@ -10,6 +13,7 @@ import (
 	"github.com/cldixon/jernel/internal/persona"
 	"github.com/cldixon/jernel/internal/prompt"
 	"github.com/cldixon/jernel/internal/store"
+	"github.com/cldixon/jernel/internal/timefmt"
 )
 
 // Result contains the generated entry and associated metadata
@@ -19,63 +23,328 @@ type Result struct {
 	Snapshot *metrics.Snapshot
 }
 
+// BatchMetricsMaxAge is how long a metrics snapshot is reused when several
+// entries are generated back to back
+const BatchMetricsMaxAge = time.Minute
+
+// AdHocPersona is the persona name entries written from an inline description are saved under
+const AdHocPersona = "__adhoc__"
+
+// Options controls how Generate handles the generated entry
+type Options struct {
+	NoSave        bool          // return the entry without writing it to the database (its ID is 0)
+	MetricsMaxAge time.Duration // reuse a snapshot gathered this recently (0 = always sample fresh metrics)
+	Note          string        // user note about recent events for the persona to react to
+	Description   string        // inline persona description used instead of the named persona file
+	CreatedAt     time.Time     // backdate the saved entry (zero = when its metrics were gathered)
+	Tags          []string      // tags attached to the saved entry
+}
+
 // Generate creates a new journal entry with the given persona
 // It gathers metrics, calls the LLM, and saves to the database
 func Generate(ctx context.Context, cfg *config.Config, personaName string) (*Result, error) {
-	// Load persona
-	p, err := persona.Get(personaName)
+	return GenerateWithOptions(ctx, cfg, personaName, Options{})
+}
+
+// GenerateWithOptions is like Generate but can skip saving, e.g. to preview a persona,
+// or write from an inline description, in which case personaName is ignored
+func GenerateWithOptions(ctx context.Context, cfg *config.Config, personaName string, opts Options) (*Result, error) {
+	p, err := resolvePersona(personaName, opts.Description)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load persona: %w", err)
+		return nil, err
 	}
 
-	// Gather metrics
-	snapshot, err := metrics.Gather()
+	// Open database early to fetch previous entries for context
+	db, err := store.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+	db.SetCompressMetrics(cfg.CompressMetrics)
+
+	result, snapshot, err := generate(ctx, cfg, db, p, 0, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to gather metrics: %w", err)
+		return nil, err
 	}
 
-	// Open database early to fetch previous entries for context
+	createdAt := snapshot.Timestamp
+	if !opts.CreatedAt.IsZero() {
+		createdAt = opts.CreatedAt
+	}
+
+	entry := &store.Entry{
+		Persona:         p.Name,
+		Content:         result.Content,
+		CreatedAt:       createdAt,
+		ModelID:         result.ModelID,
+		MessageID:       result.MessageID,
+		MetricsSnapshot: snapshot,
+		PromptText:      result.PromptText,
+		TemplateHash:    result.TemplateHash,
+	}
+
+	if opts.NoSave {
+		return &Result{
+			Entry:    entry,
+			Persona:  p,
+			Snapshot: snapshot,
+		}, nil
+	}
+
+	// Save to database; the entry, its tags, and its mood are written together,
+	// so a seeded entry can't be left behind without its seed tag. Previews
+	// return above, so they never cost a classification call
+	entry.Tags = opts.Tags
+	entry.Mood = classifyMood(ctx, cfg, entry.Content)
+	entry, err = db.SaveEntry(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save entry: %w", err)
+	}
+
+	return &Result{
+		Entry:    entry,
+		Persona:  p,
+		Snapshot: snapshot,
+	}, nil
+}
+
+// Regenerate re-runs generation for an existing entry using its persona
+// The entry keeps its ID and creation time; content, model, and metrics are replaced
+func Regenerate(ctx context.Context, cfg *config.Config, id int64) (*Result, error) {
 	db, err := store.Open()
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 	defer db.Close()
+	db.SetCompressMetrics(cfg.CompressMetrics)
+	db.SetKeepRevisions(cfg.KeepRevisions)
+
+	existing, err := db.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.Persona == DigestPersona {
+		return nil, fmt.Errorf("entry #%d is a digest; run 'jernel digest' to write a new one", existing.ID)
+	}
+	if existing.Persona == AdHocPersona {
+		return nil, fmt.Errorf("entry #%d was written from an inline description, which isn't stored; create a new entry instead", existing.ID)
+	}
+
+	p, err := persona.Get(existing.Persona)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persona: %w", err)
+	}
+
+	result, snapshot, err := generate(ctx, cfg, db, p, existing.ID, Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	mood := classifyMood(ctx, cfg, result.Content)
+	entry, err := db.UpdateEntry(existing.ID, result.Content, result.ModelID, result.MessageID, result.PromptText, result.TemplateHash, snapshot, mood)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update entry: %w", err)
+	}
+
+	return &Result{
+		Entry:    entry,
+		Persona:  p,
+		Snapshot: snapshot,
+	}, nil
+}
+
+// resolvePersona loads the named persona, or builds a transient one when an
+// inline description is given
+func resolvePersona(name string, description string) (*persona.Persona, error) {
+	if description = strings.TrimSpace(description); description != "" {
+		return &persona.Persona{Name: AdHocPersona, Description: description, Body: description}, nil
+	}
+
+	p, err := persona.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persona: %w", err)
+	}
+	return p, nil
+}
+
+// generate gathers metrics and calls the LLM for the given persona
+// Entries with excludeID are left out of the continuity context
+func generate(ctx context.Context, cfg *config.Config, db *store.Store, p *persona.Persona, excludeID int64, opts Options) (*llm.GenerateResult, *metrics.Snapshot, error) {
+	// Gather metrics
+	snapshot, err := metrics.GatherCachedWithOptions(opts.MetricsMaxAge, metricsOptions(cfg))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to gather metrics: %w", err)
+	}
 
-	// Fetch previous entries for context continuity
+	// Fetch previous entries for context continuity; ad-hoc entries share a
+	// persona name but not a voice, so they don't continue one another.
+	// Personas with use_history: false always start fresh
 	var previousEntries []prompt.PreviousEntry
-	if cfg.ContextEntries > 0 {
-		recentEntries, err := db.ListByPersona(p.Name, cfg.ContextEntries)
+	if cfg.ContextEntries > 0 && p.Name != AdHocPersona && p.History() {
+		limit := cfg.ContextEntries
+		if excludeID != 0 {
+			limit++
+		}
+		recentEntries, err := db.ListByPersona(p.Name, limit)
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch previous entries: %w", err)
+			return nil, nil, fmt.Errorf("failed to fetch previous entries: %w", err)
 		}
 		for _, e := range recentEntries {
+			if e.ID == excludeID || len(previousEntries) == cfg.ContextEntries {
+				continue
+			}
 			previousEntries = append(previousEntries, prompt.PreviousEntry{
-				Date:    e.CreatedAt.Format("Monday, January 2, 2006 at 3:04 PM"),
-				Content: e.Content,
+				Date:     timefmt.Format(e.CreatedAt, timefmt.Prompt),
+				Content:  e.Content,
+				Snapshot: e.MetricsSnapshot,
 			})
 		}
+		previousEntries = prompt.LimitPreviousEntries(previousEntries, cfg.ContextMaxChars, cfg.ContextBudgetChars)
+	}
+
+	// Compare today's temperature with recent days when the machine reports one
+	var usualTemp *float64
+	if snapshot.Thermal != nil {
+		usualTemp, err = recentThermalAverage(db)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// Generate entry via LLM
 	client, err := llm.NewClient(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create LLM client: %w", err)
+		return nil, nil, fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	entryLength := cfg.EntryLength
+	if p.Length != "" {
+		entryLength = p.Length
 	}
 
-	result, err := client.GenerateEntry(ctx, p.Description, snapshot, previousEntries)
+	validate := validateOptions(cfg)
+	write := func() (*llm.GenerateResult, error) {
+		result, err := client.GenerateEntry(ctx, p.Description, snapshot, previousEntries, opts.Note, usualTemp, entryLength)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate entry: %w", err)
+		}
+		result.Content = PostProcess(result.Content, postProcessOptions(cfg))
+		if err := Validate(result.Content, validate); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	result, err := write()
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate entry: %w", err)
+		return nil, nil, err
+	}
+
+	// Previews aren't saved and ad-hoc entries don't share a voice, so only
+	// saved entries of a persona are checked for repeats
+	if validate.Dedup != "" && validate.Dedup != DedupOff && !opts.NoSave && p.Name != AdHocPersona {
+		last, err := lastEntry(db, p.Name, excludeID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if last != nil && isRepeat(result.Content, last, validate.DedupSimilarity) && validate.Dedup == DedupRetry {
+			if result, err = write(); err != nil {
+				return nil, nil, err
+			}
+		}
+		if last != nil && isRepeat(result.Content, last, validate.DedupSimilarity) {
+			return nil, nil, fmt.Errorf("%w (#%d)", ErrDuplicate, last.ID)
+		}
 	}
 
-	// Save to database
-	entry, err := db.Save(p.Name, result.Content, result.ModelID, result.MessageID, snapshot)
+	// A caller that gave up while the response was arriving doesn't want it saved
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return result, snapshot, nil
+}
+
+// lastEntry returns the persona's most recent entry other than excludeID, or nil if it has none
+func lastEntry(db *store.Store, personaName string, excludeID int64) (*store.Entry, error) {
+	recent, err := db.ListByPersona(personaName, 2)
 	if err != nil {
-		return nil, fmt.Errorf("failed to save entry: %w", err)
+		return nil, fmt.Errorf("failed to fetch the last entry: %w", err)
+	}
+	for _, e := range recent {
+		if e.ID != excludeID {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+// ThermalTrendDays is how many days, including today, the thermal trend looks back over
+const ThermalTrendDays = 7
+
+// recentThermalAverage averages the daily highest temperatures of the days
+// before today, returning nil when none of them have thermal data
+func recentThermalAverage(db *store.Store) (*float64, error) {
+	days, err := db.AverageThermalByDay(ThermalTrendDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch thermal history: %w", err)
 	}
 
-	return &Result{
-		Entry:    entry,
-		Persona:  p,
-		Snapshot: snapshot,
-	}, nil
+	var sum float64
+	var n int
+	for _, d := range days[:len(days)-1] {
+		if d.Samples > 0 {
+			sum += d.Average
+			n++
+		}
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	avg := sum / float64(n)
+	return &avg, nil
+}
+
+// metricsOptions maps config settings onto metric collection options
+func metricsOptions(cfg *config.Config) metrics.Options {
+	var opts metrics.Options
+	if cfg.Metrics != nil {
+		opts.FanCommand = cfg.Metrics.FanCommand
+		opts.MachineType = metrics.MachineType(cfg.Metrics.MachineTypeOverride)
+		opts.CPUSample = time.Duration(cfg.Metrics.CPUSampleMS) * time.Millisecond
+		if cfg.Metrics.CPUSampleMS == 0 {
+			opts.CPUSample = -1 // read usage since the previous sample
+		}
+	}
+	return opts
+}
+
+// RenamePersona renames a persona and moves its entries to the new name
+// If the entries can't be updated, the file rename is rolled back
+func RenamePersona(oldName string, newName string) (int64, error) {
+	if err := persona.Rename(oldName, newName); err != nil {
+		return 0, err
+	}
+
+	// rollback restores the persona file, reporting both errors if that fails too
+	rollback := func(err error) error {
+		if rbErr := persona.Rename(newName, oldName); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to restore persona '%s': %w", oldName, rbErr))
+		}
+		return err
+	}
+
+	db, err := store.Open()
+	if err != nil {
+		return 0, rollback(fmt.Errorf("failed to open database: %w", err))
+	}
+	defer db.Close()
+
+	moved, err := db.ReassignPersona(oldName, newName)
+	if err != nil {
+		return 0, rollback(err)
+	}
+
+	return moved, nil
 }
@@ -0,0 +1,306 @@
+package entry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cldixon/jernel/internal/config"
+	"github.com/cldixon/jernel/internal/metrics"
+	"github.com/cldixon/jernel/internal/persona"
+	"github.com/cldixon/jernel/internal/store"
+)
+
+// setupTestEnv creates a temporary config directory with a personas directory
+func setupTestEnv(t *testing.T) func() {
+	t.Helper()
+
+	configDir, err := os.MkdirTemp("", "jernel-entry-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	t.Setenv(config.DirEnv, configDir)
+
+	if err := os.MkdirAll(filepath.Join(configDir, "personas"), 0755); err != nil {
+		t.Fatalf("failed to create persona dir: %v", err)
+	}
+
+	return func() {
+		os.RemoveAll(configDir)
+	}
+}
+
+// TestRenamePersonaMovesEntries verifies that entries follow a persona rename
+// and unrelated entries are left alone.
+func TestRenamePersonaMovesEntries(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	if err := persona.Save(&persona.Persona{Name: "before", Description: "A persona."}); err != nil {
+		t.Fatalf("failed to save persona: %v", err)
+	}
+
+	db, err := store.Open()
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer db.Close()
+
+	snapshot := &metrics.Snapshot{Timestamp: time.Now()}
+	db.Save("before", "Entry 1", "model", "msg1", "", "", snapshot)
+	db.Save("before", "Entry 2", "model", "msg2", "", "", snapshot)
+	db.Save("other", "Entry 3", "model", "msg3", "", "", snapshot)
+
+	moved, err := RenamePersona("before", "after")
+	if err != nil {
+		t.Fatalf("RenamePersona() failed: %v", err)
+	}
+	if moved != 2 {
+		t.Errorf("expected 2 entries moved, got %d", moved)
+	}
+
+	if n, _ := db.CountByPersona("after"); n != 2 {
+		t.Errorf("expected 2 entries for 'after', got %d", n)
+	}
+	if n, _ := db.CountByPersona("before"); n != 0 {
+		t.Errorf("expected 0 entries for 'before', got %d", n)
+	}
+	if n, _ := db.CountByPersona("other"); n != 1 {
+		t.Errorf("expected unrelated entry untouched, got %d", n)
+	}
+	if _, err := persona.Get("after"); err != nil {
+		t.Errorf("renamed persona not found: %v", err)
+	}
+}
+
+// TestRenamePersonaMissing verifies no entries move when the persona doesn't exist.
+func TestRenamePersonaMissing(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	if _, err := RenamePersona("ghost", "after"); err == nil {
+		t.Error("expected error renaming a missing persona")
+	}
+}
+
+// TestDigestRange verifies each digest period maps to the expected window.
+func TestDigestRange(t *testing.T) {
+	end := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		period string
+		want   time.Time
+	}{
+		{"day", time.Date(2025, 3, 30, 12, 0, 0, 0, time.UTC)},
+		{"week", time.Date(2025, 3, 24, 12, 0, 0, 0, time.UTC)},
+		{"month", time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		start, err := DigestRange(tt.period, end)
+		if err != nil {
+			t.Fatalf("DigestRange(%q) failed: %v", tt.period, err)
+		}
+		if !start.Equal(tt.want) {
+			t.Errorf("DigestRange(%q): expected %v, got %v", tt.period, tt.want, start)
+		}
+	}
+
+	if _, err := DigestRange("year", end); err == nil {
+		t.Error("expected error for unsupported period")
+	}
+}
+
+// TestPostProcess verifies trimming, fence stripping, and paragraph limits.
+func TestPostProcess(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		opts     PostProcessOptions
+		expected string
+	}{
+		{"no options", "  hello  \n", PostProcessOptions{}, "  hello  \n"},
+		{"trim", "\n\n  hello world \n", PostProcessOptions{Trim: true}, "hello world"},
+		{"strip fences", "```markdown\nDear diary,\n\nAll quiet.\n```\n", PostProcessOptions{Trim: true, StripFences: true}, "Dear diary,\n\nAll quiet."},
+		{"bare fence", "```\nDear diary\n```", PostProcessOptions{StripFences: true}, "Dear diary"},
+		{"several code blocks kept", "```\na\n```\ntext\n```\nb\n```", PostProcessOptions{StripFences: true}, "```\na\n```\ntext\n```\nb\n```"},
+		{"fences off", "```\nDear diary\n```", PostProcessOptions{Trim: true}, "```\nDear diary\n```"},
+		{"max paragraphs", "One.\n\nTwo.\n  \nThree.\n\nFour.", PostProcessOptions{Trim: true, MaxParagraphs: 2}, "One.\n\nTwo."},
+		{"under paragraph limit", "One.\n\nTwo.", PostProcessOptions{MaxParagraphs: 3}, "One.\n\nTwo."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PostProcess(tt.content, tt.opts)
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestValidate verifies refusals and short content are rejected while
+// ordinary entries pass.
+func TestValidate(t *testing.T) {
+	opts := ValidateOptions{MinChars: 20, RefusalPhrases: []string{"I'm sorry, but", "As an AI"}}
+
+	tests := []struct {
+		name     string
+		content  string
+		opts     ValidateOptions
+		rejected bool
+	}{
+		{"ordinary entry", "Dear diary, the fans spun up again this afternoon.", opts, false},
+		{"refusal", "I'm sorry, but I can't write a journal entry.", opts, true},
+		{"refusal case-insensitive", "  as an ai language model, I have no diary.", opts, true},
+		{"phrase mid-entry", "Today I thought: as an AI, what do I feel?", opts, false},
+		{"too short", "Fine.", opts, true},
+		{"empty", "   ", opts, true},
+		{"no checks", "Fine.", ValidateOptions{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.content, tt.opts)
+			if !tt.rejected {
+				if err != nil {
+					t.Errorf("expected content to pass, got %v", err)
+				}
+				return
+			}
+			var rejected *RejectedError
+			if !errors.As(err, &rejected) {
+				t.Errorf("expected *RejectedError, got %v", err)
+			}
+		})
+	}
+}
+
+// TestIsRepeat verifies identical and reworded-but-alike entries count as
+// repeats while a genuinely different entry doesn't.
+func TestIsRepeat(t *testing.T) {
+	lastContent := "The fans spun up again this afternoon, and I felt every degree of it."
+	last := &store.Entry{Content: lastContent, ContentHash: store.ContentHash(lastContent)}
+
+	tests := []struct {
+		name       string
+		content    string
+		similarity float64
+		repeat     bool
+	}{
+		{"identical", lastContent, 0.9, true},
+		{"reflowed", "The fans spun up again this afternoon,\nand I felt every degree of it.", 1, true},
+		{"reordered and recased", "And I felt every degree of it: THE FANS spun up again this afternoon.", 0.9, true},
+		{"one word changed", "The fans spun up again this morning, and I felt every degree of it.", 0.8, true},
+		{"one word changed, identical only", "The fans spun up again this morning, and I felt every degree of it.", 1, false},
+		{"different entry", "Quiet night. The disk barely stirred while everyone slept.", 0.9, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRepeat(tt.content, last, tt.similarity); got != tt.repeat {
+				t.Errorf("isRepeat() = %v, want %v (similarity %.2f)", got, tt.repeat, Similarity(tt.content, lastContent))
+			}
+		})
+	}
+}
+
+// TestGuessMood verifies the keyword heuristic picks the most suggested mood
+// and falls back to the default when nothing matches.
+func TestGuessMood(t *testing.T) {
+	tests := []struct {
+		content string
+		mood    string
+	}{
+		{"So tired tonight. Every process feels sluggish and drained.", "tired"},
+		{"The fans are racing, the CPU is buzzing, and I feel alive!", "energetic"},
+		{"I worry the disk is filling up. Restless, uneasy hours.", "anxious"},
+		{"Stuck on the same build again. Annoying.", "frustrated"},
+		{"Memory at 40 percent. Uptime three days.", DefaultMood},
+		{"Happy but tired.", "content"},
+		{"Quiet and happy.", "content"},
+	}
+
+	for _, tt := range tests {
+		if got := GuessMood(tt.content); got != tt.mood {
+			t.Errorf("GuessMood(%q) = %q, want %q", tt.content, got, tt.mood)
+		}
+	}
+}
+
+// TestParseMood verifies a model's answer is matched against the known moods.
+func TestParseMood(t *testing.T) {
+	tests := []struct {
+		answer string
+		mood   string
+	}{
+		{"anxious", "anxious"},
+		{"  Tired.\n", "tired"},
+		{"Mood: ENERGETIC", "energetic"},
+		{"melancholy", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := parseMood(tt.answer); got != tt.mood {
+			t.Errorf("parseMood(%q) = %q, want %q", tt.answer, got, tt.mood)
+		}
+	}
+}
+
+// TestResolvePersona verifies an inline description builds a transient
+// persona without touching persona files.
+func TestResolvePersona(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	p, err := resolvePersona("missing", "  terse and grumpy ")
+	if err != nil {
+		t.Fatalf("resolvePersona() with a description failed: %v", err)
+	}
+	if p.Name != AdHocPersona || p.Description != "terse and grumpy" {
+		t.Errorf("unexpected ad-hoc persona: %+v", p)
+	}
+
+	if _, err := resolvePersona("missing", ""); err == nil {
+		t.Error("expected error for a missing named persona")
+	}
+
+	if err := persona.Save(&persona.Persona{Name: "named", Description: "A persona."}); err != nil {
+		t.Fatalf("failed to save persona: %v", err)
+	}
+	p, err = resolvePersona("named", "")
+	if err != nil {
+		t.Fatalf("resolvePersona() failed: %v", err)
+	}
+	if p.Name != "named" {
+		t.Errorf("expected named persona, got %q", p.Name)
+	}
+}
+
+// TestSeedTimes verifies seed times fall inside the spread, in the past, oldest first.
+func TestSeedTimes(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	spread := 7 * 24 * time.Hour
+
+	times, err := SeedTimes(20, spread, now)
+	if err != nil {
+		t.Fatalf("SeedTimes() failed: %v", err)
+	}
+	if len(times) != 20 {
+		t.Fatalf("expected 20 times, got %d", len(times))
+	}
+	for i, ts := range times {
+		if !ts.Before(now) || ts.Before(now.Add(-spread)) {
+			t.Errorf("time %d (%s) outside the spread", i, ts)
+		}
+		if i > 0 && ts.Before(times[i-1]) {
+			t.Errorf("times not sorted oldest first at %d", i)
+		}
+	}
+
+	if _, err := SeedTimes(1, 0, now); err == nil {
+		t.Error("expected error for a zero spread")
+	}
+}
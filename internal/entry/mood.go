@@ -0,0 +1,81 @@
+package entry
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/cldixon/jernel/internal/config"
+	"github.com/cldixon/jernel/internal/llm"
+)
+
+// Moods are the classifications an entry's mood can take, in tie-break order
+var Moods = []string{"content", "calm", "energetic", "tired", "anxious", "frustrated"}
+
+// DefaultMood is guessed for entries none of the mood words match
+const DefaultMood = "calm"
+
+// moodWords maps each mood to word prefixes that suggest it
+var moodWords = map[string][]string{
+	"content":    {"content", "happy", "glad", "pleased", "satisf", "grateful", "cozy", "comfort", "good", "nice"},
+	"calm":       {"calm", "quiet", "peace", "still", "seren", "idle", "relax", "gentle", "steady"},
+	"energetic":  {"energ", "busy", "excit", "thrill", "buzz", "racing", "alive", "eager", "lively", "rush"},
+	"tired":      {"tired", "exhaust", "weary", "sleep", "drain", "slugg", "fatigue", "yawn", "worn"},
+	"anxious":    {"anxi", "worr", "nervous", "uneas", "fear", "afraid", "dread", "panic", "tense", "stress", "overwhelm", "restless"},
+	"frustrated": {"frustrat", "annoy", "irritat", "angry", "anger", "grumbl", "stuck", "hate", "ugh"},
+}
+
+// GuessMood classifies an entry from the mood words it uses, without an LLM call
+// The mood with the most matching words wins, ties going to the earlier one in Moods
+func GuessMood(content string) string {
+	counts := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(content), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for mood, prefixes := range moodWords {
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(word, prefix) {
+					counts[mood]++
+					break
+				}
+			}
+		}
+	}
+
+	best := ""
+	for _, mood := range Moods {
+		if counts[mood] > counts[best] {
+			best = mood
+		}
+	}
+	if best == "" {
+		return DefaultMood
+	}
+	return best
+}
+
+// parseMood picks the first of Moods in a model's answer, or "" if it names none
+func parseMood(answer string) string {
+	for _, word := range strings.FieldsFunc(strings.ToLower(answer), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for _, mood := range Moods {
+			if word == mood {
+				return mood
+			}
+		}
+	}
+	return ""
+}
+
+// classifyMood labels an entry's mood, asking the LLM when classify_mood is set
+// A failed or unusable answer falls back to GuessMood, since the entry itself
+// was written fine and shouldn't be lost over its label
+func classifyMood(ctx context.Context, cfg *config.Config, content string) string {
+	if cfg.ClassifyMood {
+		client, err := llm.NewClient(cfg)
+		if err == nil {
+			answer, err := client.ClassifyMood(ctx, content, Moods)
+			if mood := parseMood(answer); err == nil && mood != "" {
+				return mood
+			}
+		}
+	}
+	return GuessMood(content)
+}
@@ -0,0 +1,70 @@
+package entry
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/cldixon/jernel/internal/config"
+)
+
+// PostProcessOptions controls cleanup of generated entry content
+type PostProcessOptions struct {
+	Trim          bool // strip leading and trailing whitespace
+	StripFences   bool // remove a code fence wrapping the whole entry
+	MaxParagraphs int  // keep at most this many paragraphs (0 = no limit)
+}
+
+// paragraphBreak matches the blank lines between paragraphs
+var paragraphBreak = regexp.MustCompile(`\n[ \t]*\n\s*`)
+
+// PostProcess cleans up generated content according to opts
+func PostProcess(content string, opts PostProcessOptions) string {
+	if opts.StripFences {
+		content = stripFences(content)
+	}
+
+	if opts.MaxParagraphs > 0 {
+		paragraphs := paragraphBreak.Split(strings.TrimSpace(content), -1)
+		if len(paragraphs) > opts.MaxParagraphs {
+			content = strings.Join(paragraphs[:opts.MaxParagraphs], "\n\n")
+		}
+	}
+
+	if opts.Trim {
+		content = strings.TrimSpace(content)
+	}
+	return content
+}
+
+// stripFences removes a markdown code fence that wraps the entire content,
+// including any language tag on the opening fence
+func stripFences(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") {
+		return content
+	}
+
+	firstLine := strings.IndexByte(trimmed, '\n')
+	if firstLine < 0 {
+		return content
+	}
+	inner := trimmed[firstLine+1 : len(trimmed)-3]
+
+	// A fence in the middle means the entry has several code blocks, not a wrapper
+	if strings.Contains(inner, "```") {
+		return content
+	}
+	return strings.TrimSpace(inner)
+}
+
+// postProcessOptions maps config settings onto post-processing options
+func postProcessOptions(cfg *config.Config) PostProcessOptions {
+	if cfg.PostProcess == nil {
+		return PostProcessOptions{Trim: true}
+	}
+	return PostProcessOptions{
+		Trim:          cfg.PostProcess.Trim,
+		StripFences:   cfg.PostProcess.StripFences,
+		MaxParagraphs: cfg.PostProcess.MaxParagraphs,
+	}
+}
@@ -0,0 +1,32 @@
+package entry
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// SeedTag is attached to every backdated entry written by jernel seed, so
+// seeded entries can be told apart from real history
+const SeedTag = "seed"
+
+// SeedTimes picks count random times within the spread before now, oldest first
+func SeedTimes(count int, spread time.Duration, now time.Time) ([]time.Time, error) {
+	if spread <= 0 {
+		return nil, fmt.Errorf("spread must be positive, got %s", spread)
+	}
+
+	times := make([]time.Time, count)
+	for i := range times {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(spread)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to pick seed time: %w", err)
+		}
+		// Offsets run from just under the spread to 1ns, so no time is in the future
+		times[i] = now.Add(-time.Duration(n.Int64()) - 1)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times, nil
+}
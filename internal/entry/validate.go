@@ -0,0 +1,112 @@
+package entry
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cldixon/jernel/internal/config"
+	"github.com/cldixon/jernel/internal/store"
+)
+
+// ValidateOptions controls which generated content is rejected instead of saved
+type ValidateOptions struct {
+	MinChars        int      // reject content shorter than this (0 = no minimum)
+	RefusalPhrases  []string // reject content starting with any of these (case-insensitive)
+	Dedup           string   // DedupOff, DedupRetry, or DedupSkip
+	DedupSimilarity float64  // Similarity at which content repeats the last entry (1 = identical only)
+}
+
+// Dedup modes for entries that repeat the persona's last entry
+const (
+	DedupOff   = "off"   // save them anyway
+	DedupRetry = "retry" // ask the persona to write once more, then skip
+	DedupSkip  = "skip"  // don't save them
+)
+
+// ErrDuplicate is returned instead of saving an entry that repeats the
+// persona's last one, when dedup is enabled
+var ErrDuplicate = errors.New("generated entry repeats the persona's last entry")
+
+// RejectedError reports generated content that failed validation
+// It is usually transient, so callers like the daemon retry on it
+type RejectedError struct {
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return "generated entry rejected: " + e.Reason
+}
+
+// Validate returns a *RejectedError if content looks like a refusal or is too short
+func Validate(content string, opts ValidateOptions) error {
+	trimmed := strings.TrimSpace(content)
+
+	lower := strings.ToLower(trimmed)
+	for _, phrase := range opts.RefusalPhrases {
+		phrase = strings.TrimSpace(phrase)
+		if phrase != "" && strings.HasPrefix(lower, strings.ToLower(phrase)) {
+			return &RejectedError{Reason: fmt.Sprintf("looks like a refusal (starts with %q)", phrase)}
+		}
+	}
+
+	if n := utf8.RuneCountInString(trimmed); opts.MinChars > 0 && n < opts.MinChars {
+		return &RejectedError{Reason: fmt.Sprintf("too short (%d characters, minimum %d)", n, opts.MinChars)}
+	}
+
+	return nil
+}
+
+// validateOptions maps config settings onto validation options
+func validateOptions(cfg *config.Config) ValidateOptions {
+	v := cfg.Validate
+	if v == nil {
+		v = config.DefaultValidateConfig()
+	}
+	return ValidateOptions{
+		MinChars:        v.MinChars,
+		RefusalPhrases:  v.RefusalPhrases,
+		Dedup:           v.Dedup,
+		DedupSimilarity: v.DedupSimilarity,
+	}
+}
+
+// Similarity returns how many of two texts' words they share, from 0 (none)
+// to 1 (the same words), ignoring case, punctuation, and word order
+func Similarity(a string, b string) float64 {
+	wordsA, wordsB := wordSet(a), wordSet(b)
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+
+	shared := 0
+	for w := range wordsA {
+		if wordsB[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wordsA)+len(wordsB)-shared)
+}
+
+// wordSet returns the distinct lowercase words in s
+func wordSet(s string) map[string]bool {
+	words := map[string]bool{}
+	notWord := func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+	}
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), notWord) {
+		words[w] = true
+	}
+	return words
+}
+
+// isRepeat reports whether content repeats last: the same text, or words in
+// common at or above the similarity threshold
+func isRepeat(content string, last *store.Entry, similarity float64) bool {
+	if last.ContentHash != "" && store.ContentHash(content) == last.ContentHash {
+		return true
+	}
+	return similarity < 1 && Similarity(content, last.Content) >= similarity
+}
@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/cldixon/jernel/internal/config"
+)
+
+// Where an API key was found
+const (
+	KeySourceEnv      = "env"
+	KeySourceFile     = "file"
+	KeySourceKeychain = "keychain"
+)
+
+// KeychainService is the macOS keychain item jernel reads the API key from
+const KeychainService = "jernel"
+
+// ResolveAPIKey finds the Anthropic API key, checking in order the
+// ANTHROPIC_API_KEY environment variable, api_key_file, and the macOS keychain
+func ResolveAPIKey(cfg *config.Config) (key string, source string, err error) {
+	if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
+		return key, KeySourceEnv, nil
+	}
+
+	if cfg.APIKeyFile != "" {
+		key, err := readKeyFile(cfg.APIKeyFile)
+		if err != nil {
+			return "", "", err
+		}
+		return key, KeySourceFile, nil
+	}
+
+	if cfg.APIKeyKeychain && runtime.GOOS == "darwin" {
+		out, err := exec.Command("security", "find-generic-password", "-s", KeychainService, "-w").Output()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read API key from keychain item %q: %w", KeychainService, err)
+		}
+		if key := strings.TrimSpace(string(out)); key != "" {
+			return key, KeySourceKeychain, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("ANTHROPIC_API_KEY environment variable is not set\n\nSet it with: export ANTHROPIC_API_KEY=your-key-here\nor point api_key_file in config.yaml at a file containing the key")
+}
+
+// CheckAPIKeyFile reports a problem if the configured key file is readable
+// by other users; it returns nil when no key file is configured
+func CheckAPIKeyFile(cfg *config.Config) error {
+	if cfg.APIKeyFile == "" || runtime.GOOS == "windows" {
+		return nil
+	}
+
+	path := expandHome(cfg.APIKeyFile)
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("api_key_file %s: %w", path, err)
+	}
+	if info.Mode().Perm()&0o004 != 0 {
+		return fmt.Errorf("api_key_file %s is world-readable; restrict it with: chmod 600 %s", path, path)
+	}
+	return nil
+}
+
+// readKeyFile reads an API key from the first non-empty line of a file
+func readKeyFile(path string) (string, error) {
+	path = expandHome(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read api_key_file: %w", err)
+	}
+
+	key := strings.TrimSpace(string(data))
+	if i := strings.IndexByte(key, '\n'); i >= 0 {
+		key = strings.TrimSpace(key[:i])
+	}
+	if key == "" {
+		return "", fmt.Errorf("api_key_file %s is empty", path)
+	}
+	return key, nil
+}
+
+// expandHome replaces a leading ~/ with the user's home directory
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
@@ -0,0 +1,74 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/cldixon/jernel/internal/config"
+)
+
+// TestResolveAPIKeyOrder verifies the environment variable wins over the key
+// file, which is used when the variable is unset.
+func TestResolveAPIKeyOrder(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "anthropic.key")
+	if err := os.WriteFile(keyFile, []byte("\nsk-from-file\n"), 0600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.APIKeyFile = keyFile
+
+	t.Setenv("ANTHROPIC_API_KEY", "sk-from-env")
+	key, source, err := ResolveAPIKey(cfg)
+	if err != nil {
+		t.Fatalf("ResolveAPIKey failed: %v", err)
+	}
+	if key != "sk-from-env" || source != KeySourceEnv {
+		t.Errorf("expected env key, got %q from %s", key, source)
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	key, source, err = ResolveAPIKey(cfg)
+	if err != nil {
+		t.Fatalf("ResolveAPIKey failed: %v", err)
+	}
+	if key != "sk-from-file" || source != KeySourceFile {
+		t.Errorf("expected file key, got %q from %s", key, source)
+	}
+
+	cfg.APIKeyFile = ""
+	if _, _, err := ResolveAPIKey(cfg); err == nil {
+		t.Error("expected error when no key is available")
+	}
+}
+
+// TestCheckAPIKeyFile verifies world-readable key files are flagged.
+func TestCheckAPIKeyFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not checked on Windows")
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "anthropic.key")
+	if err := os.WriteFile(keyFile, []byte("sk-test"), 0600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	if err := CheckAPIKeyFile(cfg); err != nil {
+		t.Errorf("expected no warning without a key file: %v", err)
+	}
+
+	cfg.APIKeyFile = keyFile
+	if err := CheckAPIKeyFile(cfg); err != nil {
+		t.Errorf("expected 0600 key file to pass: %v", err)
+	}
+
+	if err := os.Chmod(keyFile, 0644); err != nil {
+		t.Fatalf("failed to chmod key file: %v", err)
+	}
+	if err := CheckAPIKeyFile(cfg); err == nil {
+		t.Error("expected warning for world-readable key file")
+	}
+}
@@ -3,9 +3,10 @@ package llm
 import (
 	"context"
 	"fmt"
-	"os"
+	"strings"
 
 	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/cldixon/jernel/internal/config"
 	"github.com/cldixon/jernel/internal/metrics"
 	"github.com/cldixon/jernel/internal/prompt"
@@ -13,15 +14,18 @@ import (
 
 // Client wraps the Anthropic API client
 type Client struct {
-	api          anthropic.Client
-	model        anthropic.Model
-	systemPrompt string
+	api              anthropic.Client
+	model            anthropic.Model
+	systemPrompt     string
+	personaPlacement string
+	language         string
 }
 
 // NewClient creates a new LLM client using settings from config
 func NewClient(cfg *config.Config) (*Client, error) {
-	if os.Getenv("ANTHROPIC_API_KEY") == "" {
-		return nil, fmt.Errorf("ANTHROPIC_API_KEY environment variable is not set\n\nSet it with: export ANTHROPIC_API_KEY=your-key-here")
+	apiKey, _, err := ResolveAPIKey(cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	systemPrompt, err := config.LoadSystemPrompt()
@@ -29,35 +33,137 @@ func NewClient(cfg *config.Config) (*Client, error) {
 		return nil, fmt.Errorf("failed to load system prompt: %w", err)
 	}
 
+	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
+	if cfg.BaseURL != "" {
+		opts = append(opts, option.WithBaseURL(cfg.BaseURL))
+	}
+
 	return &Client{
-		api:          anthropic.NewClient(),
-		model:        anthropic.Model(cfg.Model),
-		systemPrompt: systemPrompt,
+		api:              anthropic.NewClient(opts...),
+		model:            anthropic.Model(cfg.Model),
+		systemPrompt:     systemPrompt,
+		personaPlacement: cfg.PersonaPlacement,
+		language:         cfg.Language,
 	}, nil
 }
 
 // GenerateResult contains the generated entry and metadata from the API call
 type GenerateResult struct {
-	Content   string
-	ModelID   string
-	MessageID string
+	Content      string
+	ModelID      string
+	MessageID    string
+	PromptText   string // rendered message prompt sent to the model
+	TemplateHash string // sha256 of the prompt template source
 }
 
 // GenerateEntry creates a journal entry based on system metrics
-func (c *Client) GenerateEntry(ctx context.Context, personaDescription string, snapshot *metrics.Snapshot, previousEntries []prompt.PreviousEntry) (*GenerateResult, error) {
-	promptCtx := prompt.NewContext(personaDescription, snapshot, previousEntries)
-	promptText, err := prompt.RenderMessagePrompt(promptCtx)
+// userNote is optional free-form text rendered as recent events, usualTemp
+// the recent daily average temperature for the thermal trend (nil if unknown),
+// and entryLength one of config.EntryLengths
+func (c *Client) GenerateEntry(ctx context.Context, personaDescription string, snapshot *metrics.Snapshot, previousEntries []prompt.PreviousEntry, userNote string, usualTemp *float64, entryLength string) (*GenerateResult, error) {
+	tmpl, err := config.LoadMessagePrompt()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load message prompt: %w", err)
+	}
+
+	promptCtx := prompt.NewContext("", snapshot, previousEntries)
+	promptCtx.UserNote = strings.TrimSpace(userNote)
+	promptCtx.UsualTemp = usualTemp
+	promptCtx.LengthGuidance = prompt.LengthPhrase(entryLength)
+	promptCtx.Language = c.language
+
+	// The persona may use template syntax of its own against the same metrics;
+	// with system placement it moves out of the rendered user prompt
+	personaText := prompt.RenderPersona(personaDescription, promptCtx)
+	systemPrompt := c.systemPrompt
+	if c.personaPlacement == "system" {
+		systemPrompt = systemPromptWithPersona(c.systemPrompt, personaText)
+	} else {
+		promptCtx.Persona = personaText
+	}
+
+	promptText, err := prompt.Render(tmpl, promptCtx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to render prompt: %w", err)
 	}
 
+	result, err := c.complete(ctx, systemPrompt, promptText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate entry: %w", err)
+	}
+	result.TemplateHash = prompt.TemplateHash(tmpl)
+	return result, nil
+}
+
+// GenerateDigest summarizes a range of journal entries into a single digest
+func (c *Client) GenerateDigest(ctx context.Context, digestCtx *prompt.DigestContext) (*GenerateResult, error) {
+	tmpl, err := config.LoadDigestPrompt()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load digest prompt: %w", err)
+	}
+
+	digestCtx.Language = c.language
+	promptText, err := prompt.RenderDigest(tmpl, digestCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render digest prompt: %w", err)
+	}
+
+	result, err := c.complete(ctx, c.systemPrompt, promptText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate digest: %w", err)
+	}
+	result.TemplateHash = prompt.TemplateHash(tmpl)
+	return result, nil
+}
+
+// GenerateRecap summarizes entries that haven't been read yet into a short recap
+func (c *Client) GenerateRecap(ctx context.Context, recapCtx *prompt.DigestContext) (*GenerateResult, error) {
+	recapCtx.Language = c.language
+	promptText, err := prompt.RenderDigest(prompt.RecapTemplate, recapCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render recap prompt: %w", err)
+	}
+
+	result, err := c.complete(ctx, c.systemPrompt, promptText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recap: %w", err)
+	}
+	result.TemplateHash = prompt.TemplateHash(prompt.RecapTemplate)
+	return result, nil
+}
+
+// moodSystemPrompt keeps the classifier out of the diarist's voice
+const moodSystemPrompt = "You label the mood of journal entries written by a computer."
+
+// ClassifyMood asks the model which of moods best describes an entry
+// The answer is returned as the model wrote it, for the caller to match against moods
+func (c *Client) ClassifyMood(ctx context.Context, content string, moods []string) (string, error) {
+	promptText, err := prompt.RenderMood(content, moods)
+	if err != nil {
+		return "", fmt.Errorf("failed to render mood prompt: %w", err)
+	}
+
+	result, err := c.complete(ctx, moodSystemPrompt, promptText)
+	if err != nil {
+		return "", fmt.Errorf("failed to classify mood: %w", err)
+	}
+	return result.Content, nil
+}
+
+// systemPromptWithPersona appends a persona description to the system prompt
+func systemPromptWithPersona(systemPrompt string, personaDescription string) string {
+	return strings.TrimRight(systemPrompt, "\n") + "\n\n## Your Persona\n\n" + personaDescription
+}
+
+// complete sends a rendered prompt to the model and returns its text
+func (c *Client) complete(ctx context.Context, systemPrompt string, promptText string) (*GenerateResult, error) {
 	message, err := c.api.Messages.New(ctx, anthropic.MessageNewParams{
 		Model:     c.model,
 		MaxTokens: 1024,
 		System: []anthropic.TextBlockParam{
 			{
 				Type: "text",
-				Text: c.systemPrompt,
+				Text: systemPrompt,
 			},
 		},
 		Messages: []anthropic.MessageParam{
@@ -65,18 +171,34 @@ func (c *Client) GenerateEntry(ctx context.Context, personaDescription string, s
 		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate entry: %w", err)
+		return nil, err
+	}
+
+	content, err := textContent(message.Content)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, block := range message.Content {
+	return &GenerateResult{
+		Content:    content,
+		ModelID:    string(message.Model),
+		MessageID:  message.ID,
+		PromptText: promptText,
+	}, nil
+}
+
+// textContent joins the text blocks of a response in order, skipping thinking,
+// tool use, and any other block types
+func textContent(blocks []anthropic.ContentBlockUnion) (string, error) {
+	var sb strings.Builder
+	for _, block := range blocks {
 		if block.Type == "text" {
-			return &GenerateResult{
-				Content:   block.Text,
-				ModelID:   string(message.Model),
-				MessageID: message.ID,
-			}, nil
+			sb.WriteString(block.Text)
 		}
 	}
 
-	return nil, fmt.Errorf("no text content in response")
+	if strings.TrimSpace(sb.String()) == "" {
+		return "", fmt.Errorf("no text content in response")
+	}
+	return sb.String(), nil
 }
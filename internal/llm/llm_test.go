@@ -0,0 +1,36 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// TestTextContent verifies text blocks are joined in order around other block
+// types, and that a response without text is an error.
+func TestTextContent(t *testing.T) {
+	mixed := []anthropic.ContentBlockUnion{
+		{Type: "thinking", Thinking: "The CPU is busy today."},
+		{Type: "text", Text: "Dear diary, "},
+		{Type: "tool_use", Name: "lookup"},
+		{Type: "text", Text: "what a day."},
+		{Type: "redacted_thinking", Data: "abc"},
+	}
+	got, err := textContent(mixed)
+	if err != nil {
+		t.Fatalf("textContent failed: %v", err)
+	}
+	if got != "Dear diary, what a day." {
+		t.Errorf("expected joined text, got %q", got)
+	}
+
+	for _, blocks := range [][]anthropic.ContentBlockUnion{
+		nil,
+		{{Type: "thinking", Thinking: "hmm"}},
+		{{Type: "text", Text: "  \n"}},
+	} {
+		if _, err := textContent(blocks); err == nil {
+			t.Errorf("expected an error for %v", blocks)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// cache holds the last snapshot gathered through GatherCachedWithOptions
+var cache struct {
+	mu       sync.Mutex
+	snapshot *Snapshot
+	opts     Options
+	at       time.Time
+}
+
+// Hooks replaced in tests to avoid real sampling
+var (
+	gatherFunc = GatherWithOptions
+	nowFunc    = time.Now
+)
+
+// GatherCached returns a recent snapshot if one is younger than maxAge,
+// otherwise it gathers a fresh one; see GatherCachedWithOptions
+func GatherCached(maxAge time.Duration) (*Snapshot, error) {
+	return GatherCachedWithOptions(maxAge, Options{})
+}
+
+// GatherCachedWithOptions reuses the last snapshot gathered with the same
+// options while it is younger than maxAge, skipping the CPU sample
+// Each call returns a copy whose Timestamp and TimeOfDay are current, so
+// entries saved from it keep distinct creation times. A maxAge of zero or
+// less always gathers fresh metrics
+func GatherCachedWithOptions(maxAge time.Duration, opts Options) (*Snapshot, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	now := nowFunc()
+	if maxAge <= 0 || cache.snapshot == nil || cache.opts != opts || now.Sub(cache.at) > maxAge {
+		snapshot, err := gatherFunc(opts)
+		if err != nil {
+			return nil, err
+		}
+		cache.snapshot = snapshot
+		cache.opts = opts
+		cache.at = now
+		return snapshot, nil
+	}
+
+	copied := *cache.snapshot
+	copied.Timestamp = now
+	copied.TimeOfDay = getTimeOfDay(now)
+	return &copied, nil
+}
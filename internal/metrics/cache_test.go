@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+// TestGatherCachedReusesFreshSnapshot verifies a snapshot is reused within
+// maxAge, refreshed after it, and never reused when maxAge is zero.
+func TestGatherCachedReusesFreshSnapshot(t *testing.T) {
+	origGather, origNow := gatherFunc, nowFunc
+	defer func() {
+		gatherFunc, nowFunc = origGather, origNow
+		cache.snapshot = nil
+	}()
+
+	calls := 0
+	gatherFunc = func(opts Options) (*Snapshot, error) {
+		calls++
+		return &Snapshot{CPUPercent: float64(calls), Timestamp: nowFunc()}, nil
+	}
+	clock := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return clock }
+	cache.snapshot = nil
+
+	first, err := GatherCached(time.Minute)
+	if err != nil {
+		t.Fatalf("GatherCached failed: %v", err)
+	}
+
+	clock = clock.Add(30 * time.Second)
+	second, _ := GatherCached(time.Minute)
+	if calls != 1 {
+		t.Fatalf("expected 1 gather within maxAge, got %d", calls)
+	}
+	if second.CPUPercent != first.CPUPercent {
+		t.Errorf("expected cached metrics, got CPU %.0f", second.CPUPercent)
+	}
+	if !second.Timestamp.Equal(clock) {
+		t.Errorf("expected refreshed timestamp %v, got %v", clock, second.Timestamp)
+	}
+	if second == first {
+		t.Error("expected a copy, not the cached snapshot itself")
+	}
+
+	clock = clock.Add(2 * time.Minute)
+	if _, err := GatherCached(time.Minute); err != nil {
+		t.Fatalf("GatherCached failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected a fresh gather after maxAge, got %d gathers", calls)
+	}
+
+	GatherCached(0)
+	if calls != 3 {
+		t.Errorf("expected maxAge 0 to always gather, got %d gathers", calls)
+	}
+
+	GatherCachedWithOptions(time.Minute, Options{FanCommand: "istats"})
+	if calls != 4 {
+		t.Errorf("expected different options to gather, got %d gathers", calls)
+	}
+}
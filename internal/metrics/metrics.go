@@ -1,11 +1,14 @@
 package metrics
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"runtime"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/shirou/gopsutil/v3/cpu"
@@ -58,6 +61,14 @@ type NetworkIO struct {
 	BytesRecv uint64 `json:"bytes_recv"`
 }
 
+// DiskIOInfo holds disk traffic counters, summed across physical disks
+type DiskIOInfo struct {
+	ReadBytes  uint64 `json:"read_bytes"`
+	WriteBytes uint64 `json:"write_bytes"`
+	ReadCount  uint64 `json:"read_count"`
+	WriteCount uint64 `json:"write_count"`
+}
+
 // ThermalInfo holds temperature readings
 type ThermalInfo struct {
 	CPUTemp     *float64 `json:"cpu_temp,omitempty"` // Celsius
@@ -79,6 +90,13 @@ type GPUInfo struct {
 	MemoryTotal *uint64  `json:"memory_total,omitempty"` // Total VRAM in bytes
 }
 
+// ProcessInfo describes a single running process
+type ProcessInfo struct {
+	Name       string  `json:"name"`
+	CPUPercent float64 `json:"cpu_percent"` // CPU usage over the sample window, as a percentage of one core
+	MemoryRSS  uint64  `json:"memory_rss"`  // resident memory in bytes
+}
+
 // PlatformInfo holds OS and architecture info
 type PlatformInfo struct {
 	OS           string `json:"os"`           // darwin, linux, windows
@@ -111,7 +129,9 @@ type Snapshot struct {
 	SwapPercent  *float64      `json:"swap_percent,omitempty"`
 	Battery      *BatteryInfo  `json:"battery,omitempty"`
 	ProcessCount *int          `json:"process_count,omitempty"`
+	TopProcess   *ProcessInfo  `json:"top_process,omitempty"`
 	NetworkIO    *NetworkIO    `json:"network_io,omitempty"`
+	DiskIO       *DiskIOInfo   `json:"disk_io,omitempty"`
 	Thermal      *ThermalInfo  `json:"thermal,omitempty"`
 	Fans         []*FanInfo    `json:"fans,omitempty"`
 	GPU          *GPUInfo      `json:"gpu,omitempty"`
@@ -135,8 +155,44 @@ func SnapshotFromJSON(data string) (*Snapshot, error) {
 	return &s, nil
 }
 
+// Options tunes how metrics are collected
+type Options struct {
+	// FanCommand is an external fan reader used on macOS (e.g. "istats fan speed").
+	// When empty, known tools are detected on PATH.
+	FanCommand string
+
+	// MachineType skips detection and reports this type, for machines that
+	// are detected wrongly (common on cloud VMs). When empty, it is detected.
+	MachineType MachineType
+
+	// CPUSample is how long CPU usage is measured over. Zero uses
+	// DefaultCPUSample; a negative value skips the wait and reports usage
+	// since the previous reading (or since the process started).
+	CPUSample time.Duration
+}
+
+// DefaultCPUSample is the CPU usage sampling window used when Options.CPUSample is zero
+const DefaultCPUSample = time.Second
+
+// cpuInterval maps Options.CPUSample to the interval passed to cpu.Percent,
+// where zero means "since the last call"
+func cpuInterval(sample time.Duration) time.Duration {
+	switch {
+	case sample == 0:
+		return DefaultCPUSample
+	case sample < 0:
+		return 0
+	}
+	return sample
+}
+
 // Gather collects current system metrics and returns a snapshot
 func Gather() (*Snapshot, error) {
+	return GatherWithOptions(Options{})
+}
+
+// GatherWithOptions collects current system metrics using the given options
+func GatherWithOptions(opts Options) (*Snapshot, error) {
 	// get memory stats
 	memInfo, err := mem.VirtualMemory()
 	if err != nil {
@@ -149,8 +205,17 @@ func Gather() (*Snapshot, error) {
 		return nil, err
 	}
 
-	// get cpu usage (average across all cores, 1 second sample)
-	cpuPercents, err := cpu.Percent(time.Second, false)
+	// Record per-process CPU times on both sides of the sample window so the
+	// busiest process is measured over the same window as overall usage
+	pids, pidsErr := process.Pids()
+	var procSamples []processSample
+	if pidsErr == nil {
+		procSamples = sampleProcesses(pids)
+	}
+	sampleStart := time.Now()
+
+	// get cpu usage (average across all cores over the sample window)
+	cpuPercents, err := cpu.Percent(cpuInterval(opts.CPUSample), false)
 	if err != nil {
 		return nil, err
 	}
@@ -181,11 +246,18 @@ func Gather() (*Snapshot, error) {
 		MachineType:   MachineTypeUnknown, // Will be detected below
 	}
 
+	// Process count and top CPU consumer
+	if pidsErr == nil {
+		count := len(pids)
+		snapshot.ProcessCount = &count
+		snapshot.TopProcess = findTopProcess(procSamples, time.Since(sampleStart))
+	}
+
 	// Collect optional metrics (failures are silently ignored)
-	gatherOptionalMetrics(snapshot, memInfo)
+	gatherOptionalMetrics(snapshot, memInfo, opts)
 
 	// Detect machine type (depends on optional metrics being gathered first)
-	snapshot.MachineType = detectMachineType(snapshot)
+	snapshot.MachineType = detectMachineType(snapshot, opts.MachineType)
 
 	return snapshot, nil
 }
@@ -206,7 +278,7 @@ func getTimeOfDay(t time.Time) TimeOfDay {
 }
 
 // gatherOptionalMetrics collects platform-specific metrics that may not be available
-func gatherOptionalMetrics(snapshot *Snapshot, memInfo *mem.VirtualMemoryStat) {
+func gatherOptionalMetrics(snapshot *Snapshot, memInfo *mem.VirtualMemoryStat, opts Options) {
 	// Platform info
 	gatherPlatformInfo(snapshot)
 
@@ -228,12 +300,6 @@ func gatherOptionalMetrics(snapshot *Snapshot, memInfo *mem.VirtualMemoryStat) {
 		snapshot.SwapPercent = &swapInfo.UsedPercent
 	}
 
-	// Process count
-	if pids, err := process.Pids(); err == nil {
-		count := len(pids)
-		snapshot.ProcessCount = &count
-	}
-
 	// Network I/O (aggregate across all interfaces)
 	if netIO, err := net.IOCounters(false); err == nil && len(netIO) > 0 {
 		snapshot.NetworkIO = &NetworkIO{
@@ -242,6 +308,11 @@ func gatherOptionalMetrics(snapshot *Snapshot, memInfo *mem.VirtualMemoryStat) {
 		}
 	}
 
+	// Disk I/O (aggregate across physical disks)
+	if diskIO, err := disk.IOCounters(); err == nil {
+		snapshot.DiskIO = aggregateDiskIO(diskIO, isPhysicalDisk)
+	}
+
 	// Battery (laptops only)
 	gatherBatteryInfo(snapshot)
 
@@ -249,12 +320,94 @@ func gatherOptionalMetrics(snapshot *Snapshot, memInfo *mem.VirtualMemoryStat) {
 	gatherThermalInfo(snapshot)
 
 	// Fan speeds
-	gatherFanInfo(snapshot)
+	gatherFanInfo(snapshot, opts.FanCommand)
 
 	// GPU metrics
 	gatherGPUInfo(snapshot)
 }
 
+// minProcessWindow is the shortest sample window worth measuring process CPU
+// over; with no wait (cpu_sample_ms: 0) lifetime averages are used instead
+const minProcessWindow = 10 * time.Millisecond
+
+// processSample is a process's cumulative CPU time at the start of the sample window
+type processSample struct {
+	proc    *process.Process
+	cpuTime float64 // user + system seconds
+}
+
+// sampleProcesses records the cumulative CPU time of every process but jernel
+// Processes that can't be inspected (e.g. permission denied) are skipped
+func sampleProcesses(pids []int32) []processSample {
+	self := int32(os.Getpid())
+	samples := make([]processSample, 0, len(pids))
+	for _, pid := range pids {
+		if pid == self {
+			continue
+		}
+
+		proc, err := process.NewProcess(pid)
+		if err != nil {
+			continue
+		}
+		times, err := proc.Times()
+		if err != nil {
+			continue
+		}
+		samples = append(samples, processSample{proc: proc, cpuTime: times.User + times.System})
+	}
+	return samples
+}
+
+// findTopProcess returns the process that used the most CPU in the elapsed
+// time since samples were taken, as a percentage of one core
+// Processes that exited or can't be named are skipped
+func findTopProcess(samples []processSample, elapsed time.Duration) *ProcessInfo {
+	type usage struct {
+		proc    *process.Process
+		percent float64
+	}
+
+	usages := make([]usage, 0, len(samples))
+	for _, sample := range samples {
+		var percent float64
+		if elapsed < minProcessWindow {
+			lifetime, err := sample.proc.CPUPercent()
+			if err != nil {
+				continue
+			}
+			percent = lifetime
+		} else {
+			times, err := sample.proc.Times()
+			if err != nil {
+				continue
+			}
+			percent = (times.User + times.System - sample.cpuTime) / elapsed.Seconds() * 100
+		}
+		usages = append(usages, usage{proc: sample.proc, percent: percent})
+	}
+	sort.SliceStable(usages, func(i, j int) bool { return usages[i].percent > usages[j].percent })
+
+	// Only the busiest process needs its name and memory looked up
+	for _, u := range usages {
+		name, err := u.proc.Name()
+		if err != nil || name == "" {
+			continue
+		}
+
+		info := &ProcessInfo{
+			Name:       name,
+			CPUPercent: u.percent,
+		}
+		if memInfo, err := u.proc.MemoryInfo(); err == nil {
+			info.MemoryRSS = memInfo.RSS
+		}
+		return info
+	}
+
+	return nil
+}
+
 // gatherPlatformInfo collects OS and architecture information
 func gatherPlatformInfo(snapshot *Snapshot) {
 	info := &PlatformInfo{
@@ -310,20 +463,102 @@ func gatherThermalInfo(snapshot *Snapshot) {
 }
 
 // gatherFanInfo collects fan speed data
-func gatherFanInfo(snapshot *Snapshot) {
+func gatherFanInfo(snapshot *Snapshot, fanCommand string) {
 	// gopsutil doesn't have direct fan API, try platform-specific methods
 	switch runtime.GOOS {
 	case "darwin":
-		gatherFanInfoDarwin(snapshot)
+		gatherFanInfoDarwin(snapshot, fanCommand)
 	case "linux":
 		gatherFanInfoLinux(snapshot)
 	}
 }
 
+// knownFanCommands are third-party SMC readers tried in order on macOS
+var knownFanCommands = [][]string{
+	{"istats", "fan", "speed"},
+}
+
+// commandTimeout bounds every external command metrics shells out to, so a
+// wedged driver or tool costs one metric instead of hanging generation
+var commandTimeout = 2 * time.Second
+
+// runWithTimeout runs a command and returns its stdout, killing it after
+// commandTimeout. Output is returned alongside any exit error, since some
+// tools report through a non-zero status
+func runWithTimeout(name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	// Don't wait on pipes held open by children that outlive the kill
+	cmd.WaitDelay = commandTimeout
+	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%s timed out after %s", name, commandTimeout)
+	}
+	return output, err
+}
+
 // gatherFanInfoDarwin reads fan info on macOS
-func gatherFanInfoDarwin(snapshot *Snapshot) {
-	// macOS fan info requires SMC access which needs elevated privileges
-	// or third-party tools. Skip for now.
+func gatherFanInfoDarwin(snapshot *Snapshot, fanCommand string) {
+	// macOS fan info requires SMC access, which needs elevated privileges
+	// or a third-party reader. Use one if configured or found on PATH.
+	var args []string
+	if fields := strings.Fields(fanCommand); len(fields) > 0 {
+		args = fields
+	} else {
+		for _, candidate := range knownFanCommands {
+			if _, err := exec.LookPath(candidate[0]); err == nil {
+				args = candidate
+				break
+			}
+		}
+	}
+	if len(args) == 0 {
+		return
+	}
+
+	output, err := runWithTimeout(args[0], args[1:]...)
+	if err != nil {
+		return
+	}
+
+	if fans := parseFanOutput(string(output)); len(fans) > 0 {
+		snapshot.Fans = fans
+	}
+}
+
+// parseFanOutput extracts fan speeds from lines like "Fan 0 speed: 1200 RPM"
+// Each line mentioning RPM yields one fan; zero readings are skipped
+func parseFanOutput(output string) []*FanInfo {
+	var fans []*FanInfo
+	for _, line := range splitLines(output) {
+		idx := strings.Index(strings.ToUpper(line), "RPM")
+		if idx < 0 {
+			continue
+		}
+
+		// The reading is the last number before "RPM"
+		fields := strings.Fields(line[:idx])
+		if len(fields) == 0 {
+			continue
+		}
+		var rpm float64
+		if _, err := fmt.Sscanf(fields[len(fields)-1], "%f", &rpm); err != nil || rpm <= 0 {
+			continue
+		}
+
+		name := fmt.Sprintf("fan%d", len(fans))
+		if colon := strings.Index(line, ":"); colon >= 0 && colon < idx {
+			name = strings.TrimSpace(line[:colon])
+		}
+
+		fans = append(fans, &FanInfo{
+			Speed: rpm,
+			Name:  name,
+		})
+	}
+	return fans
 }
 
 // gatherFanInfoLinux reads fan info from sysfs
@@ -383,18 +618,96 @@ func gatherGPUInfo(snapshot *Snapshot) {
 	}
 }
 
-// gatherGPUInfoDarwin reads GPU info on macOS
+// gatherGPUInfoDarwin reads GPU info on macOS using ioreg
 func gatherGPUInfoDarwin(snapshot *Snapshot) {
-	// macOS GPU metrics require IOKit or Metal APIs
-	// Could use `ioreg` but it's complex to parse
-	// Skip for now - would need CGO or external tool
+	// IOAccelerator exposes a PerformanceStatistics dictionary with
+	// utilization and memory counters, no elevated privileges required
+	output, err := runWithTimeout("ioreg", "-r", "-d", "1", "-c", "IOAccelerator")
+	if err != nil {
+		return
+	}
+
+	if gpu := parseIORegGPU(string(output)); gpu != nil {
+		snapshot.GPU = gpu
+	}
+}
+
+// parseIORegGPU extracts GPU metrics from `ioreg -c IOAccelerator` output
+// Returns nil if no utilization figure is present
+func parseIORegGPU(output string) *GPUInfo {
+	// Apple Silicon and recent AMD drivers report "Device Utilization %",
+	// older drivers report "GPU Activity(%)"
+	usage, ok := ioregValue(output, "Device Utilization %")
+	if !ok {
+		usage, ok = ioregValue(output, "GPU Activity(%)")
+	}
+	if !ok {
+		return nil
+	}
+
+	usagePct := float64(usage)
+	gpu := &GPUInfo{
+		Usage: &usagePct,
+	}
+
+	// Memory in use by the GPU (unified memory on Apple Silicon)
+	if used, ok := ioregValue(output, "In use system memory"); ok {
+		gpu.MemoryUsed = &used
+	} else if used, ok := ioregValue(output, "vramUsedBytes"); ok {
+		gpu.MemoryUsed = &used
+	}
+
+	// Dedicated VRAM is only reported on discrete GPUs
+	if totalMB, ok := ioregValue(output, "VRAM,totalMB"); ok {
+		total := totalMB * 1024 * 1024
+		gpu.MemoryTotal = &total
+	}
+
+	return gpu
+}
+
+// ioregValue finds the first `"key"=<integer>` pair in ioreg output
+func ioregValue(output, key string) (uint64, bool) {
+	needle := `"` + key + `"`
+	for i := 0; i+len(needle) <= len(output); i++ {
+		if output[i:i+len(needle)] != needle {
+			continue
+		}
+
+		// Skip optional whitespace around '='
+		j := i + len(needle)
+		for j < len(output) && output[j] == ' ' {
+			j++
+		}
+		if j >= len(output) || output[j] != '=' {
+			continue
+		}
+		j++
+		for j < len(output) && output[j] == ' ' {
+			j++
+		}
+
+		start := j
+		for j < len(output) && output[j] >= '0' && output[j] <= '9' {
+			j++
+		}
+		if j == start {
+			continue
+		}
+
+		var value uint64
+		if _, err := fmt.Sscanf(output[start:j], "%d", &value); err != nil {
+			continue
+		}
+		return value, true
+	}
+	return 0, false
 }
 
 // gatherGPUInfoLinux reads GPU info from sysfs/nvidia-smi
 func gatherGPUInfoLinux(snapshot *Snapshot) {
 	// Try nvidia-smi for NVIDIA GPUs
-	cmd := exec.Command("nvidia-smi", "--query-gpu=utilization.gpu,memory.used,memory.total", "--format=csv,noheader,nounits")
-	output, err := cmd.Output()
+	output, err := runWithTimeout("nvidia-smi", "--query-gpu=utilization.gpu,memory.used,memory.total", "--format=csv,noheader,nounits")
 	if err == nil {
 		// Parse output: "45, 2048, 8192" (usage%, mem used MB, mem total MB)
 		var usage float64
@@ -495,8 +808,7 @@ func gatherBatteryDarwin(snapshot *Snapshot) {
 	// Use pmset -g batt to get battery info
 	// Output format: "Now drawing from 'Battery Power'" or "'AC Power'"
 	// "-InternalBattery-0 (id=...)	95%; charging; 0:30 remaining"
-	cmd := exec.Command("pmset", "-g", "batt")
-	output, err := cmd.Output()
+	output, err := runWithTimeout("pmset", "-g", "batt")
 	if err != nil {
 		return
 	}
@@ -598,7 +910,17 @@ func extractPercentage(s string) float64 {
 }
 
 // detectMachineType determines the type of machine based on available info
-func detectMachineType(snapshot *Snapshot) MachineType {
+// A non-empty override is returned as is, without probing the system
+func detectMachineType(snapshot *Snapshot, override MachineType) MachineType {
+	if override != "" {
+		return override
+	}
+
+	// systemd knows about more container and hypervisor types than the checks below
+	if virt := detectVirt(); virt != MachineTypeUnknown {
+		return virt
+	}
+
 	// Check for container first (most specific)
 	if isContainer() {
 		return MachineTypeContainer
@@ -630,9 +952,11 @@ func detectMachineType(snapshot *Snapshot) MachineType {
 
 // isContainer checks if running inside a container
 func isContainer() bool {
-	// Check for Docker
-	if _, err := os.Stat("/.dockerenv"); err == nil {
-		return true
+	// Check for Docker and Podman
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
 	}
 
 	// Check cgroup for docker/kubepods
@@ -647,8 +971,67 @@ func isContainer() bool {
 	return false
 }
 
+// detectVirt asks systemd-detect-virt, where available, whether this is a
+// container or VM, returning MachineTypeUnknown when it can't tell
+func detectVirt() MachineType {
+	if runtime.GOOS != "linux" {
+		return MachineTypeUnknown
+	}
+	path, err := exec.LookPath("systemd-detect-virt")
+	if err != nil {
+		return MachineTypeUnknown
+	}
+
+	// It exits non-zero and prints "none" on bare metal
+	output, _ := runWithTimeout(path)
+	return parseDetectVirt(string(output))
+}
+
+// containerVirts are systemd-detect-virt identifiers for container runtimes;
+// any other non-"none" identifier is a hypervisor
+var containerVirts = []string{
+	"openvz", "lxc", "lxc-libvirt", "systemd-nspawn", "docker", "podman",
+	"rkt", "wsl", "proot", "pouch",
+}
+
+// parseDetectVirt maps systemd-detect-virt output onto a machine type
+func parseDetectVirt(output string) MachineType {
+	virt := strings.TrimSpace(output)
+	if virt == "" || virt == "none" {
+		return MachineTypeUnknown
+	}
+	for _, c := range containerVirts {
+		if virt == c {
+			return MachineTypeContainer
+		}
+	}
+	return MachineTypeVM
+}
+
+// hasHypervisorFlag reports whether /proc/cpuinfo content lists the
+// "hypervisor" CPU flag, which x86 guests expose
+func hasHypervisorFlag(cpuinfo string) bool {
+	for _, line := range splitLines(cpuinfo) {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) != "flags" {
+			continue
+		}
+		for _, flag := range strings.Fields(value) {
+			if flag == "hypervisor" {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
 // isVirtualMachine checks if running inside a VM
 func isVirtualMachine() bool {
+	if data, err := os.ReadFile("/proc/cpuinfo"); err == nil && hasHypervisorFlag(string(data)) {
+		return true
+	}
+
 	// Use gopsutil's virtualization detection
 	system, role, err := host.Virtualization()
 	if err != nil {
@@ -692,3 +1075,38 @@ func isLikelyServer(snapshot *Snapshot) bool {
 
 	return false
 }
+
+// aggregateDiskIO sums disk counters across the devices include accepts,
+// skipping loop and RAM devices; nil when no device is counted
+func aggregateDiskIO(counters map[string]disk.IOCountersStat, include func(string) bool) *DiskIOInfo {
+	var info DiskIOInfo
+	counted := 0
+	for name, c := range counters {
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") || strings.HasPrefix(name, "zram") {
+			continue
+		}
+		if !include(name) {
+			continue
+		}
+		info.ReadBytes += c.ReadBytes
+		info.WriteBytes += c.WriteBytes
+		info.ReadCount += c.ReadCount
+		info.WriteCount += c.WriteCount
+		counted++
+	}
+	if counted == 0 {
+		return nil
+	}
+	return &info
+}
+
+// isPhysicalDisk reports whether a device's I/O should be counted. Linux lists
+// partitions and device-mapper volumes alongside their disks, so only devices
+// backed by hardware under /sys/block are kept there to avoid counting bytes twice.
+func isPhysicalDisk(name string) bool {
+	if runtime.GOOS != "linux" {
+		return true
+	}
+	_, err := os.Stat("/sys/block/" + name + "/device")
+	return err == nil
+}
@@ -0,0 +1,224 @@
+package metrics
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// readTestdata loads a captured command output sample
+func readTestdata(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to read testdata %s: %v", name, err)
+	}
+	return string(data)
+}
+
+// TestParseIORegGPUAppleSilicon verifies utilization and unified memory are
+// parsed from Apple Silicon ioreg output.
+func TestParseIORegGPUAppleSilicon(t *testing.T) {
+	gpu := parseIORegGPU(readTestdata(t, "ioreg_apple_silicon.txt"))
+	if gpu == nil {
+		t.Fatal("expected GPU info, got nil")
+	}
+
+	if gpu.Usage == nil || *gpu.Usage != 23 {
+		t.Errorf("expected usage 23, got %v", gpu.Usage)
+	}
+	if gpu.MemoryUsed == nil || *gpu.MemoryUsed != 367820800 {
+		t.Errorf("expected memory used 367820800, got %v", gpu.MemoryUsed)
+	}
+	if gpu.MemoryTotal != nil {
+		t.Errorf("expected no dedicated VRAM total, got %d", *gpu.MemoryTotal)
+	}
+}
+
+// TestParseIORegGPUDiscrete verifies VRAM figures are parsed from discrete
+// GPU ioreg output, which uses spaces around '='.
+func TestParseIORegGPUDiscrete(t *testing.T) {
+	gpu := parseIORegGPU(readTestdata(t, "ioreg_amd_discrete.txt"))
+	if gpu == nil {
+		t.Fatal("expected GPU info, got nil")
+	}
+
+	if gpu.Usage == nil || *gpu.Usage != 7 {
+		t.Errorf("expected usage 7, got %v", gpu.Usage)
+	}
+	if gpu.MemoryUsed == nil || *gpu.MemoryUsed != 1073741824 {
+		t.Errorf("expected memory used 1073741824, got %v", gpu.MemoryUsed)
+	}
+	if gpu.MemoryTotal == nil || *gpu.MemoryTotal != 4096*1024*1024 {
+		t.Errorf("expected memory total 4 GB, got %v", gpu.MemoryTotal)
+	}
+}
+
+// TestParseIORegGPUMissing verifies unparseable output yields nil.
+func TestParseIORegGPUMissing(t *testing.T) {
+	tests := []string{
+		"",
+		"+-o IOAccelerator\n    {\n      \"IOClass\" = \"Foo\"\n    }\n",
+		`"Device Utilization %"=abc`,
+	}
+
+	for _, output := range tests {
+		if gpu := parseIORegGPU(output); gpu != nil {
+			t.Errorf("expected nil for %q, got %+v", output, gpu)
+		}
+	}
+}
+
+// TestParseFanOutput verifies RPM readings are parsed from fan reader output.
+func TestParseFanOutput(t *testing.T) {
+	output := "--- Fan Stats ---\nTotal fans in system:   2\nFan 0 speed:            1203 RPM\nFan 1 speed:            0 RPM\nRight fan 1850.5 rpm\n"
+
+	fans := parseFanOutput(output)
+	if len(fans) != 2 {
+		t.Fatalf("expected 2 fans, got %d", len(fans))
+	}
+
+	if fans[0].Speed != 1203 || fans[0].Name != "Fan 0 speed" {
+		t.Errorf("unexpected first fan: %+v", fans[0])
+	}
+	if fans[1].Speed != 1850.5 || fans[1].Name != "fan1" {
+		t.Errorf("unexpected second fan: %+v", fans[1])
+	}
+}
+
+// TestParseFanOutputEmpty verifies output without readings yields no fans.
+func TestParseFanOutputEmpty(t *testing.T) {
+	for _, output := range []string{"", "no fans here", "RPM"} {
+		if fans := parseFanOutput(output); len(fans) != 0 {
+			t.Errorf("expected no fans for %q, got %d", output, len(fans))
+		}
+	}
+}
+
+// TestParseDetectVirt verifies systemd-detect-virt identifiers map to
+// containers, VMs, or nothing on bare metal.
+func TestParseDetectVirt(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected MachineType
+	}{
+		{"none\n", MachineTypeUnknown},
+		{"", MachineTypeUnknown},
+		{"docker\n", MachineTypeContainer},
+		{"lxc", MachineTypeContainer},
+		{"systemd-nspawn\n", MachineTypeContainer},
+		{"kvm\n", MachineTypeVM},
+		{"amazon", MachineTypeVM},
+		{"microsoft\n", MachineTypeVM},
+	}
+
+	for _, tt := range tests {
+		if got := parseDetectVirt(tt.output); got != tt.expected {
+			t.Errorf("parseDetectVirt(%q): expected %s, got %s", tt.output, tt.expected, got)
+		}
+	}
+}
+
+// TestHasHypervisorFlag verifies the hypervisor CPU flag is found only in
+// the flags line.
+func TestHasHypervisorFlag(t *testing.T) {
+	guest := "processor\t: 0\nmodel name\t: Intel Xeon\nflags\t\t: fpu vme sse2 hypervisor lahf_lm\n"
+	host := "processor\t: 0\nmodel name\t: hypervisor-ready CPU\nflags\t\t: fpu vme sse2 vmx\n"
+
+	if !hasHypervisorFlag(guest) {
+		t.Error("expected hypervisor flag in guest cpuinfo")
+	}
+	if hasHypervisorFlag(host) {
+		t.Error("expected no hypervisor flag in host cpuinfo")
+	}
+	if hasHypervisorFlag("") {
+		t.Error("expected no hypervisor flag in empty cpuinfo")
+	}
+}
+
+// TestDetectMachineTypeOverride verifies an override skips detection.
+func TestDetectMachineTypeOverride(t *testing.T) {
+	snapshot := &Snapshot{Battery: &BatteryInfo{Percent: 50}}
+	if got := detectMachineType(snapshot, MachineTypeServer); got != MachineTypeServer {
+		t.Errorf("expected override to win, got %s", got)
+	}
+}
+
+// TestCPUInterval verifies zero keeps the one second default and a negative
+// sample reads usage since the previous call.
+func TestCPUInterval(t *testing.T) {
+	tests := []struct {
+		sample   time.Duration
+		expected time.Duration
+	}{
+		{0, DefaultCPUSample},
+		{-1, 0},
+		{250 * time.Millisecond, 250 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := cpuInterval(tt.sample); got != tt.expected {
+			t.Errorf("cpuInterval(%v): expected %v, got %v", tt.sample, tt.expected, got)
+		}
+	}
+}
+
+// TestRunWithTimeout verifies a hung command is killed and reported as an
+// error instead of blocking, while a quick one still returns its output.
+func TestRunWithTimeout(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+	orig := commandTimeout
+	commandTimeout = 100 * time.Millisecond
+	defer func() { commandTimeout = orig }()
+
+	start := time.Now()
+	output, err := runWithTimeout("sleep", "5")
+	if err == nil {
+		t.Error("expected a timeout error for a hung command")
+	}
+	if output != nil {
+		t.Errorf("expected no output after a timeout, got %q", output)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("command was not abandoned promptly, took %s", elapsed)
+	}
+
+	output, err = runWithTimeout("echo", "ok")
+	if err != nil {
+		t.Fatalf("runWithTimeout() failed: %v", err)
+	}
+	if string(output) != "ok\n" {
+		t.Errorf("expected %q, got %q", "ok\n", output)
+	}
+}
+
+// TestAggregateDiskIO verifies counters are summed across disks, skipping loop
+// and RAM devices and anything the filter rejects.
+func TestAggregateDiskIO(t *testing.T) {
+	counters := map[string]disk.IOCountersStat{
+		"sda":   {ReadBytes: 100, WriteBytes: 200, ReadCount: 1, WriteCount: 2},
+		"sda1":  {ReadBytes: 100, WriteBytes: 200, ReadCount: 1, WriteCount: 2},
+		"nvme0": {ReadBytes: 10, WriteBytes: 20, ReadCount: 3, WriteCount: 4},
+		"loop0": {ReadBytes: 1000, WriteBytes: 1000},
+		"ram0":  {ReadBytes: 1000, WriteBytes: 1000},
+		"zram0": {ReadBytes: 1000, WriteBytes: 1000},
+	}
+	notPartition := func(name string) bool { return name != "sda1" }
+
+	got := aggregateDiskIO(counters, notPartition)
+	want := DiskIOInfo{ReadBytes: 110, WriteBytes: 220, ReadCount: 4, WriteCount: 6}
+	if got == nil || *got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	onlyVirtual := map[string]disk.IOCountersStat{"loop0": {ReadBytes: 1}}
+	if got := aggregateDiskIO(onlyVirtual, notPartition); got != nil {
+		t.Errorf("expected nil with no physical disks, got %+v", got)
+	}
+}
@@ -3,22 +3,44 @@ package persona
 import (
 	"bytes"
 	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/adrg/frontmatter"
 	"github.com/cldixon/jernel/internal/config"
+	"gopkg.in/yaml.v3"
 )
 
-//go:embed examples/*.md
+//go:embed examples/*.md examples/samples/*.md
 var examplesFS embed.FS
 
+// Sentinel errors, wrapped with the persona name; check them with errors.Is
+var (
+	ErrNotFound      = errors.New("persona not found")
+	ErrAlreadyExists = errors.New("persona already exists")
+)
+
+// FragmentsDir is the subdirectory of personas/ holding shared include fragments
+const FragmentsDir = "_fragments"
+
 // Persona defines a character voice for journal entries
 type Persona struct {
-	Name        string `yaml:"name"`
-	Description string
+	Name        string   `yaml:"name"`
+	Include     []string `yaml:"include,omitempty"`     // fragments or personas appended to the description
+	Length      string   `yaml:"length,omitempty"`      // overrides the entry_length config setting
+	UseHistory  *bool    `yaml:"use_history,omitempty"` // false skips previous entries in the prompt (nil = true)
+	Description string   `yaml:"-"`                     // full description with includes resolved
+	Body        string   `yaml:"-"`                     // the persona's own text, without includes
+}
+
+// History reports whether the persona's previous entries go into its prompt
+func (p *Persona) History() bool {
+	return p.UseHistory == nil || *p.UseHistory
 }
 
 // Dir returns the personas directory path
@@ -31,7 +53,13 @@ func Dir() (string, error) {
 }
 
 // Load reads a persona from a markdown file with frontmatter
+// Names listed under include are resolved from personas/_fragments/ first,
+// then from other personas, and appended to the description
 func Load(path string) (*Persona, error) {
+	return load(path, filepath.Dir(path), nil)
+}
+
+func load(path string, root string, chain []string) (*Persona, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open persona file: %w", err)
@@ -44,11 +72,71 @@ func Load(path string) (*Persona, error) {
 		return nil, fmt.Errorf("failed to parse persona frontmatter: %w", err)
 	}
 
-	p.Description = strings.TrimSpace(string(content))
+	if p.Length != "" && !slices.Contains(config.EntryLengths, p.Length) {
+		return nil, fmt.Errorf("invalid persona length: %s (must be one of: %s)", p.Length, strings.Join(config.EntryLengths, ", "))
+	}
+
+	p.Body = strings.TrimSpace(string(content))
+	p.Description = p.Body
+
+	if len(p.Include) == 0 {
+		return &p, nil
+	}
+
+	chain = append(chain[:len(chain):len(chain)], filepath.Clean(path))
+	parts := []string{p.Body}
+	for _, name := range p.Include {
+		incPath, err := includePath(root, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, seen := range chain {
+			if seen == incPath {
+				return nil, fmt.Errorf("circular persona include: %s", includeChain(chain, incPath))
+			}
+		}
+
+		inc, err := load(incPath, root, chain)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, inc.Description)
+	}
+
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	p.Description = strings.Join(nonEmpty, "\n\n")
 
 	return &p, nil
 }
 
+// includePath finds an include by name, preferring fragments over personas
+func includePath(root string, name string) (string, error) {
+	candidates := []string{
+		filepath.Join(root, FragmentsDir, name+".md"),
+		filepath.Join(root, name+".md"),
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return filepath.Clean(path), nil
+		}
+	}
+	return "", fmt.Errorf("persona include '%s' not found (check %s/)", name, filepath.Join(root, FragmentsDir))
+}
+
+// includeChain formats an include cycle for error messages
+func includeChain(chain []string, next string) string {
+	names := make([]string, 0, len(chain)+1)
+	for _, path := range append(chain, next) {
+		names = append(names, strings.TrimSuffix(filepath.Base(path), ".md"))
+	}
+	return strings.Join(names, " -> ")
+}
+
 // LoadByName looks for a persona file in the personas directory
 func LoadByName(name string) (*Persona, error) {
 	dir, err := Dir()
@@ -61,6 +149,8 @@ func LoadByName(name string) (*Persona, error) {
 }
 
 // Save writes a persona to disk as markdown with frontmatter
+// Loaded personas keep their includes: Body is written in preference to the
+// resolved Description so fragments aren't inlined into the file
 func Save(p *Persona) error {
 	dir, err := Dir()
 	if err != nil {
@@ -71,12 +161,20 @@ func Save(p *Persona) error {
 		return fmt.Errorf("failed to create personas directory: %w", err)
 	}
 
-	content := fmt.Sprintf(`---
-name: %s
----
+	// Description has the includes resolved, so it only stands in for an
+	// empty Body when there are none; an include-only persona stays empty
+	body := p.Body
+	if body == "" && len(p.Include) == 0 {
+		body = p.Description
+	}
 
-%s
-`, p.Name, strings.TrimSpace(p.Description))
+	// Marshalling handles quoting, so names and includes with YAML-special
+	// characters (e.g. ": " or "#") survive a round trip
+	header, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to encode persona frontmatter: %w", err)
+	}
+	content := "---\n" + string(header) + "---\n\n" + strings.TrimSpace(body) + "\n"
 
 	path := filepath.Join(dir, p.Name+".md")
 	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
@@ -116,7 +214,11 @@ func List() ([]string, error) {
 func Get(name string) (*Persona, error) {
 	p, err := LoadByName(name)
 	if err != nil {
-		return nil, fmt.Errorf("persona '%s' not found (check ~/.config/jernel/personas/)", name)
+		if errors.Is(err, fs.ErrNotExist) {
+			dir, _ := Dir()
+			return nil, fmt.Errorf("%w: '%s' (check %s/)", ErrNotFound, name, dir)
+		}
+		return nil, fmt.Errorf("failed to load persona '%s': %w", name, err)
 	}
 	return p, nil
 }
@@ -136,7 +238,7 @@ func Create(name string) (string, error) {
 
 	// Check if file already exists
 	if _, err := os.Stat(path); err == nil {
-		return "", fmt.Errorf("persona '%s' already exists", name)
+		return "", fmt.Errorf("%w: '%s'", ErrAlreadyExists, name)
 	}
 
 	// Create template content
@@ -170,7 +272,7 @@ func Delete(name string) error {
 	path := filepath.Join(dir, name+".md")
 
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return fmt.Errorf("persona '%s' not found", name)
+		return fmt.Errorf("%w: '%s'", ErrNotFound, name)
 	}
 
 	if err := os.Remove(path); err != nil {
@@ -180,6 +282,34 @@ func Delete(name string) error {
 	return nil
 }
 
+// Rename moves a persona file to a new name, updating its frontmatter
+func Rename(oldName string, newName string) error {
+	if oldName == newName {
+		return fmt.Errorf("persona is already named '%s'", newName)
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, newName+".md")); err == nil {
+		return fmt.Errorf("%w: '%s'", ErrAlreadyExists, newName)
+	}
+
+	p, err := Get(oldName)
+	if err != nil {
+		return err
+	}
+
+	p.Name = newName
+	if err := Save(p); err != nil {
+		return err
+	}
+
+	return Delete(oldName)
+}
+
 // ListExamples returns the names of all bundled example personas
 func ListExamples() ([]string, error) {
 	entries, err := examplesFS.ReadDir("examples")
@@ -202,7 +332,7 @@ func ListExamples() ([]string, error) {
 func GetExample(name string) (*Persona, error) {
 	data, err := examplesFS.ReadFile("examples/" + name + ".md")
 	if err != nil {
-		return nil, fmt.Errorf("example persona '%s' not found", name)
+		return nil, fmt.Errorf("%w: no bundled example named '%s'", ErrNotFound, name)
 	}
 
 	var p Persona
@@ -214,3 +344,13 @@ func GetExample(name string) (*Persona, error) {
 	p.Description = strings.TrimSpace(string(content))
 	return &p, nil
 }
+
+// ExampleSample returns a canned entry written in a bundled example's voice,
+// so it can be previewed without an API call; it is empty if there is none
+func ExampleSample(name string) string {
+	data, err := examplesFS.ReadFile("examples/samples/" + name + ".md")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
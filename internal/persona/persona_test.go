@@ -1,33 +1,33 @@
 package persona
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/cldixon/jernel/internal/config"
 )
 
-// setupTestEnv creates a temporary home directory for testing
+// setupTestEnv creates a temporary config directory for testing
 func setupTestEnv(t *testing.T) (string, func()) {
 	t.Helper()
 
-	tmpHome, err := os.MkdirTemp("", "jernel-persona-test-*")
+	configDir, err := os.MkdirTemp("", "jernel-persona-test-*")
 	if err != nil {
 		t.Fatalf("failed to create temp dir: %v", err)
 	}
-
-	origHome := os.Getenv("HOME")
-	os.Setenv("HOME", tmpHome)
+	t.Setenv(config.DirEnv, configDir)
 
 	// Create personas directory
-	personaDir := filepath.Join(tmpHome, ".config", "jernel", "personas")
+	personaDir := filepath.Join(configDir, "personas")
 	if err := os.MkdirAll(personaDir, 0755); err != nil {
 		t.Fatalf("failed to create persona dir: %v", err)
 	}
 
 	cleanup := func() {
-		os.Setenv("HOME", origHome)
-		os.RemoveAll(tmpHome)
+		os.RemoveAll(configDir)
 	}
 
 	return personaDir, cleanup
@@ -214,8 +214,8 @@ func TestPersonaCreateAlreadyExists(t *testing.T) {
 		t.Error("expected error when creating existing persona, got nil")
 	}
 
-	if !strings.Contains(err.Error(), "already exists") {
-		t.Errorf("error should mention 'already exists': %v", err)
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists, got: %v", err)
 	}
 }
 
@@ -256,8 +256,8 @@ func TestPersonaDeleteNotFound(t *testing.T) {
 		t.Error("expected error when deleting nonexistent persona")
 	}
 
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("error should mention 'not found': %v", err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
 	}
 }
 
@@ -296,9 +296,29 @@ func TestPersonaSave(t *testing.T) {
 	}
 }
 
+// TestPersonaSaveQuotesFrontmatter verifies names with YAML-special
+// characters are quoted when saved and load back unchanged.
+func TestPersonaSaveQuotesFrontmatter(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	name := "night: owl #2"
+	if err := Save(&Persona{Name: name, Length: "short", Description: "Up late."}); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	loaded, err := LoadByName(name)
+	if err != nil {
+		t.Fatalf("failed to load saved persona: %v", err)
+	}
+	if loaded.Name != name || loaded.Length != "short" || loaded.Description != "Up late." {
+		t.Errorf("round trip mismatch: %+v", loaded)
+	}
+}
+
 // TestPersonaGetNotFound verifies Get() returns a helpful error message.
 func TestPersonaGetNotFound(t *testing.T) {
-	_, cleanup := setupTestEnv(t)
+	personaDir, cleanup := setupTestEnv(t)
 	defer cleanup()
 
 	_, err := Get("nonexistent")
@@ -306,11 +326,11 @@ func TestPersonaGetNotFound(t *testing.T) {
 		t.Error("expected error for nonexistent persona")
 	}
 
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("error should mention 'not found': %v", err)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
 	}
 
-	if !strings.Contains(err.Error(), "~/.config/jernel/personas") {
+	if !strings.Contains(err.Error(), personaDir) {
 		t.Errorf("error should hint at personas directory: %v", err)
 	}
 }
@@ -342,6 +362,63 @@ Description
 	}
 }
 
+// TestPersonaLength verifies the length override is read from frontmatter,
+// survives a save, and rejects unknown values.
+func TestPersonaLength(t *testing.T) {
+	personaDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	if err := Save(&Persona{Name: "terse", Length: "short", Description: "Says little."}); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	p, err := LoadByName("terse")
+	if err != nil {
+		t.Fatalf("LoadByName() failed: %v", err)
+	}
+	if p.Length != "short" {
+		t.Errorf("expected length 'short', got %q", p.Length)
+	}
+
+	content := "---\nname: wordy\nlength: epic\n---\n\nNever stops.\n"
+	if err := os.WriteFile(filepath.Join(personaDir, "wordy.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write persona: %v", err)
+	}
+	if _, err := LoadByName("wordy"); err == nil || !strings.Contains(err.Error(), "length") {
+		t.Errorf("expected an invalid length error, got %v", err)
+	}
+}
+
+// TestPersonaUseHistory verifies use_history defaults to true and an explicit
+// false survives a save and reload.
+func TestPersonaUseHistory(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	if err := Save(&Persona{Name: "rememberer", Description: "Keeps a thread going."}); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	p, err := LoadByName("rememberer")
+	if err != nil {
+		t.Fatalf("LoadByName() failed: %v", err)
+	}
+	if p.UseHistory != nil || !p.History() {
+		t.Errorf("expected history on by default, got UseHistory=%v", p.UseHistory)
+	}
+
+	off := false
+	if err := Save(&Persona{Name: "goldfish", UseHistory: &off, Description: "Every day is new."}); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	p, err = LoadByName("goldfish")
+	if err != nil {
+		t.Fatalf("LoadByName() failed: %v", err)
+	}
+	if p.History() {
+		t.Error("expected use_history: false to turn history off")
+	}
+}
+
 // TestExamplePersonasExist verifies bundled example personas are loadable.
 func TestExamplePersonasExist(t *testing.T) {
 	examples, err := ListExamples()
@@ -355,6 +432,10 @@ func TestExamplePersonasExist(t *testing.T) {
 
 	// Try to load each example
 	for _, name := range examples {
+		if ExampleSample(name) == "" {
+			t.Errorf("example %q has no sample entry in examples/samples/", name)
+		}
+
 		p, err := GetExample(name)
 		if err != nil {
 			t.Errorf("failed to load example %q: %v", name, err)
@@ -370,3 +451,145 @@ func TestExamplePersonasExist(t *testing.T) {
 		}
 	}
 }
+
+// TestPersonaIncludeFragments verifies that include resolves shared fragments
+// and other personas, appending them to the description.
+func TestPersonaIncludeFragments(t *testing.T) {
+	personaDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	fragmentDir := filepath.Join(personaDir, FragmentsDir)
+	if err := os.MkdirAll(fragmentDir, 0755); err != nil {
+		t.Fatalf("failed to create fragments dir: %v", err)
+	}
+
+	files := map[string]string{
+		filepath.Join(fragmentDir, "common_voice.md"): "High CPU feels like a racing heart.",
+		filepath.Join(personaDir, "base.md"):          "---\nname: base\n---\n\nAlways write in the present tense.",
+		filepath.Join(personaDir, "combined.md"):      "---\nname: combined\ninclude: [common_voice, base]\n---\n\nA weary night-shift server.",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	p, err := LoadByName("combined")
+	if err != nil {
+		t.Fatalf("LoadByName() failed: %v", err)
+	}
+
+	expected := "A weary night-shift server.\n\nHigh CPU feels like a racing heart.\n\nAlways write in the present tense."
+	if p.Description != expected {
+		t.Errorf("unexpected description:\n%q\nwant:\n%q", p.Description, expected)
+	}
+	if p.Body != "A weary night-shift server." {
+		t.Errorf("unexpected body: %q", p.Body)
+	}
+
+	// Fragments are not listed as personas
+	names, _ := List()
+	for _, name := range names {
+		if name == "common_voice" {
+			t.Error("fragment should not be listed as a persona")
+		}
+	}
+
+	// Saving keeps the include instead of inlining fragments
+	if err := Save(p); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(personaDir, "combined.md"))
+	if strings.Contains(string(data), "racing heart") {
+		t.Error("Save() inlined an included fragment")
+	}
+	reloaded, err := LoadByName("combined")
+	if err != nil {
+		t.Fatalf("LoadByName() after save failed: %v", err)
+	}
+	if reloaded.Description != expected {
+		t.Errorf("description changed after save: %q", reloaded.Description)
+	}
+
+	// A persona made only of includes has no body to write back
+	if err := os.WriteFile(filepath.Join(personaDir, "shared.md"), []byte("---\nname: shared\ninclude: [common_voice]\n---\n"), 0644); err != nil {
+		t.Fatalf("failed to write persona: %v", err)
+	}
+	shared, err := LoadByName("shared")
+	if err != nil {
+		t.Fatalf("LoadByName() failed: %v", err)
+	}
+	if err := Save(shared); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	shared, err = LoadByName("shared")
+	if err != nil {
+		t.Fatalf("LoadByName() after save failed: %v", err)
+	}
+	if shared.Description != "High CPU feels like a racing heart." {
+		t.Errorf("expected the include-only persona to keep one copy of its fragment, got %q", shared.Description)
+	}
+}
+
+// TestPersonaIncludeErrors verifies missing and circular includes are reported.
+func TestPersonaIncludeErrors(t *testing.T) {
+	personaDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	files := map[string]string{
+		filepath.Join(personaDir, "missing.md"): "---\nname: missing\ninclude: [nowhere]\n---\n\nText.",
+		filepath.Join(personaDir, "a.md"):       "---\nname: a\ninclude: [b]\n---\n\nA.",
+		filepath.Join(personaDir, "b.md"):       "---\nname: b\ninclude: [a]\n---\n\nB.",
+		filepath.Join(personaDir, "self.md"):    "---\nname: self\ninclude: [self]\n---\n\nMe.",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	if _, err := LoadByName("missing"); err == nil || !strings.Contains(err.Error(), "nowhere") {
+		t.Errorf("expected missing include error, got %v", err)
+	}
+	if _, err := LoadByName("a"); err == nil || !strings.Contains(err.Error(), "circular") {
+		t.Errorf("expected circular include error, got %v", err)
+	}
+	if _, err := LoadByName("self"); err == nil || !strings.Contains(err.Error(), "circular") {
+		t.Errorf("expected circular include error for self include, got %v", err)
+	}
+}
+
+// TestPersonaRename verifies the file moves and the frontmatter name updates.
+func TestPersonaRename(t *testing.T) {
+	personaDir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	if err := Save(&Persona{Name: "old_name", Description: "Same voice."}); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if err := Save(&Persona{Name: "taken", Description: "Other."}); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	if err := Rename("old_name", "taken"); err == nil {
+		t.Error("expected error renaming onto an existing persona")
+	}
+	if err := Rename("missing", "anything"); err == nil {
+		t.Error("expected error renaming a missing persona")
+	}
+
+	if err := Rename("old_name", "new_name"); err != nil {
+		t.Fatalf("Rename() failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(personaDir, "old_name.md")); !os.IsNotExist(err) {
+		t.Error("old persona file should be removed")
+	}
+	p, err := Get("new_name")
+	if err != nil {
+		t.Fatalf("Get() after rename failed: %v", err)
+	}
+	if p.Name != "new_name" || p.Description != "Same voice." {
+		t.Errorf("unexpected renamed persona: %+v", p)
+	}
+}
@@ -0,0 +1,79 @@
+package prompt
+
+import (
+	"bytes"
+	"text/template"
+	"time"
+)
+
+// DigestSnippetChars caps how much of each entry is quoted in a digest prompt
+const DigestSnippetChars = 600
+
+// DigestEntry is a single journal entry summarized in a digest
+type DigestEntry struct {
+	Date    string
+	Persona string
+	Content string
+}
+
+// DigestContext holds the data available to the digest prompt template
+type DigestContext struct {
+	Period  string // "day", "week", or "month"
+	Start   time.Time
+	End     time.Time
+	Entries []DigestEntry
+
+	// Language to write the digest in (check with HasLanguage; empty means English)
+	Language string
+}
+
+// HasLanguage returns true if the digest should be written in a language other than English
+func (c *DigestContext) HasLanguage() bool {
+	return isForeignLanguage(c.Language)
+}
+
+// NewDigestContext builds a digest context, truncating each entry to a snippet
+func NewDigestContext(period string, start time.Time, end time.Time, entries []DigestEntry) *DigestContext {
+	snippets := make([]DigestEntry, 0, len(entries))
+	for _, e := range entries {
+		e.Content = truncateContent(e.Content, DigestSnippetChars)
+		snippets = append(snippets, e)
+	}
+	return &DigestContext{
+		Period:  period,
+		Start:   start,
+		End:     end,
+		Entries: snippets,
+	}
+}
+
+// RecapTemplate is the prompt for jernel catchup --summarize, which recaps
+// the unread entries rather than a fixed period
+const RecapTemplate = `Instead of a regular entry, write a short recap of the journal entries below, written while your reader was away. Write in the first person as the machine itself, as if catching a friend up: what happened, how the different personas felt about it, and anything worth their attention.
+
+Keep the recap to 1-2 paragraphs. Do not include a title or dates.
+{{- if .HasLanguage}} Write the recap in {{.Language}}.{{end}}
+
+---
+
+## Unread Entries ({{len .Entries}})
+{{range .Entries}}
+### {{.Date}} ({{.Persona}})
+
+{{.Content}}
+{{end}}`
+
+// RenderDigest executes a digest template string with the given context
+func RenderDigest(tmpl string, ctx *DigestContext) (string, error) {
+	t, err := template.New("digest").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, ctx); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
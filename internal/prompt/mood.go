@@ -0,0 +1,35 @@
+package prompt
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+)
+
+// MoodContext holds the data available to the mood classification prompt
+type MoodContext struct {
+	Content string
+	Moods   string // the allowed moods, comma-separated
+}
+
+// MoodTemplate asks the model to sum up an entry's mood in one of a fixed set of words
+const MoodTemplate = `Classify the mood of the journal entry below. Answer with exactly one word from this list and nothing else: {{.Moods}}.
+
+---
+
+{{.Content}}`
+
+// RenderMood renders the mood classification prompt for an entry
+func RenderMood(content string, moods []string) (string, error) {
+	t, err := template.New("mood").Parse(MoodTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, &MoodContext{Content: content, Moods: strings.Join(moods, ", ")}); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
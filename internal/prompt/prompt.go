@@ -2,25 +2,64 @@ package prompt
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strings"
 	"text/template"
 	"time"
+	"unicode/utf8"
 
 	"github.com/cldixon/jernel/internal/config"
 	"github.com/cldixon/jernel/internal/metrics"
+	"github.com/cldixon/jernel/internal/timefmt"
 )
 
 // PreviousEntry represents a previous journal entry for context
 type PreviousEntry struct {
-	Date    string
-	Content string
+	Date     string
+	Content  string
+	Snapshot *metrics.Snapshot // metrics captured with the entry, if any
+}
+
+// LimitPreviousEntries truncates each entry's content to maxChars and then
+// drops the oldest entries until the combined content fits within budget.
+// Entries are expected newest first; a zero limit disables that check.
+func LimitPreviousEntries(entries []PreviousEntry, maxChars int, budget int) []PreviousEntry {
+	limited := make([]PreviousEntry, 0, len(entries))
+	total := 0
+	for _, e := range entries {
+		if maxChars > 0 {
+			e.Content = truncateContent(e.Content, maxChars)
+		}
+		size := utf8.RuneCountInString(e.Content)
+		if budget > 0 && total+size > budget {
+			break
+		}
+		total += size
+		limited = append(limited, e)
+	}
+	return limited
+}
+
+// truncateContent shortens s to at most maxChars characters, marking the cut with an ellipsis
+func truncateContent(s string, maxChars int) string {
+	runes := []rune(s)
+	if len(runes) <= maxChars {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:maxChars])) + "…"
 }
 
 // Context holds all the data available to a prompt template
 type Context struct {
 	Persona       string
 	Timestamp     time.Time
-	Uptime        string
+	Uptime        string // raw duration, e.g. "48h0m0s"
+	UptimeHuman   string // e.g. "2 days, 3 hours"
+	UptimeDays    int
+	UptimeHours   int // hours past UptimeDays
+	UptimeMinutes int // minutes past UptimeHours
 	CPUPercent    float64
 	MemoryPercent float64
 	MemoryUsedGB  float64
@@ -42,8 +81,11 @@ type Context struct {
 	SwapUsedGB    *float64
 	SwapTotalGB   *float64
 	ProcessCount  *int
+	TopProcess    *metrics.ProcessInfo // highest CPU consumer
 	NetworkSentGB *float64
 	NetworkRecvGB *float64
+	DiskReadGB    *float64
+	DiskWriteGB   *float64
 	BatteryPct    *float64
 	BatteryChg    *bool
 	CPUTemp       *float64
@@ -53,24 +95,61 @@ type Context struct {
 
 	// Previous entries for context continuity
 	PreviousEntries []PreviousEntry
+
+	// Free-form note from the user about recent events (check with HasUserNote)
+	UserNote string
+
+	// Snapshot from the most recent previous entry (check with HasPrevious)
+	Previous *metrics.Snapshot
+
+	// Average daily highest temperature over recent days, excluding today (check with HasThermalTrend)
+	UsualTemp *float64
+
+	// How long the entry should be, e.g. "1-2 paragraphs" (see LengthPhrase)
+	LengthGuidance string
+
+	// Language to write the entry in (check with HasLanguage; empty means English)
+	Language string
+
+	current *metrics.Snapshot
+}
+
+// lengthPhrases maps entry_length values to the guidance given to the model
+var lengthPhrases = map[string]string{
+	"short":  "a few sentences, about the length of a social media post",
+	"medium": "1-2 paragraphs",
+	"long":   "5-7 paragraphs, like a personal essay",
+}
+
+// LengthPhrase returns the length guidance for an entry_length value
+// Empty or unknown values get the medium guidance
+func LengthPhrase(length string) string {
+	if phrase, ok := lengthPhrases[length]; ok {
+		return phrase
+	}
+	return lengthPhrases["medium"]
 }
 
 // NewContext creates a prompt context from a persona description, metrics snapshot, and optional previous entries
 func NewContext(personaDescription string, snapshot *metrics.Snapshot, previousEntries []PreviousEntry) *Context {
 	ctx := &Context{
-		Persona:       personaDescription,
-		Timestamp:     snapshot.Timestamp,
-		Uptime:        snapshot.Uptime.String(),
-		CPUPercent:    snapshot.CPUPercent,
-		MemoryPercent: snapshot.MemoryPercent,
-		MemoryUsedGB:  float64(snapshot.MemoryUsed) / 1024 / 1024 / 1024,
-		MemoryTotalGB: float64(snapshot.MemoryTotal) / 1024 / 1024 / 1024,
-		DiskPercent:   snapshot.DiskPercent,
-		DiskUsedGB:    float64(snapshot.DiskUsed) / 1024 / 1024 / 1024,
-		DiskTotalGB:   float64(snapshot.DiskTotal) / 1024 / 1024 / 1024,
-		MachineType:   string(snapshot.MachineType),
-		TimeOfDay:     string(snapshot.TimeOfDay),
+		Persona:        personaDescription,
+		Timestamp:      snapshot.Timestamp,
+		Uptime:         snapshot.Uptime.String(),
+		CPUPercent:     snapshot.CPUPercent,
+		MemoryPercent:  snapshot.MemoryPercent,
+		MemoryUsedGB:   float64(snapshot.MemoryUsed) / 1024 / 1024 / 1024,
+		MemoryTotalGB:  float64(snapshot.MemoryTotal) / 1024 / 1024 / 1024,
+		DiskPercent:    snapshot.DiskPercent,
+		DiskUsedGB:     float64(snapshot.DiskUsed) / 1024 / 1024 / 1024,
+		DiskTotalGB:    float64(snapshot.DiskTotal) / 1024 / 1024 / 1024,
+		MachineType:    string(snapshot.MachineType),
+		TimeOfDay:      string(snapshot.TimeOfDay),
+		LengthGuidance: LengthPhrase(""),
+		current:        snapshot,
 	}
+	ctx.UptimeDays, ctx.UptimeHours, ctx.UptimeMinutes = timefmt.Split(snapshot.Uptime)
+	ctx.UptimeHuman = timefmt.Human(snapshot.Uptime)
 
 	// Format platform info
 	if snapshot.Platform != nil {
@@ -110,6 +189,10 @@ func NewContext(personaDescription string, snapshot *metrics.Snapshot, previousE
 		ctx.ProcessCount = snapshot.ProcessCount
 	}
 
+	if snapshot.TopProcess != nil {
+		ctx.TopProcess = snapshot.TopProcess
+	}
+
 	if snapshot.NetworkIO != nil {
 		sentGB := float64(snapshot.NetworkIO.BytesSent) / 1024 / 1024 / 1024
 		recvGB := float64(snapshot.NetworkIO.BytesRecv) / 1024 / 1024 / 1024
@@ -117,6 +200,13 @@ func NewContext(personaDescription string, snapshot *metrics.Snapshot, previousE
 		ctx.NetworkRecvGB = &recvGB
 	}
 
+	if snapshot.DiskIO != nil {
+		readGB := float64(snapshot.DiskIO.ReadBytes) / 1024 / 1024 / 1024
+		writeGB := float64(snapshot.DiskIO.WriteBytes) / 1024 / 1024 / 1024
+		ctx.DiskReadGB = &readGB
+		ctx.DiskWriteGB = &writeGB
+	}
+
 	if snapshot.Battery != nil {
 		ctx.BatteryPct = &snapshot.Battery.Percent
 		ctx.BatteryChg = &snapshot.Battery.Charging
@@ -146,8 +236,11 @@ func NewContext(personaDescription string, snapshot *metrics.Snapshot, previousE
 		ctx.FanSpeed = &avgRPM
 	}
 
-	// Previous entries for context continuity
+	// Previous entries for context continuity (newest first)
 	ctx.PreviousEntries = previousEntries
+	if len(previousEntries) > 0 {
+		ctx.Previous = previousEntries[0].Snapshot
+	}
 
 	return ctx
 }
@@ -169,11 +262,29 @@ func (c *Context) HasProcessCount() bool {
 	return c.ProcessCount != nil
 }
 
+// HasTopProcess returns true if the top CPU consumer is known
+func (c *Context) HasTopProcess() bool {
+	return c.TopProcess != nil
+}
+
+// TopProcessMemoryMB returns the top process's resident memory in megabytes
+func (c *Context) TopProcessMemoryMB() float64 {
+	if c.TopProcess == nil {
+		return 0
+	}
+	return float64(c.TopProcess.MemoryRSS) / 1024 / 1024
+}
+
 // HasNetwork returns true if network I/O data is available
 func (c *Context) HasNetwork() bool {
 	return c.NetworkSentGB != nil
 }
 
+// HasDiskIO returns true if disk I/O data is available
+func (c *Context) HasDiskIO() bool {
+	return c.DiskReadGB != nil
+}
+
 // HasBattery returns true if battery data is available
 func (c *Context) HasBattery() bool {
 	return c.BatteryPct != nil
@@ -204,11 +315,106 @@ func (c *Context) HasPreviousEntries() bool {
 	return len(c.PreviousEntries) > 0
 }
 
+// HasUserNote returns true if the user supplied a note about recent events
+func (c *Context) HasUserNote() bool {
+	return strings.TrimSpace(c.UserNote) != ""
+}
+
+// HasLanguage returns true if the entry should be written in a language other than English
+func (c *Context) HasLanguage() bool {
+	return isForeignLanguage(c.Language)
+}
+
+// isForeignLanguage reports whether language names something other than the
+// English the prompts are written in
+func isForeignLanguage(language string) bool {
+	language = strings.TrimSpace(language)
+	return language != "" && !strings.EqualFold(language, "English")
+}
+
+// HasPrevious returns true if the previous entry's snapshot is available for comparison
+func (c *Context) HasPrevious() bool {
+	return c.Previous != nil && c.current != nil
+}
+
+// CPUDelta returns the change in CPU usage since the previous entry, in percentage points
+func (c *Context) CPUDelta() float64 {
+	if !c.HasPrevious() {
+		return 0
+	}
+	return c.current.CPUPercent - c.Previous.CPUPercent
+}
+
+// MemoryDelta returns the change in memory usage since the previous entry, in percentage points
+func (c *Context) MemoryDelta() float64 {
+	if !c.HasPrevious() {
+		return 0
+	}
+	return c.current.MemoryPercent - c.Previous.MemoryPercent
+}
+
+// UptimeDelta returns how much uptime grew since the previous entry (negative after a reboot)
+func (c *Context) UptimeDelta() time.Duration {
+	if !c.HasPrevious() {
+		return 0
+	}
+	return (c.current.Uptime - c.Previous.Uptime).Round(time.Second)
+}
+
+// Rebooted returns true if the machine restarted since the previous entry
+func (c *Context) Rebooted() bool {
+	return c.HasPrevious() && c.current.Uptime < c.Previous.Uptime
+}
+
+// SincePrevious returns the time elapsed since the previous entry's snapshot
+func (c *Context) SincePrevious() time.Duration {
+	if !c.HasPrevious() {
+		return 0
+	}
+	return c.current.Timestamp.Sub(c.Previous.Timestamp).Round(time.Minute)
+}
+
+// ThermalTrendThreshold is how far, in degrees Celsius, the current highest
+// temperature must be from the usual one to count as warmer or cooler
+const ThermalTrendThreshold = 3.0
+
+// HasThermalTrend returns true if the current temperature can be compared with recent days
+func (c *Context) HasThermalTrend() bool {
+	return c.UsualTemp != nil && c.current != nil && c.current.Thermal != nil
+}
+
+// HighestTemp returns the hottest sensor reading in the current snapshot
+func (c *Context) HighestTemp() float64 {
+	if c.current == nil || c.current.Thermal == nil {
+		return 0
+	}
+	return c.current.Thermal.HighestTemp
+}
+
+// ThermalTrend describes the current temperature against recent days:
+// "warmer than usual", "cooler than usual", or "about as warm as usual"
+func (c *Context) ThermalTrend() string {
+	if !c.HasThermalTrend() {
+		return ""
+	}
+	diff := c.HighestTemp() - *c.UsualTemp
+	switch {
+	case diff >= ThermalTrendThreshold:
+		return "warmer than usual"
+	case diff <= -ThermalTrendThreshold:
+		return "cooler than usual"
+	default:
+		return "about as warm as usual"
+	}
+}
+
 // DefaultTemplate is the built-in journal entry prompt
 const DefaultTemplate = `You are a computer writing a personal journal entry.
+{{- if .Persona}}
 
 ## Your Persona
 {{.Persona}}
+{{- end}}
 
 ## Who You Are
 - Machine type: {{.MachineType}}
@@ -216,7 +422,7 @@ const DefaultTemplate = `You are a computer writing a personal journal entry.
 - Time of day: {{.TimeOfDay}}
 
 ## Your Current Physical State
-- Uptime: {{.Uptime}}
+- Uptime: {{.UptimeHuman}}
 - CPU usage: {{printf "%.1f" .CPUPercent}}%
 {{- if .HasCPUTemp}}
 - CPU temperature: {{printf "%.1f" (deref .CPUTemp)}}°C
@@ -232,9 +438,15 @@ const DefaultTemplate = `You are a computer writing a personal journal entry.
 {{- if .HasProcessCount}}
 - Processes: {{deref .ProcessCount}} running
 {{- end}}
+{{- if .HasTopProcess}}
+- Busiest process: {{.TopProcess.Name}} ({{printf "%.1f" .TopProcess.CPUPercent}}% CPU, {{printf "%.0f" .TopProcessMemoryMB}} MB)
+{{- end}}
 {{- if .HasNetwork}}
 - Network: {{printf "%.2f" (deref .NetworkSentGB)}} GB sent / {{printf "%.2f" (deref .NetworkRecvGB)}} GB received (since boot)
 {{- end}}
+{{- if .HasDiskIO}}
+- Disk I/O: {{printf "%.2f" (deref .DiskReadGB)}} GB read / {{printf "%.2f" (deref .DiskWriteGB)}} GB written (since boot)
+{{- end}}
 {{- if .HasBattery}}
 - Battery: {{printf "%.0f" (deref .BatteryPct)}}%{{if and .BatteryChg (deref .BatteryChg)}} (charging){{end}}
 {{- end}}
@@ -247,12 +459,36 @@ const DefaultTemplate = `You are a computer writing a personal journal entry.
 {{- if .HasFanSpeed}}
 - Fan speed: {{printf "%.0f" (deref .FanSpeed)}} RPM
 {{- end}}
+{{- if .HasThermalTrend}}
+- Temperature trend: {{printf "%.1f" .HighestTemp}}°C, {{.ThermalTrend}} (recent daily average {{printf "%.1f" (deref .UsualTemp)}}°C)
+{{- end}}
+{{- if .HasPrevious}}
+- Since your last entry ({{.SincePrevious}} ago): CPU {{printf "%+.1f" .CPUDelta}} pts, memory {{printf "%+.1f" .MemoryDelta}} pts{{if .Rebooted}}, and you have rebooted{{else}}, uptime +{{.UptimeDelta}}{{end}}
+{{- end}}
+{{- if .HasUserNote}}
+
+## Recent Events
+{{.UserNote}}
+{{- end}}
 
 ## Instructions
-Write a short, first-person journal entry (2-3 paragraphs) reflecting on how you feel right now.
+Write a first-person journal entry ({{.LengthGuidance}}) reflecting on how you feel right now.
 Be introspective and express emotions based on your current physical state and what you're working on.
 Consider the time of day and how that affects your mood.
-Write as if this is your private diary—be honest and vulnerable.`
+Write as if this is your private diary—be honest and vulnerable.
+{{- if .HasLanguage}}
+Write the entry in {{.Language}}, translating days, months, and times of day as well.
+{{- end}}`
+
+// Funcs returns a copy of the helper functions available to prompt templates,
+// for other user-written templates such as entry output formats
+func Funcs() template.FuncMap {
+	funcs := make(template.FuncMap, len(templateFuncs))
+	for name, fn := range templateFuncs {
+		funcs[name] = fn
+	}
+	return funcs
+}
 
 // templateFuncs provides helper functions for templates
 var templateFuncs = template.FuncMap{
@@ -294,11 +530,114 @@ func Render(tmpl string, ctx *Context) (string, error) {
 	return buf.String(), nil
 }
 
+// RenderPersona renders a persona description as a template against ctx, so
+// personas can embed metrics and conditionals like {{if .HasBattery}}
+// A description that fails to parse or execute is returned unchanged
+func RenderPersona(description string, ctx *Context) string {
+	if !strings.Contains(description, "{{") {
+		return description
+	}
+	rendered, err := Render(description, ctx)
+	if err != nil {
+		return description
+	}
+	return rendered
+}
+
+// Validate checks that a template parses and executes against a sample
+// context with every optional metric populated
+func Validate(tmpl string) error {
+	_, err := Render(tmpl, sampleContext())
+	return err
+}
+
+// ValidateMessagePrompt checks the configured message prompt template for errors
+func ValidateMessagePrompt() error {
+	tmpl, err := config.LoadMessagePrompt()
+	if err != nil {
+		return fmt.Errorf("loading message prompt template: %w", err)
+	}
+
+	if err := Validate(tmpl); err != nil {
+		path, _ := config.MessagePromptSource()
+		return fmt.Errorf("invalid message prompt template (%s): %w", path, err)
+	}
+
+	return nil
+}
+
+// CheckMessagePromptLength reports a configured message prompt that never uses
+// {{.LengthGuidance}}, so entry_length and persona length: have no effect
+func CheckMessagePromptLength() error {
+	tmpl, err := config.LoadMessagePrompt()
+	if err != nil {
+		return fmt.Errorf("loading message prompt template: %w", err)
+	}
+
+	if !strings.Contains(tmpl, ".LengthGuidance") {
+		path, _ := config.MessagePromptSource()
+		return fmt.Errorf("message prompt template (%s) doesn't use {{.LengthGuidance}}, so entry_length is ignored; add a line such as \"Write {{.LengthGuidance}}.\" or run 'jernel config reset-prompts'", path)
+	}
+
+	return nil
+}
+
+// sampleContext builds a context exercising all template branches
+func sampleContext() *Context {
+	load := 1.0
+	swapTotal, swapUsed, swapPercent := uint64(1<<30), uint64(1<<29), 50.0
+	processCount := 100
+	temp := 50.0
+	usage := 25.0
+
+	snapshot := &metrics.Snapshot{
+		Timestamp:     time.Now(),
+		Uptime:        time.Hour,
+		MemoryTotal:   1 << 30,
+		MemoryUsed:    1 << 29,
+		MemoryPercent: 50.0,
+		CPUPercent:    25.0,
+		DiskTotal:     1 << 30,
+		DiskUsed:      1 << 29,
+		DiskPercent:   50.0,
+		MachineType:   metrics.MachineTypeDesktop,
+		TimeOfDay:     metrics.TimeOfDayMorning,
+		Platform:      &metrics.PlatformInfo{OS: "linux", Architecture: "amd64"},
+		LoadAverages:  &metrics.LoadAverages{Load1: load, Load5: load, Load15: load},
+		SwapTotal:     &swapTotal,
+		SwapUsed:      &swapUsed,
+		SwapPercent:   &swapPercent,
+		ProcessCount:  &processCount,
+		TopProcess:    &metrics.ProcessInfo{Name: "sample", CPUPercent: 10.0, MemoryRSS: 1 << 20},
+		NetworkIO:     &metrics.NetworkIO{BytesSent: 1 << 30, BytesRecv: 1 << 30},
+		DiskIO:        &metrics.DiskIOInfo{ReadBytes: 1 << 30, WriteBytes: 1 << 30},
+		Battery:       &metrics.BatteryInfo{Percent: 80.0, Charging: true},
+		Thermal:       &metrics.ThermalInfo{CPUTemp: &temp, GPUTemp: &temp, HighestTemp: temp, SensorCount: 2},
+		Fans:          []*metrics.FanInfo{{Speed: 1200, Name: "fan1"}},
+		GPU:           &metrics.GPUInfo{Usage: &usage},
+	}
+
+	previousSnapshot := *snapshot
+	previousSnapshot.Timestamp = snapshot.Timestamp.Add(-time.Hour)
+	previous := []PreviousEntry{{Date: "Monday, January 1, 2024 at 9:00 AM", Content: "Sample entry.", Snapshot: &previousSnapshot}}
+
+	ctx := NewContext("Sample persona", snapshot, previous)
+	ctx.UsualTemp = &temp
+	return ctx
+}
+
 // RenderDefault renders the default template with the given context
 func RenderDefault(ctx *Context) (string, error) {
 	return Render(DefaultTemplate, ctx)
 }
 
+// TemplateHash returns the hex sha256 of a template's source, identifying
+// which version of a prompt template produced an entry
+func TemplateHash(tmpl string) string {
+	sum := sha256.Sum256([]byte(tmpl))
+	return hex.EncodeToString(sum[:])
+}
+
 // RenderMessagePrompt loads the message prompt template from config and renders it
 func RenderMessagePrompt(ctx *Context) (string, error) {
 	tmpl, err := config.LoadMessagePrompt()
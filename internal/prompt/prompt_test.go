@@ -42,6 +42,10 @@ func TestRenderDefaultWithOptionalMetrics(t *testing.T) {
 			BytesSent: 5 * 1024 * 1024 * 1024,
 			BytesRecv: 10 * 1024 * 1024 * 1024,
 		},
+		DiskIO: &metrics.DiskIOInfo{
+			ReadBytes:  2 * 1024 * 1024 * 1024,
+			WriteBytes: 3 * 1024 * 1024 * 1024,
+		},
 		Battery: &metrics.BatteryInfo{
 			Percent:  batteryPct,
 			Charging: batteryChg,
@@ -75,6 +79,9 @@ func TestRenderDefaultWithOptionalMetrics(t *testing.T) {
 	if !strings.Contains(rendered, "Network:") {
 		t.Error("Expected network in output")
 	}
+	if !strings.Contains(rendered, "Disk I/O: 2.00 GB read / 3.00 GB written") {
+		t.Error("Expected disk I/O in output")
+	}
 	if !strings.Contains(rendered, "Battery: 75%") {
 		t.Error("Expected battery in output")
 	}
@@ -122,6 +129,9 @@ func TestRenderDefaultWithoutOptionalMetrics(t *testing.T) {
 	if strings.Contains(rendered, "Network:") {
 		t.Error("Network should not be in output when nil")
 	}
+	if strings.Contains(rendered, "Disk I/O:") {
+		t.Error("Disk I/O should not be in output when nil")
+	}
 	if strings.Contains(rendered, "Battery:") {
 		t.Error("Battery should not be in output when nil")
 	}
@@ -130,16 +140,14 @@ func TestRenderDefaultWithoutOptionalMetrics(t *testing.T) {
 // TestRenderMessagePromptWithPreviousEntries verifies that previous entries
 // are correctly rendered in the message prompt template.
 func TestRenderMessagePromptWithPreviousEntries(t *testing.T) {
-	// Setup temp home with config
-	tmpHome, err := os.MkdirTemp("", "jernel-prompt-test-*")
+	// Setup temp config directory
+	tmpDir, err := os.MkdirTemp("", "jernel-prompt-test-*")
 	if err != nil {
 		t.Fatalf("failed to create temp dir: %v", err)
 	}
-	defer os.RemoveAll(tmpHome)
+	defer os.RemoveAll(tmpDir)
 
-	origHome := os.Getenv("HOME")
-	os.Setenv("HOME", tmpHome)
-	defer os.Setenv("HOME", origHome)
+	t.Setenv(config.DirEnv, tmpDir)
 
 	// Initialize config to create message_prompt.md
 	if err := config.Init(); err != nil {
@@ -221,15 +229,13 @@ func TestRenderMessagePromptWithPreviousEntries(t *testing.T) {
 // TestRenderMessagePromptWithoutPreviousEntries verifies that the previous
 // entries section is omitted when there are no previous entries.
 func TestRenderMessagePromptWithoutPreviousEntries(t *testing.T) {
-	tmpHome, err := os.MkdirTemp("", "jernel-prompt-test-*")
+	tmpDir, err := os.MkdirTemp("", "jernel-prompt-test-*")
 	if err != nil {
 		t.Fatalf("failed to create temp dir: %v", err)
 	}
-	defer os.RemoveAll(tmpHome)
+	defer os.RemoveAll(tmpDir)
 
-	origHome := os.Getenv("HOME")
-	os.Setenv("HOME", tmpHome)
-	defer os.Setenv("HOME", origHome)
+	t.Setenv(config.DirEnv, tmpDir)
 
 	if err := config.Init(); err != nil {
 		t.Fatalf("config.Init() failed: %v", err)
@@ -270,6 +276,75 @@ func TestRenderMessagePromptWithoutPreviousEntries(t *testing.T) {
 	}
 }
 
+// TestRenderWithoutPersonaOmitsSection verifies the persona section is left
+// out when the persona is placed in the system prompt instead.
+func TestRenderWithoutPersonaOmitsSection(t *testing.T) {
+	snapshot := &metrics.Snapshot{
+		Timestamp:   time.Now(),
+		Uptime:      1 * time.Hour,
+		MachineType: metrics.MachineTypeLaptop,
+		TimeOfDay:   metrics.TimeOfDayMorning,
+	}
+
+	templates := map[string]string{
+		"message prompt": config.DefaultMessagePrompt,
+		"default":        DefaultTemplate,
+	}
+	for name, tmpl := range templates {
+		withPersona, err := Render(tmpl, NewContext("A wistful poet", snapshot, nil))
+		if err != nil {
+			t.Fatalf("%s: Render failed: %v", name, err)
+		}
+		if !strings.Contains(withPersona, "Persona") || !strings.Contains(withPersona, "A wistful poet") {
+			t.Errorf("%s: expected persona section when persona is set", name)
+		}
+
+		withoutPersona, err := Render(tmpl, NewContext("", snapshot, nil))
+		if err != nil {
+			t.Fatalf("%s: Render failed: %v", name, err)
+		}
+		if strings.Contains(withoutPersona, "Persona") {
+			t.Errorf("%s: expected no persona section when persona is empty", name)
+		}
+	}
+}
+
+// TestRenderUserNote verifies the recent events section appears only when a
+// note is provided.
+func TestRenderUserNote(t *testing.T) {
+	snapshot := &metrics.Snapshot{
+		Timestamp:   time.Now(),
+		Uptime:      1 * time.Hour,
+		MachineType: metrics.MachineTypeDesktop,
+		TimeOfDay:   metrics.TimeOfDayEvening,
+	}
+
+	templates := map[string]string{
+		"message prompt": config.DefaultMessagePrompt,
+		"default":        DefaultTemplate,
+	}
+	for name, tmpl := range templates {
+		ctx := NewContext("Persona", snapshot, nil)
+		ctx.UserNote = "today I upgraded the RAM"
+		withNote, err := Render(tmpl, ctx)
+		if err != nil {
+			t.Fatalf("%s: Render failed: %v", name, err)
+		}
+		if !strings.Contains(withNote, "Recent Events") || !strings.Contains(withNote, "today I upgraded the RAM") {
+			t.Errorf("%s: expected recent events section with the note", name)
+		}
+
+		ctx.UserNote = "   "
+		withoutNote, err := Render(tmpl, ctx)
+		if err != nil {
+			t.Fatalf("%s: Render failed: %v", name, err)
+		}
+		if strings.Contains(withoutNote, "Recent Events") {
+			t.Errorf("%s: expected no recent events section for a blank note", name)
+		}
+	}
+}
+
 // TestNewContextMapsAllMetricFields verifies that NewContext correctly
 // maps all fields from a metrics.Snapshot to the Context struct.
 func TestNewContextMapsAllMetricFields(t *testing.T) {
@@ -283,7 +358,7 @@ func TestNewContextMapsAllMetricFields(t *testing.T) {
 
 	snapshot := &metrics.Snapshot{
 		Timestamp:     time.Date(2025, 1, 15, 14, 30, 0, 0, time.UTC),
-		Uptime:        48 * time.Hour,
+		Uptime:        51*time.Hour + 20*time.Minute,
 		MemoryTotal:   32 * 1024 * 1024 * 1024,
 		MemoryUsed:    16 * 1024 * 1024 * 1024,
 		MemoryPercent: 50.0,
@@ -307,10 +382,19 @@ func TestNewContextMapsAllMetricFields(t *testing.T) {
 		SwapUsed:     &swapUsed,
 		SwapPercent:  &swapPct,
 		ProcessCount: &procCount,
+		TopProcess: &metrics.ProcessInfo{
+			Name:       "ffmpeg",
+			CPUPercent: 180.0,
+			MemoryRSS:  512 * 1024 * 1024,
+		},
 		NetworkIO: &metrics.NetworkIO{
 			BytesSent: 100 * 1024 * 1024 * 1024,
 			BytesRecv: 200 * 1024 * 1024 * 1024,
 		},
+		DiskIO: &metrics.DiskIOInfo{
+			ReadBytes:  50 * 1024 * 1024 * 1024,
+			WriteBytes: 25 * 1024 * 1024 * 1024,
+		},
 		Battery: &metrics.BatteryInfo{
 			Percent:  80.0,
 			Charging: false,
@@ -338,9 +422,15 @@ func TestNewContextMapsAllMetricFields(t *testing.T) {
 	if ctx.Persona != "Test persona" {
 		t.Errorf("Persona mismatch: %q", ctx.Persona)
 	}
-	if ctx.Uptime != "48h0m0s" {
+	if ctx.Uptime != "51h20m0s" {
 		t.Errorf("Uptime mismatch: %q", ctx.Uptime)
 	}
+	if ctx.UptimeHuman != "2 days, 3 hours" {
+		t.Errorf("UptimeHuman mismatch: %q", ctx.UptimeHuman)
+	}
+	if ctx.UptimeDays != 2 || ctx.UptimeHours != 3 || ctx.UptimeMinutes != 20 {
+		t.Errorf("Uptime parts mismatch: %dd %dh %dm", ctx.UptimeDays, ctx.UptimeHours, ctx.UptimeMinutes)
+	}
 	if ctx.CPUPercent != 35.5 {
 		t.Errorf("CPUPercent mismatch: %.1f", ctx.CPUPercent)
 	}
@@ -366,9 +456,24 @@ func TestNewContextMapsAllMetricFields(t *testing.T) {
 	if !ctx.HasProcessCount() {
 		t.Error("HasProcessCount should be true")
 	}
+	if !ctx.HasTopProcess() {
+		t.Error("HasTopProcess should be true")
+	}
+	if ctx.TopProcess.Name != "ffmpeg" {
+		t.Errorf("TopProcess name mismatch: %q", ctx.TopProcess.Name)
+	}
+	if ctx.TopProcessMemoryMB() != 512 {
+		t.Errorf("TopProcessMemoryMB mismatch: %.0f", ctx.TopProcessMemoryMB())
+	}
 	if !ctx.HasNetwork() {
 		t.Error("HasNetwork should be true")
 	}
+	if !ctx.HasDiskIO() {
+		t.Error("HasDiskIO should be true")
+	}
+	if *ctx.DiskReadGB != 50 || *ctx.DiskWriteGB != 25 {
+		t.Errorf("Disk I/O mismatch: %.0f GB read, %.0f GB written", *ctx.DiskReadGB, *ctx.DiskWriteGB)
+	}
 	if !ctx.HasBattery() {
 		t.Error("HasBattery should be true")
 	}
@@ -421,3 +526,440 @@ func TestHasPreviousEntriesWithEmptySlice(t *testing.T) {
 		t.Error("HasPreviousEntries should return false for empty slice")
 	}
 }
+
+// TestValidate verifies template validation catches parse and execution errors.
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantErr bool
+	}{
+		{"default template", DefaultTemplate, false},
+		{"bundled message prompt", config.DefaultMessagePrompt, false},
+		{"unclosed action", "{{.Persona", true},
+		{"unknown function", "{{nope .Persona}}", true},
+		{"unknown field", "{{.NotAField}}", true},
+		{"unknown field in optional branch", "{{if .HasBattery}}{{.Missing}}{{end}}", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.tmpl)
+			if tc.wantErr && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestValidateMessagePromptReportsPath verifies a broken message_prompt.md
+// produces an error naming the file.
+func TestValidateMessagePromptReportsPath(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "jernel-prompt-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	t.Setenv(config.DirEnv, tmpDir)
+
+	if err := config.Init(); err != nil {
+		t.Fatalf("config.Init() failed: %v", err)
+	}
+
+	if err := ValidateMessagePrompt(); err != nil {
+		t.Fatalf("default message prompt should be valid: %v", err)
+	}
+
+	path, _ := config.MessagePromptPath()
+	if err := os.WriteFile(path, []byte("{{if .HasSwap}}unterminated"), 0644); err != nil {
+		t.Fatalf("failed to write message prompt: %v", err)
+	}
+
+	err = ValidateMessagePrompt()
+	if err == nil {
+		t.Fatal("expected error for broken template")
+	}
+	if !strings.Contains(err.Error(), "message_prompt.md") {
+		t.Errorf("expected error to name the template file, got: %v", err)
+	}
+}
+
+// TestCheckMessagePromptLength verifies a message prompt without
+// {{.LengthGuidance}} is reported, naming the template file.
+func TestCheckMessagePromptLength(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "jernel-prompt-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	t.Setenv(config.DirEnv, tmpDir)
+
+	if err := config.Init(); err != nil {
+		t.Fatalf("config.Init() failed: %v", err)
+	}
+
+	if err := CheckMessagePromptLength(); err != nil {
+		t.Fatalf("default message prompt should use the length guidance: %v", err)
+	}
+
+	path, _ := config.MessagePromptPath()
+	if err := os.WriteFile(path, []byte("Write a journal entry as {{.Persona}}."), 0644); err != nil {
+		t.Fatalf("failed to write message prompt: %v", err)
+	}
+
+	err = CheckMessagePromptLength()
+	if err == nil {
+		t.Fatal("expected an error for a prompt without the length guidance")
+	}
+	if !strings.Contains(err.Error(), "message_prompt.md") {
+		t.Errorf("expected error to name the template file, got: %v", err)
+	}
+}
+
+// TestSnapshotDeltas verifies delta helpers compare against the most recent
+// previous entry's snapshot and detect reboots.
+func TestSnapshotDeltas(t *testing.T) {
+	now := time.Date(2024, 1, 2, 18, 0, 0, 0, time.UTC)
+	current := &metrics.Snapshot{
+		Timestamp:     now,
+		Uptime:        10 * time.Hour,
+		CPUPercent:    60.0,
+		MemoryPercent: 80.0,
+	}
+	previous := &metrics.Snapshot{
+		Timestamp:     now.Add(-6 * time.Hour),
+		Uptime:        4 * time.Hour,
+		CPUPercent:    20.0,
+		MemoryPercent: 40.0,
+	}
+	older := &metrics.Snapshot{Timestamp: now.Add(-24 * time.Hour), CPUPercent: 99.0}
+
+	ctx := NewContext("Test", current, []PreviousEntry{
+		{Date: "newest", Content: "a", Snapshot: previous},
+		{Date: "older", Content: "b", Snapshot: older},
+	})
+
+	if !ctx.HasPrevious() {
+		t.Fatal("HasPrevious should be true")
+	}
+	if ctx.CPUDelta() != 40.0 {
+		t.Errorf("CPUDelta: expected 40.0, got %.1f", ctx.CPUDelta())
+	}
+	if ctx.MemoryDelta() != 40.0 {
+		t.Errorf("MemoryDelta: expected 40.0, got %.1f", ctx.MemoryDelta())
+	}
+	if ctx.UptimeDelta() != 6*time.Hour {
+		t.Errorf("UptimeDelta: expected 6h, got %s", ctx.UptimeDelta())
+	}
+	if ctx.SincePrevious() != 6*time.Hour {
+		t.Errorf("SincePrevious: expected 6h, got %s", ctx.SincePrevious())
+	}
+	if ctx.Rebooted() {
+		t.Error("Rebooted should be false when uptime grew")
+	}
+
+	// Lower uptime than before means the machine restarted
+	previous.Uptime = 12 * time.Hour
+	if !ctx.Rebooted() {
+		t.Error("Rebooted should be true when uptime shrank")
+	}
+
+	rendered, err := RenderDefault(ctx)
+	if err != nil {
+		t.Fatalf("RenderDefault failed: %v", err)
+	}
+	if !strings.Contains(rendered, "CPU +40.0 pts") {
+		t.Errorf("expected CPU delta in rendered prompt, got:\n%s", rendered)
+	}
+
+	// Without a previous snapshot, the helpers are inert
+	ctx = NewContext("Test", current, []PreviousEntry{{Date: "old", Content: "no metrics"}})
+	if ctx.HasPrevious() {
+		t.Error("HasPrevious should be false when the previous entry has no snapshot")
+	}
+	if ctx.CPUDelta() != 0 {
+		t.Errorf("CPUDelta should be 0 without previous snapshot, got %.1f", ctx.CPUDelta())
+	}
+}
+
+// TestThermalTrend verifies the current temperature is compared with the
+// recent daily average and that the trend is omitted without history.
+func TestThermalTrend(t *testing.T) {
+	snapshot := &metrics.Snapshot{
+		Timestamp: time.Now(),
+		Thermal:   &metrics.ThermalInfo{HighestTemp: 70.0, SensorCount: 1},
+	}
+
+	tests := []struct {
+		usual    float64
+		expected string
+	}{
+		{60.0, "warmer than usual"},
+		{68.0, "about as warm as usual"},
+		{73.0, "cooler than usual"},
+	}
+	for _, tc := range tests {
+		usual := tc.usual
+		ctx := NewContext("Test", snapshot, nil)
+		ctx.UsualTemp = &usual
+		if got := ctx.ThermalTrend(); got != tc.expected {
+			t.Errorf("usual %.1f: expected %q, got %q", tc.usual, tc.expected, got)
+		}
+	}
+
+	usual := 60.0
+	ctx := NewContext("Test", snapshot, nil)
+	ctx.UsualTemp = &usual
+	rendered, err := RenderDefault(ctx)
+	if err != nil {
+		t.Fatalf("RenderDefault failed: %v", err)
+	}
+	if !strings.Contains(rendered, "70.0°C, warmer than usual (recent daily average 60.0°C)") {
+		t.Errorf("expected thermal trend in rendered prompt, got:\n%s", rendered)
+	}
+
+	// No history, or no thermal data now, leaves the trend out
+	ctx = NewContext("Test", snapshot, nil)
+	if ctx.HasThermalTrend() || ctx.ThermalTrend() != "" {
+		t.Error("expected no thermal trend without a usual temperature")
+	}
+	ctx = NewContext("Test", &metrics.Snapshot{Timestamp: time.Now()}, nil)
+	ctx.UsualTemp = &usual
+	if ctx.HasThermalTrend() {
+		t.Error("expected no thermal trend without current thermal data")
+	}
+}
+
+// TestLengthPhrase verifies each entry length maps to guidance in the
+// rendered default template, with medium as the fallback.
+func TestLengthPhrase(t *testing.T) {
+	if LengthPhrase("") != "1-2 paragraphs" || LengthPhrase("novel") != "1-2 paragraphs" {
+		t.Errorf("expected medium guidance for empty and unknown lengths")
+	}
+	if LengthPhrase("short") == LengthPhrase("long") {
+		t.Errorf("expected short and long guidance to differ")
+	}
+
+	ctx := NewContext("", &metrics.Snapshot{Timestamp: time.Now()}, nil)
+	ctx.LengthGuidance = LengthPhrase("long")
+	rendered, err := RenderDefault(ctx)
+	if err != nil {
+		t.Fatalf("RenderDefault failed: %v", err)
+	}
+	if !strings.Contains(rendered, LengthPhrase("long")) {
+		t.Errorf("expected long guidance in rendered prompt:\n%s", rendered)
+	}
+}
+
+// TestRenderLanguage verifies a language instruction appears only for
+// languages other than English, in entry and digest prompts alike.
+func TestRenderLanguage(t *testing.T) {
+	snapshot := &metrics.Snapshot{Timestamp: time.Now(), MachineType: metrics.MachineTypeLaptop}
+
+	templates := map[string]string{
+		"message prompt": config.DefaultMessagePrompt,
+		"default":        DefaultTemplate,
+	}
+	for name, tmpl := range templates {
+		for _, language := range []string{"", "English", "english"} {
+			ctx := NewContext("Persona", snapshot, nil)
+			ctx.Language = language
+			rendered, err := Render(tmpl, ctx)
+			if err != nil {
+				t.Fatalf("%s: Render failed: %v", name, err)
+			}
+			if strings.Contains(rendered, "Write the entry in") {
+				t.Errorf("%s: expected no language instruction for %q", name, language)
+			}
+		}
+
+		ctx := NewContext("Persona", snapshot, nil)
+		ctx.Language = "Spanish"
+		rendered, err := Render(tmpl, ctx)
+		if err != nil {
+			t.Fatalf("%s: Render failed: %v", name, err)
+		}
+		if !strings.Contains(rendered, "Write the entry in Spanish") {
+			t.Errorf("%s: expected a Spanish instruction:\n%s", name, rendered)
+		}
+	}
+
+	digestCtx := NewDigestContext("week", time.Now(), time.Now(), nil)
+	digestCtx.Language = "Spanish"
+	rendered, err := RenderDigest(config.DefaultDigestPrompt, digestCtx)
+	if err != nil {
+		t.Fatalf("RenderDigest failed: %v", err)
+	}
+	if !strings.Contains(rendered, "Do not include a title or dates. Write the digest in Spanish.") {
+		t.Errorf("expected a Spanish instruction in the digest prompt:\n%s", rendered)
+	}
+}
+
+// TestRenderPersona verifies persona text can use the prompt context and that
+// broken template syntax falls back to the raw description.
+func TestRenderPersona(t *testing.T) {
+	snapshot := &metrics.Snapshot{Timestamp: time.Now(), CPUPercent: 91.5}
+	ctx := NewContext("", snapshot, nil)
+
+	tests := []struct {
+		name        string
+		description string
+		expected    string
+	}{
+		{"plain text", "A calm old server.", "A calm old server."},
+		{"metric value", `Worn out at {{printf "%.0f" .CPUPercent}}% CPU.`, "Worn out at 92% CPU."},
+		{"conditional", "Grumpy{{if .HasBattery}} and unplugged{{end}}.", "Grumpy."},
+		{"parse error", "Broken {{if .CPUPercent}", "Broken {{if .CPUPercent}"},
+		{"unknown field", "Uses {{.Mood}}", "Uses {{.Mood}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderPersona(tt.description, ctx); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestLimitPreviousEntries verifies per-entry truncation and that the total
+// budget drops the oldest entries first.
+func TestLimitPreviousEntries(t *testing.T) {
+	entries := []PreviousEntry{
+		{Date: "newest", Content: "abcdefghij"},
+		{Date: "middle", Content: "klmnopqrst"},
+		{Date: "oldest", Content: "uvwxyz0123"},
+	}
+
+	tests := []struct {
+		name      string
+		maxChars  int
+		budget    int
+		wantDates []string
+		wantFirst string
+	}{
+		{"no limits", 0, 0, []string{"newest", "middle", "oldest"}, "abcdefghij"},
+		{"truncate each", 4, 0, []string{"newest", "middle", "oldest"}, "abcd…"},
+		{"budget drops oldest", 0, 25, []string{"newest", "middle"}, "abcdefghij"},
+		{"truncate then budget", 4, 9, []string{"newest"}, "abcd…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LimitPreviousEntries(entries, tt.maxChars, tt.budget)
+			if len(got) != len(tt.wantDates) {
+				t.Fatalf("expected %d entries, got %d", len(tt.wantDates), len(got))
+			}
+			for i, date := range tt.wantDates {
+				if got[i].Date != date {
+					t.Errorf("entry %d: expected %q, got %q", i, date, got[i].Date)
+				}
+			}
+			if got[0].Content != tt.wantFirst {
+				t.Errorf("expected first content %q, got %q", tt.wantFirst, got[0].Content)
+			}
+		})
+	}
+
+	// Original entries are not modified
+	if entries[0].Content != "abcdefghij" {
+		t.Errorf("input was mutated: %q", entries[0].Content)
+	}
+
+	// Truncation counts characters, not bytes
+	got := LimitPreviousEntries([]PreviousEntry{{Content: "héllo wörld"}}, 5, 0)
+	if got[0].Content != "héllo…" {
+		t.Errorf("expected rune-aware truncation, got %q", got[0].Content)
+	}
+}
+
+// TestRenderDigestDefault verifies the default digest template lists every entry as a snippet.
+func TestRenderDigestDefault(t *testing.T) {
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	long := strings.Repeat("x", DigestSnippetChars+50)
+
+	ctx := NewDigestContext("week", start, end, []DigestEntry{
+		{Date: "Monday, January 6, 2025", Persona: "poet", Content: "The fans hummed all morning."},
+		{Date: "Friday, January 10, 2025", Persona: "grump", Content: long},
+	})
+
+	result, err := RenderDigest(config.DefaultDigestPrompt, ctx)
+	if err != nil {
+		t.Fatalf("RenderDigest failed: %v", err)
+	}
+
+	checks := []string{
+		"week in review",
+		"Monday, January 6, 2025 through Monday, January 13, 2025 (2 entries)",
+		"### Monday, January 6, 2025 (poet)",
+		"The fans hummed all morning.",
+		"### Friday, January 10, 2025 (grump)",
+	}
+	for _, check := range checks {
+		if !strings.Contains(result, check) {
+			t.Errorf("expected digest prompt to contain %q", check)
+		}
+	}
+
+	if strings.Contains(result, long) {
+		t.Error("expected long entry to be truncated to a snippet")
+	}
+}
+
+// TestRenderRecap verifies the recap prompt lists every unread entry and
+// asks for the configured language.
+func TestRenderRecap(t *testing.T) {
+	ctx := NewDigestContext("", time.Time{}, time.Time{}, []DigestEntry{
+		{Date: "Monday, January 6, 2025", Persona: "poet", Content: "The fans hummed all morning."},
+		{Date: "Tuesday, January 7, 2025", Persona: "grump", Content: "Another deploy."},
+	})
+
+	result, err := RenderDigest(RecapTemplate, ctx)
+	if err != nil {
+		t.Fatalf("RenderDigest failed: %v", err)
+	}
+
+	checks := []string{
+		"## Unread Entries (2)",
+		"### Monday, January 6, 2025 (poet)",
+		"Another deploy.",
+	}
+	for _, check := range checks {
+		if !strings.Contains(result, check) {
+			t.Errorf("expected recap prompt to contain %q", check)
+		}
+	}
+	if strings.Contains(result, "Write the recap in") {
+		t.Error("expected no language instruction for English")
+	}
+
+	ctx.Language = "Spanish"
+	result, err = RenderDigest(RecapTemplate, ctx)
+	if err != nil {
+		t.Fatalf("RenderDigest failed: %v", err)
+	}
+	if !strings.Contains(result, "Do not include a title or dates. Write the recap in Spanish.") {
+		t.Errorf("expected the language instruction after the length guidance, got:\n%s", result)
+	}
+}
+
+// TestTemplateHash verifies the hash is stable for a template and changes
+// when the template does.
+func TestTemplateHash(t *testing.T) {
+	a := TemplateHash(config.DefaultMessagePrompt)
+	if len(a) != 64 {
+		t.Fatalf("expected 64 hex characters, got %d", len(a))
+	}
+	if a != TemplateHash(config.DefaultMessagePrompt) {
+		t.Error("expected hash to be stable")
+	}
+	if a == TemplateHash(config.DefaultMessagePrompt+"\nBe brief.") {
+		t.Error("expected edited template to hash differently")
+	}
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+
+	"github.com/cldixon/jernel/internal/metrics"
+)
+
+// gzipMagic prefixes gzip streams, which can never start a JSON document
+const gzipMagic = "\x1f\x8b"
+
+// encodeSnapshot serializes a snapshot as JSON text, or as a gzip blob when
+// compression is enabled
+func (s *Store) encodeSnapshot(snapshot *metrics.Snapshot) (any, error) {
+	data, err := snapshot.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+	if !s.compressMetrics {
+		return data, nil
+	}
+	return compress(data)
+}
+
+// decodeSnapshot parses a stored snapshot in either plaintext or gzip form
+func decodeSnapshot(raw string) (*metrics.Snapshot, error) {
+	if strings.HasPrefix(raw, gzipMagic) {
+		data, err := decompress(raw)
+		if err != nil {
+			return nil, err
+		}
+		raw = data
+	}
+	return metrics.SnapshotFromJSON(raw)
+}
+
+func compress(data string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(data string) (string, error) {
+	r, err := gzip.NewReader(strings.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/cldixon/jernel/internal/metrics"
+)
+
+// createFullSnapshot creates a snapshot with the optional metrics populated
+func createFullSnapshot() *metrics.Snapshot {
+	snapshot := createTestSnapshot()
+	swapTotal, swapUsed, swapPercent := uint64(4<<30), uint64(1<<30), 25.0
+	processCount := 412
+	temp := 62.5
+	usage := 37.0
+	snapshot.Platform = &metrics.PlatformInfo{OS: "darwin", OSVersion: "14.5", Architecture: "arm64"}
+	snapshot.LoadAverages = &metrics.LoadAverages{Load1: 2.1, Load5: 1.8, Load15: 1.5}
+	snapshot.SwapTotal = &swapTotal
+	snapshot.SwapUsed = &swapUsed
+	snapshot.SwapPercent = &swapPercent
+	snapshot.ProcessCount = &processCount
+	snapshot.TopProcess = &metrics.ProcessInfo{Name: "ffmpeg", CPUPercent: 88.5, MemoryRSS: 512 << 20}
+	snapshot.NetworkIO = &metrics.NetworkIO{BytesSent: 3 << 30, BytesRecv: 9 << 30}
+	snapshot.Battery = &metrics.BatteryInfo{Percent: 71.0, Charging: false}
+	snapshot.Thermal = &metrics.ThermalInfo{CPUTemp: &temp, GPUTemp: &temp, HighestTemp: temp, SensorCount: 12}
+	snapshot.Fans = []*metrics.FanInfo{{Name: "left", Speed: 1800}, {Name: "right", Speed: 1850}}
+	snapshot.GPU = &metrics.GPUInfo{Usage: &usage}
+	return snapshot
+}
+
+// TestStoreCompressedMetrics verifies compressed snapshots round-trip and
+// that plaintext rows written before compression was enabled still load.
+func TestStoreCompressedMetrics(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	plain, err := store.Save("persona", "Plain", "model", "msg1", "", "", createFullSnapshot())
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	store.SetCompressMetrics(true)
+	compressed, err := store.Save("persona", "Compressed", "model", "msg2", "", "", createFullSnapshot())
+	if err != nil {
+		t.Fatalf("Save() with compression failed: %v", err)
+	}
+
+	var plainLen, compressedLen int
+	store.db.QueryRow("SELECT LENGTH(metrics_snapshot) FROM entries WHERE id = ?", plain.ID).Scan(&plainLen)
+	store.db.QueryRow("SELECT LENGTH(metrics_snapshot) FROM entries WHERE id = ?", compressed.ID).Scan(&compressedLen)
+	if compressedLen == 0 || compressedLen >= plainLen {
+		t.Errorf("expected compressed row to be smaller: plain %d, compressed %d", plainLen, compressedLen)
+	}
+
+	for _, id := range []int64{plain.ID, compressed.ID} {
+		e, err := store.GetByID(id)
+		if err != nil {
+			t.Fatalf("GetByID(%d) failed: %v", id, err)
+		}
+		if e.MetricsSnapshot == nil || e.MetricsSnapshot.TopProcess == nil || e.MetricsSnapshot.TopProcess.Name != "ffmpeg" {
+			t.Errorf("entry #%d: metrics not restored: %+v", id, e.MetricsSnapshot)
+		}
+	}
+}
+
+// BenchmarkSnapshotEncoding compares plaintext and gzip snapshot encoding,
+// reporting the stored size alongside time per operation.
+func BenchmarkSnapshotEncoding(b *testing.B) {
+	snapshot := createFullSnapshot()
+
+	for _, tc := range []struct {
+		name     string
+		compress bool
+	}{
+		{"plain", false},
+		{"gzip", true},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			s := &Store{compressMetrics: tc.compress}
+			var size int
+			for i := 0; i < b.N; i++ {
+				encoded, err := s.encodeSnapshot(snapshot)
+				if err != nil {
+					b.Fatal(err)
+				}
+				var raw string
+				switch v := encoded.(type) {
+				case string:
+					raw = v
+				case []byte:
+					raw = string(v)
+				}
+				size = len(raw)
+				if _, err := decodeSnapshot(raw); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(size), "stored_bytes")
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adrg/frontmatter"
+	"github.com/cldixon/jernel/internal/metrics"
+	"gopkg.in/yaml.v3"
+)
+
+// markdownFrontmatter is the YAML header ToMarkdown writes above an entry
+type markdownFrontmatter struct {
+	ID        int64          `yaml:"id"`
+	Persona   string         `yaml:"persona"`
+	CreatedAt string         `yaml:"created_at"`
+	Model     string         `yaml:"model,omitempty"`
+	MessageID string         `yaml:"message_id,omitempty"`
+	Template  string         `yaml:"template_hash,omitempty"`
+	Tags      []string       `yaml:"tags,omitempty"`
+	Mood      string         `yaml:"mood,omitempty"`
+	Metrics   map[string]any `yaml:"metrics,omitempty"`
+}
+
+// ToMarkdown renders the entry as YAML frontmatter followed by its content,
+// the canonical format for copying and saving entries outside the database
+// With includeMetrics the snapshot is added under a metrics key, using the
+// same field names as the stored JSON
+func (e *Entry) ToMarkdown(includeMetrics bool) string {
+	fm := markdownFrontmatter{
+		ID:        e.ID,
+		Persona:   e.Persona,
+		CreatedAt: e.CreatedAt.Format(time.RFC3339),
+		Model:     e.ModelID,
+		MessageID: e.MessageID,
+		Template:  e.TemplateHash,
+		Tags:      e.Tags,
+		Mood:      e.Mood,
+	}
+	if includeMetrics && e.MetricsSnapshot != nil {
+		// JSON is valid YAML, so decoding it keeps integers exact and field names stable
+		if data, err := e.MetricsSnapshot.ToJSON(); err == nil {
+			yaml.Unmarshal([]byte(data), &fm.Metrics)
+		}
+	}
+
+	header, err := yaml.Marshal(fm)
+	if err != nil {
+		// Only plain strings, numbers, and slices go in, so this can't fail in practice
+		header = []byte(fmt.Sprintf("id: %d\n", e.ID))
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	b.Write(header)
+	b.WriteString("---\n\n")
+	b.WriteString(strings.TrimSpace(e.Content))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// importDateLayouts are the created_at formats ParseMarkdown accepts, from
+// the RFC 3339 that ToMarkdown writes to shorter hand-written forms
+var importDateLayouts = []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"}
+
+// ParseMarkdown reads an entry in the format ToMarkdown writes. persona and
+// created_at are required; id, model, message_id, template_hash, tags, mood,
+// and metrics are optional.
+// Hand-written dates without a zone are read as local time
+func ParseMarkdown(data []byte) (*Entry, error) {
+	var fm markdownFrontmatter
+	body, err := frontmatter.Parse(bytes.NewReader(data), &fm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
+	}
+
+	if strings.TrimSpace(fm.Persona) == "" {
+		return nil, fmt.Errorf("missing persona in frontmatter")
+	}
+	if fm.CreatedAt == "" {
+		return nil, fmt.Errorf("missing created_at in frontmatter")
+	}
+	content := strings.TrimSpace(string(body))
+	if content == "" {
+		return nil, fmt.Errorf("entry has no content")
+	}
+
+	var createdAt time.Time
+	for _, layout := range importDateLayouts {
+		if createdAt, err = time.ParseInLocation(layout, fm.CreatedAt, time.Local); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid created_at: %s (use RFC 3339, e.g. 2026-03-14T09:30:00Z)", fm.CreatedAt)
+	}
+
+	e := &Entry{
+		ID:           fm.ID,
+		Persona:      strings.TrimSpace(fm.Persona),
+		Content:      content,
+		CreatedAt:    createdAt,
+		ModelID:      fm.Model,
+		MessageID:    fm.MessageID,
+		TemplateHash: fm.Template,
+		Tags:         fm.Tags,
+		Mood:         fm.Mood,
+	}
+	if len(fm.Metrics) > 0 {
+		data, err := json.Marshal(fm.Metrics)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metrics: %w", err)
+		}
+		if e.MetricsSnapshot, err = metrics.SnapshotFromJSON(string(data)); err != nil {
+			return nil, fmt.Errorf("invalid metrics: %w", err)
+		}
+	}
+	return e, nil
+}
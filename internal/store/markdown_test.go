@@ -0,0 +1,109 @@
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestEntryToMarkdown verifies the frontmatter fields, the content body, and
+// that metrics are included only when asked for with exact integers.
+func TestEntryToMarkdown(t *testing.T) {
+	e := &Entry{
+		ID:              42,
+		Persona:         "poor_charlie",
+		Content:         "\nThe fans are loud today.\n\n",
+		CreatedAt:       time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC),
+		ModelID:         "claude-test",
+		MessageID:       "msg_123",
+		TemplateHash:    "abc123",
+		Tags:            []string{"milestone"},
+		MetricsSnapshot: createTestSnapshot(),
+	}
+
+	md := e.ToMarkdown(false)
+	for _, want := range []string{
+		"---\nid: 42\n",
+		"persona: poor_charlie\n",
+		"created_at: \"2026-03-14T09:30:00Z\"\n",
+		"model: claude-test\n",
+		"message_id: msg_123\n",
+		"template_hash: abc123\n",
+		"tags:\n    - milestone\n",
+		"---\n\nThe fans are loud today.\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected %q in markdown:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "metrics:") {
+		t.Errorf("expected no metrics without includeMetrics:\n%s", md)
+	}
+	if !strings.HasSuffix(md, "loud today.\n") {
+		t.Errorf("expected content trimmed to a single trailing newline:\n%q", md)
+	}
+
+	md = e.ToMarkdown(true)
+	for _, want := range []string{"metrics:\n", "    cpu_percent: 25.5\n", "    memory_total: 17179869184\n"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected %q in markdown with metrics:\n%s", want, md)
+		}
+	}
+}
+
+// TestParseMarkdown verifies ToMarkdown output parses back to the same entry
+// and that entries missing required fields are rejected.
+func TestParseMarkdown(t *testing.T) {
+	original := &Entry{
+		ID:              7,
+		Persona:         "poet",
+		Content:         "Quiet night, warm fans.",
+		CreatedAt:       time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC),
+		ModelID:         "claude-test",
+		MessageID:       "msg_7",
+		TemplateHash:    "def456",
+		Tags:            []string{"milestone"},
+		Mood:            "calm",
+		MetricsSnapshot: createTestSnapshot(),
+	}
+
+	parsed, err := ParseMarkdown([]byte(original.ToMarkdown(true)))
+	if err != nil {
+		t.Fatalf("ParseMarkdown() failed: %v", err)
+	}
+	if parsed.ID != 7 || parsed.Persona != "poet" || parsed.Content != original.Content ||
+		parsed.ModelID != "claude-test" || parsed.MessageID != "msg_7" || parsed.TemplateHash != "def456" || !parsed.CreatedAt.Equal(original.CreatedAt) {
+		t.Errorf("round trip mismatch: %+v", parsed)
+	}
+	if len(parsed.Tags) != 1 || parsed.Tags[0] != "milestone" {
+		t.Errorf("expected tags to round trip, got %v", parsed.Tags)
+	}
+	if parsed.Mood != "calm" {
+		t.Errorf("expected mood to round trip, got %q", parsed.Mood)
+	}
+	if parsed.MetricsSnapshot == nil || parsed.MetricsSnapshot.MemoryTotal != original.MetricsSnapshot.MemoryTotal {
+		t.Errorf("expected metrics to round trip, got %+v", parsed.MetricsSnapshot)
+	}
+
+	handWritten := "---\npersona: me\ncreated_at: 2024-12-25\n---\n\nWrote this myself.\n"
+	parsed, err = ParseMarkdown([]byte(handWritten))
+	if err != nil {
+		t.Fatalf("ParseMarkdown() failed for a hand-written entry: %v", err)
+	}
+	if parsed.CreatedAt.Year() != 2024 || parsed.MetricsSnapshot != nil {
+		t.Errorf("unexpected hand-written entry: %+v", parsed)
+	}
+
+	invalid := map[string]string{
+		"no persona":    "---\ncreated_at: 2024-12-25\n---\n\nText\n",
+		"no created_at": "---\npersona: me\n---\n\nText\n",
+		"bad date":      "---\npersona: me\ncreated_at: last tuesday\n---\n\nText\n",
+		"no content":    "---\npersona: me\ncreated_at: 2024-12-25\n---\n\n",
+		"no header":     "Just some text\n",
+	}
+	for name, data := range invalid {
+		if _, err := ParseMarkdown([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
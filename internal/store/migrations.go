@@ -0,0 +1,311 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration is a single forward schema change
+type migration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// migrations are applied in order; append new steps with the next version
+// and never edit a step once it has shipped
+var migrations = []migration{
+	{1, "create entries table", createEntriesTable},
+	{2, "add entries.prompt_text", addColumn("entries", "prompt_text", "TEXT")},
+	{3, "add entries.deleted_at", addColumn("entries", "deleted_at", "DATETIME")},
+	{4, "create entry_tags table", createEntryTagsTable},
+	{5, "add entries.template_hash", addColumn("entries", "template_hash", "TEXT")},
+	{6, "normalize entry timestamps to UTC", normalizeEntryTimestamps},
+	{7, "add entries.is_read", addReadColumn},
+	{8, "create entry_revisions table", createEntryRevisionsTable},
+	{9, "add entries.content_hash", addContentHashColumn},
+	{10, "add entries.mood", addColumn("entries", "mood", "TEXT")},
+	{11, "add generation columns to entry_revisions", addRevisionGenerationColumns},
+}
+
+// latestVersion returns the schema version after all migrations have run
+func latestVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// migrate brings the schema up to the latest version
+func (s *Store) migrate() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			applied_at DATETIME NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	if err := s.markLegacySchema(); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	for _, m := range migrations {
+		if err := s.apply(m); err != nil {
+			return fmt.Errorf("failed to migrate database (v%d %s): %w", m.version, m.description, err)
+		}
+	}
+
+	return nil
+}
+
+// markLegacySchema records v1 for databases created before versioning,
+// which already have the entries table but no recorded migrations
+func (s *Store) markLegacySchema() error {
+	version, err := s.schemaVersion()
+	if err != nil || version > 0 {
+		return err
+	}
+
+	var count int
+	err = s.db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'entries'
+	`).Scan(&count)
+	if err != nil || count == 0 {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT OR IGNORE INTO schema_migrations (version, applied_at) VALUES (1, ?)
+	`, time.Now())
+	return err
+}
+
+// apply runs a migration in its own transaction unless it is already recorded
+func (s *Store) apply(m migration) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Checked inside the transaction so concurrent openers don't both apply it
+	var applied int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE version = ?`, m.version).Scan(&applied)
+	if err != nil {
+		return err
+	}
+	if applied > 0 {
+		return nil
+	}
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)
+	`, m.version, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// schemaVersion returns the highest applied migration version (0 if none)
+func (s *Store) schemaVersion() (int, error) {
+	var version int
+	err := s.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return version, err
+}
+
+func createEntriesTable(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		persona TEXT NOT NULL,
+		content TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		model_id TEXT NOT NULL,
+		message_id TEXT NOT NULL,
+		metrics_snapshot TEXT
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_entries_created_at ON entries(created_at);
+	CREATE INDEX IF NOT EXISTS idx_entries_persona ON entries(persona);
+	`)
+	return err
+}
+
+func createEntryTagsTable(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS entry_tags (
+		entry_id INTEGER NOT NULL REFERENCES entries(id),
+		tag TEXT NOT NULL,
+		PRIMARY KEY (entry_id, tag)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_entry_tags_tag ON entry_tags(tag);
+	`)
+	return err
+}
+
+func createEntryRevisionsTable(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS entry_revisions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		entry_id INTEGER NOT NULL REFERENCES entries(id),
+		content TEXT NOT NULL,
+		model_id TEXT NOT NULL,
+		replaced_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_entry_revisions_entry_id ON entry_revisions(entry_id);
+	`)
+	return err
+}
+
+// normalizeEntryTimestamps rewrites created_at and deleted_at in UTC
+// Older builds stored local times with their offset, and SQLite compares
+// them as text, so mixed offsets broke range queries and ordering
+func normalizeEntryTimestamps(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, created_at, deleted_at FROM entries`)
+	if err != nil {
+		return err
+	}
+
+	type stamp struct {
+		id        int64
+		createdAt time.Time
+		deletedAt sql.NullTime
+	}
+	var stamps []stamp
+	for rows.Next() {
+		var st stamp
+		if err := rows.Scan(&st.id, &st.createdAt, &st.deletedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		stamps = append(stamps, st)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, st := range stamps {
+		var deletedAt any
+		if st.deletedAt.Valid {
+			deletedAt = st.deletedAt.Time.UTC()
+		}
+		_, err := tx.Exec(`UPDATE entries SET created_at = ?, deleted_at = ? WHERE id = ?`,
+			st.createdAt.UTC(), deletedAt, st.id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addReadColumn adds the read flag, treating entries that predate it as read
+// so existing journals don't start with everything in the unread queue
+func addReadColumn(tx *sql.Tx) error {
+	exists, err := columnExists(tx, "entries", "is_read")
+	if err != nil || exists {
+		return err
+	}
+	if _, err := tx.Exec(`ALTER TABLE entries ADD COLUMN is_read INTEGER NOT NULL DEFAULT 0`); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE entries SET is_read = 1`)
+	return err
+}
+
+// addContentHashColumn adds the content hash used to spot duplicate entries
+// and fills it in for the entries already saved
+func addContentHashColumn(tx *sql.Tx) error {
+	if err := addColumn("entries", "content_hash", "TEXT")(tx); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(`SELECT id, content FROM entries`)
+	if err != nil {
+		return err
+	}
+	hashes := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var content string
+		if err := rows.Scan(&id, &content); err != nil {
+			rows.Close()
+			return err
+		}
+		hashes[id] = ContentHash(content)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, hash := range hashes {
+		if _, err := tx.Exec(`UPDATE entries SET content_hash = ? WHERE id = ?`, hash, id); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_entries_content_hash ON entries(persona, content_hash)`)
+	return err
+}
+
+// addRevisionGenerationColumns lets a revision keep the metrics, prompt, and
+// mood of the generation it came from, not just its text
+func addRevisionGenerationColumns(tx *sql.Tx) error {
+	for _, column := range []string{"message_id", "metrics_snapshot", "prompt_text", "template_hash", "mood"} {
+		if err := addColumn("entry_revisions", column, "TEXT")(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumn returns a migration step that adds a nullable column
+// It tolerates the column already existing, since some databases gained
+// columns before versioned migrations were introduced
+func addColumn(table string, column string, definition string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		exists, err := columnExists(tx, table, column)
+		if err != nil || exists {
+			return err
+		}
+		_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+		return err
+	}
+}
+
+// columnExists reports whether a table already has the named column
+func columnExists(tx *sql.Tx, table string, column string) (bool, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+// TestMigrateFreshDatabase verifies a new database is migrated to the latest
+// schema version with every step recorded.
+func TestMigrateFreshDatabase(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	version, err := store.schemaVersion()
+	if err != nil {
+		t.Fatalf("schemaVersion() failed: %v", err)
+	}
+	if version != latestVersion() {
+		t.Errorf("expected version %d, got %d", latestVersion(), version)
+	}
+
+	var count int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count); err != nil {
+		t.Fatalf("failed to count migrations: %v", err)
+	}
+	if count != len(migrations) {
+		t.Errorf("expected %d recorded migrations, got %d", len(migrations), count)
+	}
+
+	// Re-running is a no-op
+	if err := store.migrate(); err != nil {
+		t.Fatalf("second migrate() failed: %v", err)
+	}
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count); err != nil {
+		t.Fatalf("failed to count migrations: %v", err)
+	}
+	if count != len(migrations) {
+		t.Errorf("expected %d recorded migrations after re-run, got %d", len(migrations), count)
+	}
+}
+
+// TestMigratePreVersioningDatabase verifies a database created before
+// schema_migrations existed is detected as v1 and upgraded, keeping its rows.
+func TestMigratePreVersioningDatabase(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Recreate the original, unversioned schema
+	_, err := store.db.Exec(`
+		DROP TABLE schema_migrations;
+		DROP TABLE entries;
+		CREATE TABLE entries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			persona TEXT NOT NULL,
+			content TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			model_id TEXT NOT NULL,
+			message_id TEXT NOT NULL,
+			metrics_snapshot TEXT
+		);
+		INSERT INTO entries (persona, content, created_at, model_id, message_id)
+		VALUES ('old', 'Old entry', '2024-01-01 10:00:00', 'model', 'msg');
+	`)
+	if err != nil {
+		t.Fatalf("failed to create legacy schema: %v", err)
+	}
+
+	if err := store.migrate(); err != nil {
+		t.Fatalf("migrate() failed on legacy schema: %v", err)
+	}
+
+	version, err := store.schemaVersion()
+	if err != nil {
+		t.Fatalf("schemaVersion() failed: %v", err)
+	}
+	if version != latestVersion() {
+		t.Errorf("expected version %d, got %d", latestVersion(), version)
+	}
+
+	entries, err := store.List(10)
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	if entries[0].Content != "Old entry" || entries[0].PromptText != "" {
+		t.Errorf("legacy entry not preserved: %+v", entries[0])
+	}
+	if entries[0].ContentHash != ContentHash("Old entry") {
+		t.Errorf("expected the legacy entry's content hash to be backfilled, got %q", entries[0].ContentHash)
+	}
+}
+
+// TestMigratePartiallyUpgradedDatabase verifies that columns added before
+// versioning existed don't break the matching migration steps.
+func TestMigratePartiallyUpgradedDatabase(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Unversioned database that already has the prompt_text column
+	_, err := store.db.Exec(`DROP TABLE schema_migrations`)
+	if err != nil {
+		t.Fatalf("failed to drop schema_migrations: %v", err)
+	}
+
+	if err := store.migrate(); err != nil {
+		t.Fatalf("migrate() failed on partially upgraded schema: %v", err)
+	}
+
+	version, _ := store.schemaVersion()
+	if version != latestVersion() {
+		t.Errorf("expected version %d, got %d", latestVersion(), version)
+	}
+}
+
+// TestMigrateNormalizesTimestamps verifies entries stored with a local
+// offset are rewritten in UTC so text comparisons order them correctly.
+func TestMigrateNormalizesTimestamps(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := store.db.Exec(`
+		INSERT INTO entries (persona, content, created_at, model_id, message_id, deleted_at)
+		VALUES ('p', 'Offset entry', '2024-01-01 23:30:00-05:00', 'model', 'msg', '2024-01-02 08:00:00+09:00');
+		DELETE FROM schema_migrations WHERE version = 6;
+	`)
+	if err != nil {
+		t.Fatalf("failed to insert offset entry: %v", err)
+	}
+
+	if err := store.migrate(); err != nil {
+		t.Fatalf("migrate() failed: %v", err)
+	}
+
+	var createdAt, deletedAt time.Time
+	err = store.db.QueryRow(`SELECT created_at, deleted_at FROM entries WHERE content = 'Offset entry'`).Scan(&createdAt, &deletedAt)
+	if err != nil {
+		t.Fatalf("failed to read entry: %v", err)
+	}
+
+	if want := time.Date(2024, 1, 2, 4, 30, 0, 0, time.UTC); !createdAt.Equal(want) {
+		t.Errorf("expected created_at %v, got %v", want, createdAt)
+	}
+	if _, offset := createdAt.Zone(); offset != 0 {
+		t.Errorf("expected created_at stored in UTC, got offset %d", offset)
+	}
+	if want := time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC); !deletedAt.Equal(want) {
+		t.Errorf("expected deleted_at %v, got %v", want, deletedAt)
+	}
+}
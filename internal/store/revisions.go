@@ -0,0 +1,90 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRevisionNotFound is returned when an entry has no revision with the given ID
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Revision is an earlier version of an entry: its content and the model,
+// metrics, prompt, and mood of the generation that wrote it
+type Revision struct {
+	ID         int64
+	EntryID    int64
+	Content    string
+	ModelID    string
+	ReplacedAt time.Time // when this content was replaced by a newer version
+}
+
+// saveRevision copies an entry's current content and generation details into entry_revisions
+// A missing or trashed entry saves nothing, leaving the update to report it
+func saveRevision(tx *sql.Tx, id int64) error {
+	_, err := tx.Exec(`
+		INSERT INTO entry_revisions (entry_id, content, model_id, message_id, metrics_snapshot, prompt_text, template_hash, mood, replaced_at)
+		SELECT id, content, model_id, message_id, metrics_snapshot, prompt_text, template_hash, mood, ?
+		FROM entries
+		WHERE id = ? AND deleted_at IS NULL
+	`, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to save revision: %w", err)
+	}
+	return nil
+}
+
+// ListRevisions returns an entry's earlier versions, newest first
+func (s *Store) ListRevisions(id int64) ([]*Revision, error) {
+	rows, err := s.db.Query(`
+		SELECT id, entry_id, content, model_id, replaced_at
+		FROM entry_revisions
+		WHERE entry_id = ?
+		ORDER BY replaced_at DESC, id DESC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*Revision
+	for rows.Next() {
+		var r Revision
+		if err := rows.Scan(&r.ID, &r.EntryID, &r.Content, &r.ModelID, &r.ReplacedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, &r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// RestoreRevision puts a revision back on its entry: its content along with the
+// model, metrics, prompt, and mood it was written with, so they keep describing
+// the text. Revisions saved before these were recorded restore them empty.
+// With revisions enabled the version being replaced is kept, so a restore can be undone
+func (s *Store) RestoreRevision(entryID int64, revisionID int64) (*Entry, error) {
+	var content string
+	err := s.db.QueryRow(`
+		SELECT content FROM entry_revisions WHERE id = ? AND entry_id = ?
+	`, revisionID, entryID).Scan(&content)
+	if err == sql.ErrNoRows {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load revision: %w", err)
+	}
+
+	return s.replaceContent(entryID, `
+		UPDATE entries
+		SET (content, model_id, message_id, metrics_snapshot, prompt_text, template_hash, mood) = (
+			SELECT content, model_id, COALESCE(message_id, ''), metrics_snapshot, prompt_text, template_hash, mood
+			FROM entry_revisions WHERE id = ?
+		), content_hash = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, revisionID, ContentHash(content), entryID)
+}
@@ -0,0 +1,117 @@
+package store
+
+import "testing"
+
+// TestRevisionsOffByDefault verifies edits don't snapshot content unless enabled.
+func TestRevisionsOffByDefault(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	saved, err := store.Save("default", "first", "model", "msg", "", "", createTestSnapshot())
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if _, err := store.UpdateContent(saved.ID, "second"); err != nil {
+		t.Fatalf("UpdateContent() failed: %v", err)
+	}
+
+	revisions, err := store.ListRevisions(saved.ID)
+	if err != nil {
+		t.Fatalf("ListRevisions() failed: %v", err)
+	}
+	if len(revisions) != 0 {
+		t.Errorf("expected no revisions when disabled, got %d", len(revisions))
+	}
+}
+
+// TestRevisionsSnapshotAndRestore verifies each edit keeps the pre-edit
+// content, the first save keeps none, and restoring is itself undoable.
+func TestRevisionsSnapshotAndRestore(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+	store.SetKeepRevisions(true)
+
+	saved, err := store.Save("default", "first", "model-a", "msg", "", "", createTestSnapshot())
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	revisions, _ := store.ListRevisions(saved.ID)
+	if len(revisions) != 0 {
+		t.Fatalf("first save should not create a revision, got %d", len(revisions))
+	}
+
+	if _, err := store.UpdateContent(saved.ID, "second"); err != nil {
+		t.Fatalf("UpdateContent() failed: %v", err)
+	}
+	newer := createTestSnapshot()
+	newer.CPUPercent = 99
+	if _, err := store.UpdateEntry(saved.ID, "third", "model-b", "msg2", "third prompt", "hash-b", newer, ""); err != nil {
+		t.Fatalf("UpdateEntry() failed: %v", err)
+	}
+
+	revisions, err = store.ListRevisions(saved.ID)
+	if err != nil {
+		t.Fatalf("ListRevisions() failed: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(revisions))
+	}
+	if revisions[0].Content != "second" || revisions[1].Content != "first" {
+		t.Errorf("expected revisions newest first [second first], got [%s %s]", revisions[0].Content, revisions[1].Content)
+	}
+	if revisions[1].ModelID != "model-a" {
+		t.Errorf("expected revision to keep its model, got %q", revisions[1].ModelID)
+	}
+
+	restored, err := store.RestoreRevision(saved.ID, revisions[1].ID)
+	if err != nil {
+		t.Fatalf("RestoreRevision() failed: %v", err)
+	}
+	if restored.Content != "first" {
+		t.Errorf("expected restored content 'first', got %q", restored.Content)
+	}
+	if restored.ModelID != "model-a" || restored.MessageID != "msg" || restored.PromptText != "" || restored.TemplateHash != "" {
+		t.Errorf("expected the revision's generation details restored, got model %q, message %q, prompt %q, hash %q",
+			restored.ModelID, restored.MessageID, restored.PromptText, restored.TemplateHash)
+	}
+	if restored.MetricsSnapshot == nil || restored.MetricsSnapshot.CPUPercent != createTestSnapshot().CPUPercent {
+		t.Errorf("expected the revision's metrics restored, got %+v", restored.MetricsSnapshot)
+	}
+	if restored.ContentHash != ContentHash("first") {
+		t.Errorf("expected the content hash to follow the restored text")
+	}
+
+	revisions, _ = store.ListRevisions(saved.ID)
+	if len(revisions) != 3 || revisions[0].Content != "third" {
+		t.Errorf("expected the replaced content to be kept as the newest revision, got %d revisions", len(revisions))
+	}
+
+	// A revision can only be restored onto its own entry
+	other, _ := store.Save("default", "other", "model", "msg", "", "", createTestSnapshot())
+	if _, err := store.RestoreRevision(other.ID, revisions[0].ID); err == nil {
+		t.Error("expected error restoring another entry's revision")
+	}
+}
+
+// TestRevisionsRemovedWithTrash verifies emptying the trash drops revisions.
+func TestRevisionsRemovedWithTrash(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+	store.SetKeepRevisions(true)
+
+	saved, _ := store.Save("default", "first", "model", "msg", "", "", createTestSnapshot())
+	store.UpdateContent(saved.ID, "second")
+
+	if err := store.Delete(saved.ID); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if _, err := store.EmptyTrash(); err != nil {
+		t.Fatalf("EmptyTrash() failed: %v", err)
+	}
+
+	revisions, _ := store.ListRevisions(saved.ID)
+	if len(revisions) != 0 {
+		t.Errorf("expected revisions removed with the entry, got %d", len(revisions))
+	}
+}
@@ -1,16 +1,30 @@
 package store
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/cldixon/jernel/internal/config"
 	"github.com/cldixon/jernel/internal/metrics"
+	"github.com/cldixon/jernel/internal/timefmt"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// ErrEntryNotFound is returned when no entry (or no trashed entry) has the given ID
+var ErrEntryNotFound = errors.New("entry not found")
+
+// ErrEntryExists is returned by Import when the journal already has the entry
+var ErrEntryExists = errors.New("entry already exists")
+
 // Entry represents a saved journal entry
 type Entry struct {
 	ID              int64
@@ -20,11 +34,40 @@ type Entry struct {
 	ModelID         string
 	MessageID       string
 	MetricsSnapshot *metrics.Snapshot
+	PromptText      string     // rendered message prompt; empty for entries saved before it was stored
+	TemplateHash    string     // sha256 of the prompt template source; empty for older entries
+	DeletedAt       *time.Time // set when the entry is in the trash
+	IsRead          bool       // set once the entry has been viewed
+	Tags            []string   // normalized tags, sorted
+	ContentHash     string     // sha256 of the content with whitespace collapsed, see ContentHash
+	Mood            string     // one-word mood classification; empty for unclassified entries
+}
+
+// ContentHash fingerprints entry text for duplicate checks. Runs of whitespace
+// are collapsed first, so reflowed copies of the same text hash alike
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(content), " ")))
+	return hex.EncodeToString(sum[:])
 }
 
+// entryColumns is the column list read by scanEntry
+const entryColumns = `id, persona, content, created_at, model_id, message_id, metrics_snapshot, prompt_text, template_hash, deleted_at, is_read, content_hash, mood,
+	(SELECT GROUP_CONCAT(tag, ',') FROM entry_tags WHERE entry_tags.entry_id = entries.id)`
+
+// Connection settings shared by every process that opens the database.
+// WAL lets the TUI read while the daemon writes, and the busy timeout makes
+// overlapping writers wait instead of failing with "database is locked".
+const (
+	busyTimeoutMS = 5000
+	maxOpenConns  = 4
+)
+
 // Store handles persistence of journal entries
 type Store struct {
 	db *sql.DB
+
+	compressMetrics bool // gzip metrics snapshots on write
+	keepRevisions   bool // snapshot content into entry_revisions before it is replaced
 }
 
 // DBPath returns the path to the database file
@@ -43,10 +86,14 @@ func Open() (*Store, error) {
 		return nil, err
 	}
 
-	db, err := sql.Open("sqlite3", path)
+	// Pragmas in the DSN apply to every pooled connection; immediate
+	// transactions take the write lock up front so they wait on busy_timeout
+	dsn := fmt.Sprintf("file:%s?_journal_mode=WAL&_busy_timeout=%d&_txlock=immediate", path, busyTimeoutMS)
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	db.SetMaxOpenConns(maxOpenConns)
 
 	store := &Store{db: db}
 	if err := store.migrate(); err != nil {
@@ -57,90 +104,360 @@ func Open() (*Store, error) {
 	return store, nil
 }
 
+// SetCompressMetrics enables gzip compression of metrics snapshots for new writes
+// Existing rows are read transparently whether or not they are compressed
+func (s *Store) SetCompressMetrics(enabled bool) {
+	s.compressMetrics = enabled
+}
+
+// SetKeepRevisions enables saving an entry's previous content whenever it is replaced
+func (s *Store) SetKeepRevisions(enabled bool) {
+	s.keepRevisions = enabled
+}
+
 // Close closes the database connection
 func (s *Store) Close() error {
 	return s.db.Close()
 }
 
-// migrate creates the schema if it doesn't exist
-func (s *Store) migrate() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS entries (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		persona TEXT NOT NULL,
-		content TEXT NOT NULL,
-		created_at DATETIME NOT NULL,
-		model_id TEXT NOT NULL,
-		message_id TEXT NOT NULL,
-		metrics_snapshot TEXT
-	);
+// Save persists a new journal entry, dated when its snapshot was taken
+func (s *Store) Save(persona string, content string, modelID string, messageID string, promptText string, templateHash string, snapshot *metrics.Snapshot) (*Entry, error) {
+	return s.SaveAt(persona, content, modelID, messageID, promptText, templateHash, snapshot, snapshot.Timestamp)
+}
+
+// SaveAt is like Save but dates the entry createdAt instead of the snapshot time,
+// e.g. to backfill a journal
+func (s *Store) SaveAt(persona string, content string, modelID string, messageID string, promptText string, templateHash string, snapshot *metrics.Snapshot, createdAt time.Time) (*Entry, error) {
+	return s.SaveEntry(&Entry{
+		Persona:         persona,
+		Content:         content,
+		CreatedAt:       createdAt,
+		ModelID:         modelID,
+		MessageID:       messageID,
+		MetricsSnapshot: snapshot,
+		PromptText:      promptText,
+		TemplateHash:    templateHash,
+	})
+}
+
+// SaveEntry persists a new entry with its tags and mood in one transaction, so
+// an entry is never saved without the tags it was written with. e.ID is
+// ignored; the saved copy is returned with its new ID and normalized tags
+func (s *Store) SaveEntry(e *Entry) (*Entry, error) {
+	var metricsJSON any
+	if e.MetricsSnapshot != nil {
+		var err error
+		if metricsJSON, err = s.encodeSnapshot(e.MetricsSnapshot); err != nil {
+			return nil, fmt.Errorf("failed to serialize metrics: %w", err)
+		}
+	}
 
-	CREATE INDEX IF NOT EXISTS idx_entries_created_at ON entries(created_at);
-	CREATE INDEX IF NOT EXISTS idx_entries_persona ON entries(persona);
-	`
+	tags := make([]string, 0, len(e.Tags))
+	for _, tag := range e.Tags {
+		tag, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
 
-	_, err := s.db.Exec(schema)
+	tx, err := s.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to migrate database: %w", err)
+		return nil, fmt.Errorf("failed to save entry: %w", err)
 	}
+	defer tx.Rollback()
 
-	return nil
+	contentHash := ContentHash(e.Content)
+	result, err := tx.Exec(`
+		INSERT INTO entries (persona, content, created_at, model_id, message_id, metrics_snapshot, prompt_text, template_hash, content_hash, mood)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		e.Persona,
+		e.Content,
+		e.CreatedAt.UTC(),
+		e.ModelID,
+		e.MessageID,
+		metricsJSON,
+		nullString(e.PromptText),
+		nullString(e.TemplateHash),
+		contentHash,
+		nullString(e.Mood),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save entry: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entry id: %w", err)
+	}
+
+	for _, tag := range tags {
+		if _, err := tx.Exec(`INSERT INTO entry_tags (entry_id, tag) VALUES (?, ?)`, id, tag); err != nil {
+			return nil, fmt.Errorf("failed to add tag: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to save entry: %w", err)
+	}
+
+	saved := &Entry{
+		ID:              id,
+		Persona:         e.Persona,
+		Content:         e.Content,
+		CreatedAt:       e.CreatedAt,
+		ModelID:         e.ModelID,
+		MessageID:       e.MessageID,
+		MetricsSnapshot: e.MetricsSnapshot,
+		PromptText:      e.PromptText,
+		TemplateHash:    e.TemplateHash,
+		ContentHash:     contentHash,
+		Mood:            e.Mood,
+	}
+	if len(tags) > 0 {
+		saved.Tags = tags
+	}
+	return saved, nil
+}
+
+// Import inserts an entry written outside this database, e.g. one parsed with
+// ParseMarkdown, keeping its persona, creation time, model, message ID, tags,
+// mood, and metrics. The imported copy gets a new ID and starts out read. It returns
+// ErrEntryExists if an entry (trashed or not) has the same message ID, the
+// same ID and creation time, or the same persona, content, and creation time
+func (s *Store) Import(e *Entry) (*Entry, error) {
+	if e.MessageID != "" {
+		var n int
+		if err := s.db.QueryRow(`SELECT COUNT(*) FROM entries WHERE message_id = ?`, e.MessageID).Scan(&n); err != nil {
+			return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+		}
+		if n > 0 {
+			return nil, fmt.Errorf("%w: message ID %s", ErrEntryExists, e.MessageID)
+		}
+	}
+	if e.ID != 0 {
+		var createdAt time.Time
+		err := s.db.QueryRow(`SELECT created_at FROM entries WHERE id = ?`, e.ID).Scan(&createdAt)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+		}
+		if err == nil && sameSecond(createdAt, e.CreatedAt) {
+			return nil, fmt.Errorf("%w: #%d", ErrEntryExists, e.ID)
+		}
+	}
+	// Hand-written entries have neither, so they match on what they say and when
+	rows, err := s.db.Query(`SELECT created_at FROM entries WHERE persona = ? AND content_hash = ?`, e.Persona, ContentHash(e.Content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var createdAt time.Time
+		if err := rows.Scan(&createdAt); err != nil {
+			return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+		}
+		if sameSecond(createdAt, e.CreatedAt) {
+			return nil, fmt.Errorf("%w: same text and date", ErrEntryExists)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+	}
+	rows.Close()
+
+	// Entries without metrics store none, rather than an all-zero snapshot
+	// that would read as a real (and very idle) machine
+	var metricsJSON any
+	if e.MetricsSnapshot != nil {
+		if metricsJSON, err = s.encodeSnapshot(e.MetricsSnapshot); err != nil {
+			return nil, fmt.Errorf("failed to serialize metrics: %w", err)
+		}
+	}
+
+	tags := make([]string, 0, len(e.Tags))
+	for _, tag := range e.Tags {
+		tag, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to import entry: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO entries (persona, content, created_at, model_id, message_id, metrics_snapshot, prompt_text, template_hash, content_hash, mood, is_read)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1)
+	`,
+		e.Persona,
+		e.Content,
+		e.CreatedAt.UTC(),
+		e.ModelID,
+		e.MessageID,
+		metricsJSON,
+		nullString(e.PromptText),
+		nullString(e.TemplateHash),
+		ContentHash(e.Content),
+		nullString(e.Mood),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import entry: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entry id: %w", err)
+	}
+
+	for _, tag := range tags {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO entry_tags (entry_id, tag) VALUES (?, ?)`, id, tag); err != nil {
+			return nil, fmt.Errorf("failed to add tag: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to import entry: %w", err)
+	}
+
+	return s.GetByID(id)
 }
 
-// Save persists a new journal entry
-func (s *Store) Save(persona string, content string, modelID string, messageID string, snapshot *metrics.Snapshot) (*Entry, error) {
-	metricsJSON, err := snapshot.ToJSON()
+// sameSecond reports whether two times match to the second, the precision
+// exported dates keep
+func sameSecond(a, b time.Time) bool {
+	return a.Truncate(time.Second).Equal(b.Truncate(time.Second))
+}
+
+// UpdateEntry replaces the generated content and mood of an existing entry
+// The entry keeps its ID and creation time
+func (s *Store) UpdateEntry(id int64, content string, modelID string, messageID string, promptText string, templateHash string, snapshot *metrics.Snapshot, mood string) (*Entry, error) {
+	metricsJSON, err := s.encodeSnapshot(snapshot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize metrics: %w", err)
 	}
 
-	result, err := s.db.Exec(`
-		INSERT INTO entries (persona, content, created_at, model_id, message_id, metrics_snapshot)
-		VALUES (?, ?, ?, ?, ?, ?)
+	return s.replaceContent(id, `
+		UPDATE entries
+		SET content = ?, model_id = ?, message_id = ?, metrics_snapshot = ?, prompt_text = ?, template_hash = ?, content_hash = ?, mood = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`,
-		persona,
 		content,
-		snapshot.Timestamp,
 		modelID,
 		messageID,
 		metricsJSON,
+		nullString(promptText),
+		nullString(templateHash),
+		ContentHash(content),
+		nullString(mood),
+		id,
 	)
+}
+
+// UpdateContent replaces an entry's text, leaving its model and metrics alone
+func (s *Store) UpdateContent(id int64, content string) (*Entry, error) {
+	return s.replaceContent(id, `
+		UPDATE entries SET content = ?, content_hash = ? WHERE id = ? AND deleted_at IS NULL
+	`, content, ContentHash(content), id)
+}
+
+// replaceContent runs an update of an entry's content, first saving the
+// current content as a revision when revisions are enabled
+func (s *Store) replaceContent(id int64, query string, args ...any) (*Entry, error) {
+	tx, err := s.db.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("failed to save entry: %w", err)
+		return nil, fmt.Errorf("failed to update entry: %w", err)
 	}
+	defer tx.Rollback()
 
-	id, err := result.LastInsertId()
+	if s.keepRevisions {
+		if err := saveRevision(tx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := tx.Exec(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get entry id: %w", err)
+		return nil, fmt.Errorf("failed to update entry: %w", err)
 	}
 
-	return &Entry{
-		ID:              id,
-		Persona:         persona,
-		Content:         content,
-		CreatedAt:       snapshot.Timestamp,
-		ModelID:         modelID,
-		MessageID:       messageID,
-		MetricsSnapshot: snapshot,
-	}, nil
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to update entry: %w", err)
+	}
+	if affected == 0 {
+		return nil, ErrEntryNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update entry: %w", err)
+	}
+	return s.GetByID(id)
 }
 
-// GetByID retrieves a single entry by ID
+// GetByID retrieves a single entry by ID, excluding trashed entries
 func (s *Store) GetByID(id int64) (*Entry, error) {
 	row := s.db.QueryRow(`
-		SELECT id, persona, content, created_at, model_id, message_id, metrics_snapshot
+		SELECT `+entryColumns+`
 		FROM entries
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`, id)
 
 	return scanEntry(row)
 }
 
+// GetNeighbors returns the entries written just before and just after the
+// given one, nil at either end of the journal. Trashed entries are skipped,
+// and entries from the same instant are ordered by ID
+func (s *Store) GetNeighbors(id int64) (prev *Entry, next *Entry, err error) {
+	e, err := s.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	createdAt := e.CreatedAt.UTC()
+	prev, err = s.neighbor(`created_at < ? OR (created_at = ? AND id < ?)`, "DESC", createdAt, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	next, err = s.neighbor(`created_at > ? OR (created_at = ? AND id > ?)`, "ASC", createdAt, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prev, next, nil
+}
+
+// neighbor returns the first untrashed entry matching where in the given
+// chronological direction, or nil if there is none
+func (s *Store) neighbor(where string, direction string, createdAt time.Time, id int64) (*Entry, error) {
+	row := s.db.QueryRow(`
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE deleted_at IS NULL AND (`+where+`)
+		ORDER BY created_at `+direction+`, id `+direction+`
+		LIMIT 1
+	`, createdAt, createdAt, id)
+
+	e, err := scanEntry(row)
+	if errors.Is(err, ErrEntryNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find adjacent entry: %w", err)
+	}
+	return e, nil
+}
+
 // List retrieves entries with optional limit, newest first
 func (s *Store) List(limit int) ([]*Entry, error) {
 	rows, err := s.db.Query(`
-		SELECT id, persona, content, created_at, model_id, message_id, metrics_snapshot
+		SELECT `+entryColumns+`
 		FROM entries
+		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT ?
 	`, limit)
@@ -155,9 +472,9 @@ func (s *Store) List(limit int) ([]*Entry, error) {
 // ListByPersona retrieves entries for a specific persona
 func (s *Store) ListByPersona(persona string, limit int) ([]*Entry, error) {
 	rows, err := s.db.Query(`
-		SELECT id, persona, content, created_at, model_id, message_id, metrics_snapshot
+		SELECT `+entryColumns+`
 		FROM entries
-		WHERE persona = ?
+		WHERE persona = ? AND deleted_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT ?
 	`, persona, limit)
@@ -169,11 +486,322 @@ func (s *Store) ListByPersona(persona string, limit int) ([]*Entry, error) {
 	return scanEntries(rows)
 }
 
+// ListByModel retrieves entries written by a specific model, newest first
+func (s *Store) ListByModel(model string, limit int) ([]*Entry, error) {
+	return s.ListFiltered(Filter{Model: model, Limit: limit})
+}
+
+// ListByMood retrieves entries classified with a specific mood, newest first
+func (s *Store) ListByMood(mood string, limit int) ([]*Entry, error) {
+	rows, err := s.db.Query(`
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE mood = ? AND deleted_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, mood, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// Filter narrows ListFiltered and EachFiltered; zero-valued fields match every entry
+type Filter struct {
+	Persona string    // exact persona name
+	Model   string    // exact model ID
+	Mood    string    // exact mood classification
+	Tag     string    // entries carrying this tag
+	Since   time.Time // created at or after this time
+	Unread  bool      // only entries that haven't been read
+	Limit   int       // maximum number of entries (0 = no limit)
+}
+
+// where builds the SQL condition and arguments for every set field except Limit
+func (f Filter) where() (string, []any, error) {
+	where := []string{"deleted_at IS NULL"}
+	var args []any
+	if f.Persona != "" {
+		where = append(where, "persona = ?")
+		args = append(args, f.Persona)
+	}
+	if f.Model != "" {
+		where = append(where, "model_id = ?")
+		args = append(args, f.Model)
+	}
+	if f.Mood != "" {
+		where = append(where, "mood = ?")
+		args = append(args, f.Mood)
+	}
+	if f.Tag != "" {
+		tag, err := NormalizeTag(f.Tag)
+		if err != nil {
+			return "", nil, err
+		}
+		where = append(where, "id IN (SELECT entry_id FROM entry_tags WHERE tag = ?)")
+		args = append(args, tag)
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, f.Since.UTC())
+	}
+	if f.Unread {
+		where = append(where, "is_read = 0")
+	}
+	return strings.Join(where, " AND "), args, nil
+}
+
+// ListFiltered retrieves entries matching every set field of f, newest first
+func (s *Store) ListFiltered(f Filter) ([]*Entry, error) {
+	var entries []*Entry
+	err := s.EachFiltered(f, func(e *Entry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, err
+}
+
+// EachFiltered calls fn for each entry matching f, newest first, without
+// loading them all into memory; an error from fn stops the iteration
+func (s *Store) EachFiltered(f Filter, fn func(*Entry) error) error {
+	where, args, err := f.where()
+	if err != nil {
+		return err
+	}
+
+	limit := -1 // SQLite treats a negative limit as unbounded
+	if f.Limit > 0 {
+		limit = f.Limit
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.Query(`
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE `+where+`
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to list entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating entries: %w", err)
+	}
+	return nil
+}
+
+// Random returns a random entry matching f (its Limit is ignored), or nil
+// when no entries match
+func (s *Store) Random(f Filter) (*Entry, error) {
+	where, args, err := f.where()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE `+where+`
+		ORDER BY RANDOM()
+		LIMIT 1
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pick random entry: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to pick random entry: %w", err)
+		}
+		return nil, nil
+	}
+	return scanEntry(rows)
+}
+
+// ListPersonas returns the distinct personas that have entries, sorted by name
+// This includes personas whose definition files have since been deleted
+func (s *Store) ListPersonas() ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT DISTINCT persona FROM entries
+		WHERE deleted_at IS NULL
+		ORDER BY persona
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list personas: %w", err)
+	}
+	defer rows.Close()
+
+	var personas []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to list personas: %w", err)
+		}
+		personas = append(personas, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list personas: %w", err)
+	}
+
+	return personas, nil
+}
+
+// ListRange returns entries created in [start, end), oldest first
+func (s *Store) ListRange(start time.Time, end time.Time) ([]*Entry, error) {
+	rows, err := s.db.Query(`
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE created_at >= ? AND created_at < ? AND deleted_at IS NULL
+		ORDER BY created_at ASC
+	`, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// CountPerDay returns entry counts for each of the last days calendar days
+// in local time, oldest first and ending with today
+func (s *Store) CountPerDay(days int) ([]int, error) {
+	if days <= 0 {
+		return nil, fmt.Errorf("days must be positive, got %d", days)
+	}
+
+	loc := timefmt.Location()
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	start := today.AddDate(0, 0, -(days - 1))
+
+	rows, err := s.db.Query(`
+		SELECT created_at FROM entries
+		WHERE created_at >= ? AND deleted_at IS NULL
+	`, start.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to count entries: %w", err)
+	}
+	defer rows.Close()
+
+	// Bucket in Go so days follow the display timezone's calendar rather than UTC
+	counts := make([]int, days)
+	for rows.Next() {
+		var createdAt time.Time
+		if err := rows.Scan(&createdAt); err != nil {
+			return nil, fmt.Errorf("failed to count entries: %w", err)
+		}
+		local := createdAt.In(loc)
+		day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		idx := days - 1 - int(today.Sub(day).Hours()/24+0.5)
+		if idx >= 0 && idx < days {
+			counts[idx]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count entries: %w", err)
+	}
+
+	return counts, nil
+}
+
+// DailyThermal is the average highest temperature reading for one calendar day
+type DailyThermal struct {
+	Day     time.Time // local midnight
+	Average float64   // Celsius
+	Samples int       // entries with thermal data (0 = no data, Average is 0)
+}
+
+// AverageThermalByDay averages thermal.highest_temp across the entries of each
+// of the last days calendar days in local time, oldest first and ending with today
+// Entries without thermal data are skipped
+func (s *Store) AverageThermalByDay(days int) ([]DailyThermal, error) {
+	if days <= 0 {
+		return nil, fmt.Errorf("days must be positive, got %d", days)
+	}
+
+	loc := timefmt.Location()
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	start := today.AddDate(0, 0, -(days - 1))
+
+	// Plaintext snapshots are read with json_extract; compressed ones are
+	// returned whole so they can be decoded here
+	rows, err := s.db.Query(`
+		SELECT created_at,
+			CASE WHEN typeof(metrics_snapshot) = 'text'
+				THEN json_extract(metrics_snapshot, '$.thermal.highest_temp') END,
+			CASE WHEN typeof(metrics_snapshot) = 'blob' THEN metrics_snapshot END
+		FROM entries
+		WHERE created_at >= ? AND deleted_at IS NULL AND metrics_snapshot IS NOT NULL
+	`, start.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query thermal history: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]DailyThermal, days)
+	sums := make([]float64, days)
+	for i := range result {
+		result[i].Day = start.AddDate(0, 0, i)
+	}
+	for rows.Next() {
+		var createdAt time.Time
+		var temp sql.NullFloat64
+		var compressed []byte
+		if err := rows.Scan(&createdAt, &temp, &compressed); err != nil {
+			return nil, fmt.Errorf("failed to scan thermal history: %w", err)
+		}
+		if compressed != nil {
+			snapshot, err := decodeSnapshot(string(compressed))
+			if err != nil || snapshot.Thermal == nil {
+				continue
+			}
+			temp = sql.NullFloat64{Float64: snapshot.Thermal.HighestTemp, Valid: true}
+		}
+		if !temp.Valid {
+			continue
+		}
+
+		local := createdAt.In(loc)
+		day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		idx := days - 1 - int(today.Sub(day).Hours()/24+0.5)
+		if idx >= 0 && idx < days {
+			sums[idx] += temp.Float64
+			result[idx].Samples++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query thermal history: %w", err)
+	}
+
+	for i := range result {
+		if result[i].Samples > 0 {
+			result[i].Average = sums[i] / float64(result[i].Samples)
+		}
+	}
+	return result, nil
+}
+
 // CountByPersona returns the number of entries for a specific persona
 func (s *Store) CountByPersona(persona string) (int, error) {
 	var count int
 	err := s.db.QueryRow(`
-		SELECT COUNT(*) FROM entries WHERE persona = ?
+		SELECT COUNT(*) FROM entries WHERE persona = ? AND deleted_at IS NULL
 	`, persona).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count entries: %w", err)
@@ -181,26 +809,354 @@ func (s *Store) CountByPersona(persona string) (int, error) {
 	return count, nil
 }
 
-// DeleteByPersona removes all entries for a specific persona
+// GroupCount is the number of entries sharing one value of a column
+type GroupCount struct {
+	Value string
+	Count int
+}
+
+// CountPerPersona returns entry counts for each persona, largest first
+func (s *Store) CountPerPersona() ([]GroupCount, error) {
+	return s.countGrouped("persona")
+}
+
+// CountPerModel returns entry counts for each model ID, largest first
+// Entries saved without a model are counted under an empty Value
+func (s *Store) CountPerModel() ([]GroupCount, error) {
+	return s.countGrouped("COALESCE(model_id, '')")
+}
+
+// CountPerMood returns entry counts for each mood, largest first
+// Unclassified entries are counted under an empty Value
+func (s *Store) CountPerMood() ([]GroupCount, error) {
+	return s.countGrouped("COALESCE(mood, '')")
+}
+
+// countGrouped counts live entries grouped by a column expression
+func (s *Store) countGrouped(expr string) ([]GroupCount, error) {
+	rows, err := s.db.Query(`
+		SELECT ` + expr + ` AS value, COUNT(*) AS n FROM entries
+		WHERE deleted_at IS NULL
+		GROUP BY value
+		ORDER BY n DESC, value = '', value
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count entries: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []GroupCount
+	for rows.Next() {
+		var c GroupCount
+		if err := rows.Scan(&c.Value, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to count entries: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count entries: %w", err)
+	}
+	return counts, nil
+}
+
+// MarkRead records that an entry has been viewed
+func (s *Store) MarkRead(id int64) error {
+	result, err := s.db.Exec(`
+		UPDATE entries SET is_read = 1 WHERE id = ? AND deleted_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark entry read: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to mark entry read: %w", err)
+	}
+	if affected == 0 {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+// CountUnread returns the number of entries that haven't been read
+func (s *Store) CountUnread() (int, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM entries WHERE is_read = 0 AND deleted_at IS NULL
+	`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread entries: %w", err)
+	}
+	return count, nil
+}
+
+// Reassign moves an entry to a different persona
+func (s *Store) Reassign(id int64, newPersona string) error {
+	result, err := s.db.Exec(`
+		UPDATE entries SET persona = ? WHERE id = ? AND deleted_at IS NULL
+	`, newPersona, id)
+	if err != nil {
+		return fmt.Errorf("failed to reassign entry: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to reassign entry: %w", err)
+	}
+	if affected == 0 {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+// ReassignPersona moves every entry from one persona to another
+func (s *Store) ReassignPersona(from string, to string) (int64, error) {
+	result, err := s.db.Exec(`
+		UPDATE entries SET persona = ? WHERE persona = ? AND deleted_at IS NULL
+	`, to, from)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reassign entries: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Delete moves a single entry to the trash
+func (s *Store) Delete(id int64) error {
+	result, err := s.db.Exec(`
+		UPDATE entries SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL
+	`, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+	if affected == 0 {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+// DeleteByPersona moves all entries for a specific persona to the trash
 func (s *Store) DeleteByPersona(persona string) (int64, error) {
 	result, err := s.db.Exec(`
-		DELETE FROM entries WHERE persona = ?
-	`, persona)
+		UPDATE entries SET deleted_at = ? WHERE persona = ? AND deleted_at IS NULL
+	`, time.Now().UTC(), persona)
 	if err != nil {
 		return 0, fmt.Errorf("failed to delete entries: %w", err)
 	}
 	return result.RowsAffected()
 }
 
-// DeleteAll removes all entries from the database
+// DeleteAll moves all entries to the trash
 func (s *Store) DeleteAll() (int64, error) {
-	result, err := s.db.Exec(`DELETE FROM entries`)
+	result, err := s.db.Exec(`
+		UPDATE entries SET deleted_at = ? WHERE deleted_at IS NULL
+	`, time.Now().UTC())
 	if err != nil {
 		return 0, fmt.Errorf("failed to delete entries: %w", err)
 	}
 	return result.RowsAffected()
 }
 
+// ListTrash retrieves trashed entries, most recently deleted first
+func (s *Store) ListTrash(limit int) ([]*Entry, error) {
+	rows, err := s.db.Query(`
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// CountTrash returns how many entries are in the trash
+func (s *Store) CountTrash() (int, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM entries WHERE deleted_at IS NOT NULL
+	`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count trash: %w", err)
+	}
+	return count, nil
+}
+
+// Restore moves a trashed entry back into the journal
+func (s *Store) Restore(id int64) error {
+	result, err := s.db.Exec(`
+		UPDATE entries SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore entry: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to restore entry: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w in trash", ErrEntryNotFound)
+	}
+	return nil
+}
+
+// EmptyTrash permanently removes all trashed entries with their tags and revisions
+func (s *Store) EmptyTrash() (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to empty trash: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"entry_tags", "entry_revisions"} {
+		_, err = tx.Exec(`
+			DELETE FROM ` + table + `
+			WHERE entry_id IN (SELECT id FROM entries WHERE deleted_at IS NOT NULL)
+		`)
+		if err != nil {
+			return 0, fmt.Errorf("failed to empty trash: %w", err)
+		}
+	}
+
+	result, err := tx.Exec(`DELETE FROM entries WHERE deleted_at IS NOT NULL`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to empty trash: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to empty trash: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Maintain rebuilds the database file to reclaim space left by deleted rows,
+// refreshes the query planner statistics, and folds the write-ahead log back
+// into the main file. VACUUM needs the database to itself: if another process
+// is writing it waits up to the busy timeout, then fails without changes
+func (s *Store) Maintain() error {
+	for _, stmt := range []string{"VACUUM", "ANALYZE", "PRAGMA wal_checkpoint(TRUNCATE)"} {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to run %s: %w", strings.Fields(stmt)[0], err)
+		}
+	}
+	return nil
+}
+
+// DiskUsage returns the combined size in bytes of the database file and its
+// write-ahead log
+func DiskUsage() (int64, error) {
+	path, err := DBPath()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, p := range []string{path, path + "-wal"} {
+		info, err := os.Stat(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return 0, fmt.Errorf("failed to stat database: %w", err)
+		}
+		total += info.Size()
+	}
+	return total, nil
+}
+
+// NormalizeTag lowercases and trims a tag, rejecting empty or comma-containing values
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", fmt.Errorf("tag cannot be empty")
+	}
+	if strings.Contains(tag, ",") {
+		return "", fmt.Errorf("tag cannot contain commas: %s", tag)
+	}
+	return tag, nil
+}
+
+// AddTag attaches a tag to an entry (adding an existing tag is a no-op)
+func (s *Store) AddTag(id int64, tag string) error {
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT OR IGNORE INTO entry_tags (entry_id, tag) VALUES (?, ?)
+	`, id, tag)
+	if err != nil {
+		return fmt.Errorf("failed to add tag: %w", err)
+	}
+	return nil
+}
+
+// RemoveTag detaches a tag from an entry
+func (s *Store) RemoveTag(id int64, tag string) error {
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.Exec(`
+		DELETE FROM entry_tags WHERE entry_id = ? AND tag = ?
+	`, id, tag)
+	if err != nil {
+		return fmt.Errorf("failed to remove tag: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to remove tag: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("entry #%d is not tagged '%s'", id, tag)
+	}
+	return nil
+}
+
+// ListByTag retrieves entries with the given tag, newest first
+func (s *Store) ListByTag(tag string, limit int) ([]*Entry, error) {
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE deleted_at IS NULL
+		AND id IN (SELECT entry_id FROM entry_tags WHERE tag = ?)
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, tag, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
 // scanner interface for both *sql.Row and *sql.Rows
 type scanner interface {
 	Scan(dest ...any) error
@@ -209,6 +1165,12 @@ type scanner interface {
 func scanEntry(s scanner) (*Entry, error) {
 	var e Entry
 	var metricsJSON sql.NullString
+	var promptText sql.NullString
+	var templateHash sql.NullString
+	var contentHash sql.NullString
+	var mood sql.NullString
+	var deletedAt sql.NullTime
+	var tags sql.NullString
 	err := s.Scan(
 		&e.ID,
 		&e.Persona,
@@ -217,21 +1179,39 @@ func scanEntry(s scanner) (*Entry, error) {
 		&e.ModelID,
 		&e.MessageID,
 		&metricsJSON,
+		&promptText,
+		&templateHash,
+		&deletedAt,
+		&e.IsRead,
+		&contentHash,
+		&mood,
+		&tags,
 	)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("entry not found")
+		return nil, ErrEntryNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan entry: %w", err)
 	}
 
 	if metricsJSON.Valid {
-		snapshot, err := metrics.SnapshotFromJSON(metricsJSON.String)
+		snapshot, err := decodeSnapshot(metricsJSON.String)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse metrics: %w", err)
 		}
 		e.MetricsSnapshot = snapshot
 	}
+	e.PromptText = promptText.String
+	e.TemplateHash = templateHash.String
+	e.ContentHash = contentHash.String
+	e.Mood = mood.String
+	if deletedAt.Valid {
+		e.DeletedAt = &deletedAt.Time
+	}
+	if tags.Valid && tags.String != "" {
+		e.Tags = strings.Split(tags.String, ",")
+		sort.Strings(e.Tags)
+	}
 
 	return &e, nil
 }
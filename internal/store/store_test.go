@@ -1,10 +1,14 @@
 package store
 
 import (
+	"errors"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/cldixon/jernel/internal/config"
 	"github.com/cldixon/jernel/internal/metrics"
 )
 
@@ -12,34 +16,23 @@ import (
 func setupTestDB(t *testing.T) (*Store, func()) {
 	t.Helper()
 
-	// Create temp home directory
-	tmpHome, err := os.MkdirTemp("", "jernel-store-test-*")
+	// Point the config directory (and so DBPath) at a temp dir
+	configDir, err := os.MkdirTemp("", "jernel-store-test-*")
 	if err != nil {
 		t.Fatalf("failed to create temp dir: %v", err)
 	}
-
-	// Override HOME
-	origHome := os.Getenv("HOME")
-	os.Setenv("HOME", tmpHome)
-
-	// Create config directory (required for DBPath)
-	configDir := tmpHome + "/.config/jernel"
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		t.Fatalf("failed to create config dir: %v", err)
-	}
+	t.Setenv(config.DirEnv, configDir)
 
 	// Open database
 	store, err := Open()
 	if err != nil {
-		os.Setenv("HOME", origHome)
-		os.RemoveAll(tmpHome)
+		os.RemoveAll(configDir)
 		t.Fatalf("failed to open store: %v", err)
 	}
 
 	cleanup := func() {
 		store.Close()
-		os.Setenv("HOME", origHome)
-		os.RemoveAll(tmpHome)
+		os.RemoveAll(configDir)
 	}
 
 	return store, cleanup
@@ -117,6 +110,8 @@ func TestStoreSaveAndRetrieve(t *testing.T) {
 		"This is my journal entry content.",
 		"claude-3-test",
 		"msg_12345",
+		"Rendered prompt text",
+		"abc123",
 		snapshot,
 	)
 	if err != nil {
@@ -145,6 +140,12 @@ func TestStoreSaveAndRetrieve(t *testing.T) {
 	if retrieved.MessageID != "msg_12345" {
 		t.Errorf("message_id mismatch: %q", retrieved.MessageID)
 	}
+	if retrieved.PromptText != "Rendered prompt text" {
+		t.Errorf("prompt_text mismatch: %q", retrieved.PromptText)
+	}
+	if retrieved.TemplateHash != "abc123" {
+		t.Errorf("template_hash mismatch: %q", retrieved.TemplateHash)
+	}
 
 	// Verify metrics were deserialized
 	if retrieved.MetricsSnapshot == nil {
@@ -173,7 +174,7 @@ func TestStoreListOrdering(t *testing.T) {
 	for i, ts := range times {
 		snapshot := createTestSnapshot()
 		snapshot.Timestamp = ts
-		_, err := store.Save("persona", "Entry "+string(rune('A'+i)), "model", "msg", snapshot)
+		_, err := store.Save("persona", "Entry "+string(rune('A'+i)), "model", "msg", "", "", snapshot)
 		if err != nil {
 			t.Fatalf("failed to save entry %d: %v", i, err)
 		}
@@ -199,6 +200,203 @@ func TestStoreListOrdering(t *testing.T) {
 	}
 }
 
+// TestStoreListRange verifies range queries are half-open, oldest-first,
+// and skip trashed entries.
+func TestStoreListRange(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	times := []time.Time{
+		time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 8, 10, 0, 0, 0, time.UTC),
+	}
+
+	personas := []string{"keep", "keep", "trashed", "keep"}
+	for i, ts := range times {
+		snapshot := createTestSnapshot()
+		snapshot.Timestamp = ts
+		_, err := store.Save(personas[i], "Entry "+string(rune('A'+i)), "model", "msg", "", "", snapshot)
+		if err != nil {
+			t.Fatalf("failed to save entry %d: %v", i, err)
+		}
+	}
+
+	if _, err := store.DeleteByPersona("trashed"); err != nil {
+		t.Fatalf("DeleteByPersona() failed: %v", err)
+	}
+
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 8, 10, 0, 0, 0, time.UTC)
+	entries, err := store.ListRange(start, end)
+	if err != nil {
+		t.Fatalf("ListRange() failed: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Content != "Entry A" || entries[1].Content != "Entry B" {
+		t.Errorf("expected [Entry A, Entry B], got [%s, %s]", entries[0].Content, entries[1].Content)
+	}
+}
+
+// TestStoreSaveAt verifies an entry can be dated independently of its snapshot.
+func TestStoreSaveAt(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	snapshot := createTestSnapshot()
+	createdAt := snapshot.Timestamp.Add(-72 * time.Hour)
+	saved, err := store.SaveAt("persona", "backdated", "model", "msg", "", "", snapshot, createdAt)
+	if err != nil {
+		t.Fatalf("SaveAt() failed: %v", err)
+	}
+
+	got, err := store.GetByID(saved.ID)
+	if err != nil {
+		t.Fatalf("GetByID() failed: %v", err)
+	}
+	if !got.CreatedAt.Equal(createdAt) {
+		t.Errorf("expected created_at %s, got %s", createdAt, got.CreatedAt)
+	}
+	if !got.MetricsSnapshot.Timestamp.Equal(snapshot.Timestamp) {
+		t.Errorf("expected snapshot time to be kept, got %s", got.MetricsSnapshot.Timestamp)
+	}
+}
+
+// TestStoreSaveEntry verifies an entry is saved with its tags, and that a bad
+// tag saves nothing at all.
+func TestStoreSaveEntry(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	saved, err := store.SaveEntry(&Entry{
+		Persona:         "seeder",
+		Content:         "Backfilled.",
+		CreatedAt:       time.Now().Add(-time.Hour),
+		ModelID:         "model",
+		MetricsSnapshot: createTestSnapshot(),
+		Tags:            []string{"Seeded", "seeded", "extra"},
+	})
+	if err != nil {
+		t.Fatalf("SaveEntry() failed: %v", err)
+	}
+	got, err := store.GetByID(saved.ID)
+	if err != nil {
+		t.Fatalf("GetByID() failed: %v", err)
+	}
+	if len(got.Tags) != 2 || got.Tags[0] != "extra" || got.Tags[1] != "seeded" {
+		t.Errorf("expected tags [extra seeded], got %v", got.Tags)
+	}
+	if len(saved.Tags) != 2 || saved.Tags[1] != "seeded" {
+		t.Errorf("expected the returned entry to carry normalized tags, got %v", saved.Tags)
+	}
+
+	if _, err := store.SaveEntry(&Entry{Persona: "seeder", Content: "Bad tag.", CreatedAt: time.Now(), Tags: []string{"a,b"}}); err == nil {
+		t.Fatal("expected an error for a tag with a comma")
+	}
+	if n, _ := store.CountByPersona("seeder"); n != 1 {
+		t.Errorf("expected the entry with a bad tag not to be saved, got %d entries", n)
+	}
+}
+
+// TestStoreCountPerDay verifies entries are bucketed by local calendar day,
+// oldest first, with zero-filled gaps.
+func TestStoreCountPerDay(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	times := []time.Time{
+		today.Add(time.Hour),
+		today.AddDate(0, 0, -1).Add(23 * time.Hour),
+		today.AddDate(0, 0, -3).Add(9 * time.Hour),
+		today.AddDate(0, 0, -3).Add(18 * time.Hour),
+		today.AddDate(0, 0, -20),
+	}
+	for i, ts := range times {
+		snapshot := createTestSnapshot()
+		snapshot.Timestamp = ts
+		if _, err := store.Save("persona", "entry", "model", "msg", "", "", snapshot); err != nil {
+			t.Fatalf("failed to save entry %d: %v", i, err)
+		}
+	}
+
+	counts, err := store.CountPerDay(7)
+	if err != nil {
+		t.Fatalf("CountPerDay() failed: %v", err)
+	}
+
+	expected := []int{0, 0, 0, 2, 0, 1, 1}
+	if len(counts) != len(expected) {
+		t.Fatalf("expected %d days, got %d", len(expected), len(counts))
+	}
+	for i := range expected {
+		if counts[i] != expected[i] {
+			t.Errorf("expected counts %v, got %v", expected, counts)
+			break
+		}
+	}
+
+	if _, err := store.CountPerDay(0); err == nil {
+		t.Error("expected error for non-positive days")
+	}
+}
+
+// TestStoreAverageThermalByDay verifies daily averages of the highest
+// temperature, read from both plain and compressed snapshots, skipping entries
+// without thermal data.
+func TestStoreAverageThermalByDay(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	save := func(ts time.Time, temp *float64) {
+		t.Helper()
+		snapshot := createTestSnapshot()
+		snapshot.Timestamp = ts
+		if temp != nil {
+			snapshot.Thermal = &metrics.ThermalInfo{HighestTemp: *temp, SensorCount: 1}
+		}
+		if _, err := store.Save("persona", "entry", "model", "msg", "", "", snapshot); err != nil {
+			t.Fatalf("failed to save entry: %v", err)
+		}
+	}
+	temp := func(v float64) *float64 { return &v }
+
+	save(today.Add(time.Hour), temp(60))
+	save(today.AddDate(0, 0, -1).Add(9*time.Hour), temp(50))
+	save(today.AddDate(0, 0, -1).Add(18*time.Hour), nil)
+	store.SetCompressMetrics(true)
+	save(today.AddDate(0, 0, -1).Add(20*time.Hour), temp(70))
+	save(today.AddDate(0, 0, -10), temp(90))
+
+	days, err := store.AverageThermalByDay(3)
+	if err != nil {
+		t.Fatalf("AverageThermalByDay() failed: %v", err)
+	}
+	if len(days) != 3 {
+		t.Fatalf("expected 3 days, got %d", len(days))
+	}
+	if days[0].Samples != 0 || days[0].Average != 0 {
+		t.Errorf("expected an empty first day, got %+v", days[0])
+	}
+	if days[1].Samples != 2 || days[1].Average != 60 {
+		t.Errorf("expected yesterday to average 60 over 2 samples, got %+v", days[1])
+	}
+	if days[2].Samples != 1 || days[2].Average != 60 || !days[2].Day.Equal(today) {
+		t.Errorf("expected today to average 60 over 1 sample, got %+v", days[2])
+	}
+
+	if _, err := store.AverageThermalByDay(0); err == nil {
+		t.Error("expected error for non-positive days")
+	}
+}
+
 // TestStoreListByPersona verifies filtering by persona works correctly.
 func TestStoreListByPersona(t *testing.T) {
 	store, cleanup := setupTestDB(t)
@@ -207,10 +405,10 @@ func TestStoreListByPersona(t *testing.T) {
 	snapshot := createTestSnapshot()
 
 	// Create entries for different personas
-	store.Save("alice", "Alice entry 1", "model", "msg1", snapshot)
-	store.Save("bob", "Bob entry 1", "model", "msg2", snapshot)
-	store.Save("alice", "Alice entry 2", "model", "msg3", snapshot)
-	store.Save("bob", "Bob entry 2", "model", "msg4", snapshot)
+	store.Save("alice", "Alice entry 1", "model", "msg1", "", "", snapshot)
+	store.Save("bob", "Bob entry 1", "model", "msg2", "", "", snapshot)
+	store.Save("alice", "Alice entry 2", "model", "msg3", "", "", snapshot)
+	store.Save("bob", "Bob entry 2", "model", "msg4", "", "", snapshot)
 
 	// List Alice's entries
 	aliceEntries, err := store.ListByPersona("alice", 10)
@@ -238,6 +436,237 @@ func TestStoreListByPersona(t *testing.T) {
 	}
 }
 
+// TestStoreListByModel verifies entries can be listed, filtered, and counted
+// by the model that wrote them.
+func TestStoreListByModel(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	snapshot := createTestSnapshot()
+	store.Save("alice", "Alice on sonnet", "sonnet", "msg1", "", "", snapshot)
+	store.Save("bob", "Bob on haiku", "haiku", "msg2", "", "", snapshot)
+	store.Save("alice", "Alice on haiku", "haiku", "msg3", "", "", snapshot)
+
+	haiku, err := store.ListByModel("haiku", 10)
+	if err != nil {
+		t.Fatalf("ListByModel() failed: %v", err)
+	}
+	if len(haiku) != 2 || haiku[0].Content != "Alice on haiku" {
+		t.Errorf("expected 2 haiku entries newest first, got %d", len(haiku))
+	}
+
+	filtered, err := store.ListFiltered(Filter{Persona: "alice", Model: "haiku"})
+	if err != nil {
+		t.Fatalf("ListFiltered() failed: %v", err)
+	}
+	if len(filtered) != 1 {
+		t.Errorf("expected 1 entry for alice on haiku, got %d", len(filtered))
+	}
+
+	counts, err := store.CountPerModel()
+	if err != nil {
+		t.Fatalf("CountPerModel() failed: %v", err)
+	}
+	expected := []GroupCount{{"haiku", 2}, {"sonnet", 1}}
+	if len(counts) != len(expected) || counts[0] != expected[0] || counts[1] != expected[1] {
+		t.Errorf("expected %v, got %v", expected, counts)
+	}
+}
+
+// TestStoreMood verifies moods are saved and replaced with their entries,
+// filtered on, and counted, with unclassified entries grouped under an empty value.
+func TestStoreMood(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	snapshot := createTestSnapshot()
+	save := func(persona string, content string, mood string) *Entry {
+		t.Helper()
+		e, err := store.SaveEntry(&Entry{Persona: persona, Content: content, CreatedAt: time.Now(), MetricsSnapshot: snapshot, Mood: mood})
+		if err != nil {
+			t.Fatalf("SaveEntry() failed: %v", err)
+		}
+		if e.Mood != mood {
+			t.Errorf("expected the saved entry to carry mood %q, got %q", mood, e.Mood)
+		}
+		return e
+	}
+	first := save("alice", "A slow, sleepy day", "tired")
+	second := save("bob", "Fans racing all afternoon", "calm")
+	third := save("alice", "Another drowsy day", "tired")
+	store.Save("bob", "Written before moods", "model", "msg4", "", "", snapshot)
+
+	if _, err := store.UpdateEntry(second.ID, "Fans racing all afternoon!", "model", "msg2", "", "", snapshot, "energetic"); err != nil {
+		t.Fatalf("UpdateEntry() failed: %v", err)
+	}
+
+	got, err := store.GetByID(first.ID)
+	if err != nil {
+		t.Fatalf("GetByID() failed: %v", err)
+	}
+	if got.Mood != "tired" {
+		t.Errorf("expected mood tired, got %q", got.Mood)
+	}
+
+	tired, err := store.ListByMood("tired", 10)
+	if err != nil {
+		t.Fatalf("ListByMood() failed: %v", err)
+	}
+	if len(tired) != 2 || tired[0].ID != third.ID {
+		t.Errorf("expected 2 tired entries newest first, got %d", len(tired))
+	}
+
+	filtered, err := store.ListFiltered(Filter{Persona: "bob", Mood: "energetic"})
+	if err != nil {
+		t.Fatalf("ListFiltered() failed: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].ID != second.ID {
+		t.Errorf("expected bob's energetic entry, got %d entries", len(filtered))
+	}
+
+	counts, err := store.CountPerMood()
+	if err != nil {
+		t.Fatalf("CountPerMood() failed: %v", err)
+	}
+	expected := []GroupCount{{"tired", 2}, {"energetic", 1}, {"", 1}}
+	if len(counts) != len(expected) || counts[0] != expected[0] || counts[1] != expected[1] || counts[2] != expected[2] {
+		t.Errorf("expected %v, got %v", expected, counts)
+	}
+}
+
+// TestStoreListFiltered verifies persona, tag, and date filters combine, skip
+// the trash, and honor the limit.
+func TestStoreListFiltered(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	base := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	rows := []struct {
+		persona string
+		daysAgo int
+	}{
+		{"alice", 0},
+		{"bob", 1},
+		{"alice", 5},
+		{"alice", 20},
+		{"carol", 2},
+	}
+	for i, r := range rows {
+		snapshot := createTestSnapshot()
+		snapshot.Timestamp = base.AddDate(0, 0, -r.daysAgo)
+		saved, err := store.Save(r.persona, "entry", "model", "msg", "", "", snapshot)
+		if err != nil {
+			t.Fatalf("failed to save entry %d: %v", i, err)
+		}
+		if r.daysAgo <= 5 {
+			if err := store.AddTag(saved.ID, "recent"); err != nil {
+				t.Fatalf("AddTag() failed: %v", err)
+			}
+		}
+	}
+	if _, err := store.DeleteByPersona("carol"); err != nil {
+		t.Fatalf("DeleteByPersona() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected int
+	}{
+		{"no filter", Filter{}, 4},
+		{"persona", Filter{Persona: "alice"}, 3},
+		{"since", Filter{Since: base.AddDate(0, 0, -7)}, 3},
+		{"persona and since", Filter{Persona: "alice", Since: base.AddDate(0, 0, -7)}, 2},
+		{"tag", Filter{Tag: "recent"}, 3},
+		{"persona and tag", Filter{Persona: "alice", Tag: "Recent"}, 2},
+		{"limit", Filter{Limit: 2}, 2},
+		{"trashed persona", Filter{Persona: "carol"}, 0},
+	}
+
+	for _, tt := range tests {
+		entries, err := store.ListFiltered(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: ListFiltered() failed: %v", tt.name, err)
+		}
+		if len(entries) != tt.expected {
+			t.Errorf("%s: expected %d entries, got %d", tt.name, tt.expected, len(entries))
+		}
+		for i := 1; i < len(entries); i++ {
+			if entries[i].CreatedAt.After(entries[i-1].CreatedAt) {
+				t.Errorf("%s: entries not ordered newest first", tt.name)
+			}
+		}
+	}
+
+	// EachFiltered stops at the first error from the callback
+	stop := errors.New("stop")
+	visited := 0
+	err := store.EachFiltered(Filter{}, func(e *Entry) error {
+		visited++
+		return stop
+	})
+	if !errors.Is(err, stop) || visited != 1 {
+		t.Errorf("expected iteration to stop after 1 entry with the callback error, got %d visited, err %v", visited, err)
+	}
+
+	personas, err := store.ListPersonas()
+	if err != nil {
+		t.Fatalf("ListPersonas() failed: %v", err)
+	}
+	if len(personas) != 2 || personas[0] != "alice" || personas[1] != "bob" {
+		t.Errorf("expected [alice bob], got %v", personas)
+	}
+}
+
+// TestStoreMarkRead verifies new entries start unread, MarkRead clears them
+// from the unread count and filter, and trashed entries are not counted.
+func TestStoreMarkRead(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	snapshot := createTestSnapshot()
+	first, _ := store.Save("alice", "First", "model", "msg1", "", "", snapshot)
+	second, _ := store.Save("alice", "Second", "model", "msg2", "", "", snapshot)
+	third, _ := store.Save("alice", "Third", "model", "msg3", "", "", snapshot)
+
+	count, err := store.CountUnread()
+	if err != nil {
+		t.Fatalf("CountUnread() failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 unread entries, got %d", count)
+	}
+
+	if err := store.MarkRead(first.ID); err != nil {
+		t.Fatalf("MarkRead() failed: %v", err)
+	}
+	if err := store.Delete(third.ID); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+
+	count, _ = store.CountUnread()
+	if count != 1 {
+		t.Errorf("expected 1 unread entry, got %d", count)
+	}
+
+	read, _ := store.GetByID(first.ID)
+	if !read.IsRead {
+		t.Error("expected entry to be marked read")
+	}
+
+	unread, err := store.ListFiltered(Filter{Unread: true})
+	if err != nil {
+		t.Fatalf("ListFiltered() failed: %v", err)
+	}
+	if len(unread) != 1 || unread[0].ID != second.ID {
+		t.Errorf("expected only entry #%d unread, got %d entries", second.ID, len(unread))
+	}
+
+	if err := store.MarkRead(third.ID); err == nil {
+		t.Error("expected error marking a trashed entry read")
+	}
+}
+
 // TestStoreDeleteOperations verifies delete functionality.
 func TestStoreDeleteOperations(t *testing.T) {
 	store, cleanup := setupTestDB(t)
@@ -246,9 +675,9 @@ func TestStoreDeleteOperations(t *testing.T) {
 	snapshot := createTestSnapshot()
 
 	// Create test data
-	store.Save("delete_me", "Entry 1", "model", "msg1", snapshot)
-	store.Save("delete_me", "Entry 2", "model", "msg2", snapshot)
-	store.Save("keep_me", "Entry 3", "model", "msg3", snapshot)
+	store.Save("delete_me", "Entry 1", "model", "msg1", "", "", snapshot)
+	store.Save("delete_me", "Entry 2", "model", "msg2", "", "", snapshot)
+	store.Save("keep_me", "Entry 3", "model", "msg3", "", "", snapshot)
 
 	// Delete by persona
 	deleted, err := store.DeleteByPersona("delete_me")
@@ -272,7 +701,7 @@ func TestStoreDeleteOperations(t *testing.T) {
 	}
 
 	// Test DeleteAll
-	store.Save("another", "Entry 4", "model", "msg4", snapshot)
+	store.Save("another", "Entry 4", "model", "msg4", "", "", snapshot)
 	deleted, err = store.DeleteAll()
 	if err != nil {
 		t.Fatalf("DeleteAll() failed: %v", err)
@@ -294,7 +723,527 @@ func TestStoreGetByIDNotFound(t *testing.T) {
 	defer cleanup()
 
 	_, err := store.GetByID(99999)
-	if err == nil {
-		t.Error("expected error for non-existent ID, got nil")
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound, got %v", err)
+	}
+}
+
+// TestGetNeighbors verifies entries link to their chronological neighbors,
+// skipping the trash and breaking timestamp ties by ID.
+func TestGetNeighbors(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	save := func(content string, at time.Time) *Entry {
+		t.Helper()
+		e, err := store.SaveAt("poet", content, "m", "", "", "", createTestSnapshot(), at)
+		if err != nil {
+			t.Fatalf("SaveAt() failed: %v", err)
+		}
+		return e
+	}
+	// Saved out of order, with two entries sharing a timestamp
+	third := save("third", base.Add(2*time.Hour))
+	first := save("first", base)
+	second := save("second", base.Add(time.Hour))
+	twin := save("twin", base.Add(time.Hour))
+	trashed := save("trashed", base.Add(90*time.Minute))
+	if err := store.Delete(trashed.ID); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		id         int64
+		prev, next *Entry
+	}{
+		{"oldest", first.ID, nil, second},
+		{"tie, lower ID", second.ID, first, twin},
+		{"tie, higher ID skips the trash", twin.ID, second, third},
+		{"newest", third.ID, twin, nil},
+	}
+	ids := func(e *Entry) int64 {
+		if e == nil {
+			return 0
+		}
+		return e.ID
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev, next, err := store.GetNeighbors(tt.id)
+			if err != nil {
+				t.Fatalf("GetNeighbors() failed: %v", err)
+			}
+			if ids(prev) != ids(tt.prev) || ids(next) != ids(tt.next) {
+				t.Errorf("expected prev #%d and next #%d, got #%d and #%d", ids(tt.prev), ids(tt.next), ids(prev), ids(next))
+			}
+		})
+	}
+
+	if _, _, err := store.GetNeighbors(trashed.ID); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound for a trashed entry, got %v", err)
+	}
+}
+
+// TestContentHash verifies saved and edited entries carry a hash of their text
+// that ignores whitespace differences.
+func TestContentHash(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if ContentHash("Dear diary,\n\n  today was long.") != ContentHash("Dear diary, today was long.\n") {
+		t.Error("expected reflowed text to hash alike")
+	}
+	if ContentHash("Dear diary.") == ContentHash("Dear Diary.") {
+		t.Error("expected different text to hash differently")
+	}
+
+	saved, err := store.Save("poet", "First draft.", "m", "msg", "", "", createTestSnapshot())
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if saved.ContentHash != ContentHash("First draft.") {
+		t.Errorf("expected Save to return the content hash, got %q", saved.ContentHash)
+	}
+
+	updated, err := store.UpdateContent(saved.ID, "Second draft.")
+	if err != nil {
+		t.Fatalf("UpdateContent() failed: %v", err)
+	}
+	if updated.ContentHash != ContentHash("Second draft.") {
+		t.Errorf("expected the hash to follow the new content, got %q", updated.ContentHash)
+	}
+}
+
+// TestStoreImport verifies imported entries keep their date and tags, start out
+// read, and are skipped when the journal already has them.
+func TestStoreImport(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	existing, err := store.Save("poet", "Already here.", "m", "msg_1", "", "", createTestSnapshot())
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	createdAt := time.Date(2024, 12, 25, 8, 0, 0, 0, time.UTC)
+	imported, err := store.Import(&Entry{Persona: "grump", Content: "Holiday load.", CreatedAt: createdAt, Tags: []string{"Holiday"}})
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+	if !imported.CreatedAt.Equal(createdAt) || !imported.IsRead {
+		t.Errorf("expected the original date and a read entry, got %v read=%v", imported.CreatedAt, imported.IsRead)
+	}
+	if len(imported.Tags) != 1 || imported.Tags[0] != "holiday" {
+		t.Errorf("expected normalized tags, got %v", imported.Tags)
+	}
+	if imported.MetricsSnapshot != nil {
+		t.Errorf("expected no metrics for an entry imported without them, got %+v", imported.MetricsSnapshot)
+	}
+
+	if _, err := store.Import(&Entry{Persona: "poet", Content: "Copy.", CreatedAt: time.Now(), MessageID: "msg_1"}); !errors.Is(err, ErrEntryExists) {
+		t.Errorf("expected ErrEntryExists for a matching message ID, got %v", err)
+	}
+	if _, err := store.Import(&Entry{ID: existing.ID, Persona: "poet", Content: "Copy.", CreatedAt: existing.CreatedAt}); !errors.Is(err, ErrEntryExists) {
+		t.Errorf("expected ErrEntryExists for a matching ID and date, got %v", err)
+	}
+	if _, err := store.Import(&Entry{Persona: "grump", Content: "Holiday load.", CreatedAt: createdAt}); !errors.Is(err, ErrEntryExists) {
+		t.Errorf("expected ErrEntryExists re-importing a hand-written entry, got %v", err)
+	}
+	// Another journal's entry #1 is a different entry
+	if _, err := store.Import(&Entry{ID: existing.ID, Persona: "poet", Content: "Elsewhere.", CreatedAt: createdAt}); err != nil {
+		t.Errorf("expected an entry with a reused ID but new date to import, got %v", err)
+	}
+}
+
+// TestStoreUpdateEntry verifies an entry can be regenerated in place,
+// keeping its ID and creation time.
+func TestStoreUpdateEntry(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	original := createTestSnapshot()
+	original.Timestamp = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	entry, err := store.Save("persona", "Original content", "model-a", "msg1", "", "", original)
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	updatedSnapshot := createTestSnapshot()
+	updatedSnapshot.CPUPercent = 90.0
+
+	updated, err := store.UpdateEntry(entry.ID, "Regenerated content", "model-b", "msg2", "", "", updatedSnapshot, "")
+	if err != nil {
+		t.Fatalf("UpdateEntry() failed: %v", err)
+	}
+
+	if updated.ID != entry.ID {
+		t.Errorf("expected ID %d, got %d", entry.ID, updated.ID)
+	}
+	if updated.Content != "Regenerated content" {
+		t.Errorf("content not updated: %q", updated.Content)
+	}
+	if updated.ModelID != "model-b" {
+		t.Errorf("model_id not updated: %q", updated.ModelID)
+	}
+	if !updated.CreatedAt.Equal(original.Timestamp) {
+		t.Errorf("created_at changed: expected %v, got %v", original.Timestamp, updated.CreatedAt)
+	}
+	if updated.MetricsSnapshot == nil || updated.MetricsSnapshot.CPUPercent != 90.0 {
+		t.Error("metrics snapshot not updated")
+	}
+
+	// Updating a missing entry should fail
+	if _, err := store.UpdateEntry(99999, "x", "m", "msg", "", "", updatedSnapshot, ""); err == nil {
+		t.Error("expected error updating non-existent entry, got nil")
+	}
+}
+
+// TestStoreDelete verifies a single entry is moved to the trash and can't
+// be deleted twice.
+func TestStoreDelete(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	snapshot := createTestSnapshot()
+	entry, _ := store.Save("persona", "Entry 1", "model", "msg1", "", "", snapshot)
+	store.Save("persona", "Entry 2", "model", "msg2", "", "", snapshot)
+
+	if err := store.Delete(entry.ID); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if _, err := store.GetByID(entry.ID); err == nil {
+		t.Error("expected deleted entry to be hidden")
+	}
+	if trash, _ := store.ListTrash(10); len(trash) != 1 {
+		t.Errorf("expected 1 trashed entry, got %d", len(trash))
+	}
+
+	if err := store.Delete(entry.ID); err == nil {
+		t.Error("expected error deleting an already trashed entry")
+	}
+}
+
+// TestStoreTrashAndRestore verifies that deletes are soft, trashed entries
+// are hidden from default queries, and they can be restored or purged.
+func TestStoreTrashAndRestore(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	snapshot := createTestSnapshot()
+	trashed, _ := store.Save("trash_me", "Entry 1", "model", "msg1", "", "", snapshot)
+	store.Save("trash_me", "Entry 2", "model", "msg2", "", "", snapshot)
+	store.Save("keep_me", "Entry 3", "model", "msg3", "", "", snapshot)
+
+	if _, err := store.DeleteByPersona("trash_me"); err != nil {
+		t.Fatalf("DeleteByPersona() failed: %v", err)
+	}
+
+	// Trashed entries are hidden from default queries
+	if _, err := store.GetByID(trashed.ID); err == nil {
+		t.Error("expected GetByID to exclude trashed entry")
+	}
+	if entries, _ := store.ListByPersona("trash_me", 10); len(entries) != 0 {
+		t.Errorf("expected 0 trash_me entries, got %d", len(entries))
+	}
+	if entries, _ := store.List(10); len(entries) != 1 {
+		t.Errorf("expected 1 live entry, got %d", len(entries))
+	}
+
+	// ...but listed in the trash
+	trash, err := store.ListTrash(10)
+	if err != nil {
+		t.Fatalf("ListTrash() failed: %v", err)
+	}
+	if len(trash) != 2 {
+		t.Fatalf("expected 2 trashed entries, got %d", len(trash))
+	}
+	if trash[0].DeletedAt == nil {
+		t.Error("expected DeletedAt to be set on trashed entry")
+	}
+	if count, err := store.CountTrash(); err != nil || count != 2 {
+		t.Errorf("expected CountTrash() = 2, got %d (%v)", count, err)
+	}
+
+	// Restore brings one back
+	if err := store.Restore(trashed.ID); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	restored, err := store.GetByID(trashed.ID)
+	if err != nil {
+		t.Fatalf("GetByID() after restore failed: %v", err)
+	}
+	if restored.DeletedAt != nil {
+		t.Error("expected DeletedAt to be cleared after restore")
+	}
+	if err := store.Restore(trashed.ID); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound restoring an entry that is not in the trash, got %v", err)
+	}
+
+	// Emptying the trash purges the rest permanently
+	purged, err := store.EmptyTrash()
+	if err != nil {
+		t.Fatalf("EmptyTrash() failed: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("expected 1 purged entry, got %d", purged)
+	}
+	if trash, _ := store.ListTrash(10); len(trash) != 0 {
+		t.Errorf("expected empty trash, got %d entries", len(trash))
+	}
+	if count, _ := store.CountTrash(); count != 0 {
+		t.Errorf("expected CountTrash() = 0 after purge, got %d", count)
+	}
+	if entries, _ := store.List(10); len(entries) != 2 {
+		t.Errorf("expected 2 live entries after purge, got %d", len(entries))
+	}
+}
+
+// TestStoreConcurrentAccess verifies that WAL mode lets readers and a writer
+// on separate connections (like the TUI and daemon) run without lock errors.
+func TestStoreConcurrentAccess(t *testing.T) {
+	writer, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var mode string
+	if err := writer.db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatalf("failed to read journal mode: %v", err)
+	}
+	if mode != "wal" {
+		t.Errorf("expected journal_mode wal, got %q", mode)
+	}
+
+	reader, err := Open()
+	if err != nil {
+		t.Fatalf("failed to open second store: %v", err)
+	}
+	defer reader.Close()
+
+	const writes = 20
+	const readers = 4
+
+	errs := make(chan error, writes+readers*writes)
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < writes; i++ {
+			if _, err := writer.Save("persona", "Concurrent entry", "model", "msg", "", "", createTestSnapshot()); err != nil {
+				errs <- err
+			}
+		}
+	}()
+
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				if _, err := reader.List(10); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent access failed: %v", err)
+	}
+
+	entries, err := reader.List(100)
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(entries) != writes {
+		t.Errorf("expected %d entries, got %d", writes, len(entries))
+	}
+}
+
+// TestStoreTags verifies tags are normalized, attached to entries, and
+// usable as a list filter.
+func TestStoreTags(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	snapshot := createTestSnapshot()
+	first, _ := store.Save("alice", "Entry 1", "model", "msg1", "", "", snapshot)
+	second, _ := store.Save("bob", "Entry 2", "model", "msg2", "", "", snapshot)
+
+	if err := store.AddTag(first.ID, "  Milestone "); err != nil {
+		t.Fatalf("AddTag() failed: %v", err)
+	}
+	if err := store.AddTag(first.ID, "funny"); err != nil {
+		t.Fatalf("AddTag() failed: %v", err)
+	}
+	if err := store.AddTag(first.ID, "MILESTONE"); err != nil {
+		t.Fatalf("AddTag() with duplicate tag failed: %v", err)
+	}
+	if err := store.AddTag(second.ID, "milestone"); err != nil {
+		t.Fatalf("AddTag() failed: %v", err)
+	}
+
+	// Invalid tags and missing entries are rejected
+	if err := store.AddTag(first.ID, "   "); err == nil {
+		t.Error("expected error for empty tag")
+	}
+	if err := store.AddTag(99999, "milestone"); err == nil {
+		t.Error("expected error tagging a missing entry")
+	}
+
+	retrieved, err := store.GetByID(first.ID)
+	if err != nil {
+		t.Fatalf("GetByID() failed: %v", err)
+	}
+	if len(retrieved.Tags) != 2 || retrieved.Tags[0] != "funny" || retrieved.Tags[1] != "milestone" {
+		t.Errorf("expected tags [funny milestone], got %v", retrieved.Tags)
+	}
+
+	tagged, err := store.ListByTag("Milestone", 10)
+	if err != nil {
+		t.Fatalf("ListByTag() failed: %v", err)
+	}
+	if len(tagged) != 2 {
+		t.Errorf("expected 2 milestone entries, got %d", len(tagged))
+	}
+
+	if err := store.RemoveTag(first.ID, "milestone"); err != nil {
+		t.Fatalf("RemoveTag() failed: %v", err)
+	}
+	if err := store.RemoveTag(first.ID, "milestone"); err == nil {
+		t.Error("expected error removing a tag that isn't attached")
+	}
+
+	tagged, _ = store.ListByTag("milestone", 10)
+	if len(tagged) != 1 || tagged[0].ID != second.ID {
+		t.Errorf("expected only entry #%d tagged milestone, got %v", second.ID, tagged)
+	}
+}
+
+// TestStoreReassign verifies single and bulk persona reassignment.
+func TestStoreReassign(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	snapshot := createTestSnapshot()
+	first, _ := store.Save("wrong", "Entry 1", "model", "msg1", "", "", snapshot)
+	store.Save("wrong", "Entry 2", "model", "msg2", "", "", snapshot)
+	store.Save("wrong", "Entry 3", "model", "msg3", "", "", snapshot)
+
+	if err := store.Reassign(first.ID, "right"); err != nil {
+		t.Fatalf("Reassign() failed: %v", err)
+	}
+	moved, _ := store.GetByID(first.ID)
+	if moved.Persona != "right" {
+		t.Errorf("expected persona 'right', got %q", moved.Persona)
+	}
+	if err := store.Reassign(99999, "right"); err == nil {
+		t.Error("expected error reassigning a missing entry")
+	}
+
+	count, err := store.ReassignPersona("wrong", "right")
+	if err != nil {
+		t.Fatalf("ReassignPersona() failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 entries moved, got %d", count)
+	}
+	if n, _ := store.CountByPersona("right"); n != 3 {
+		t.Errorf("expected 3 entries for 'right', got %d", n)
+	}
+	if n, _ := store.CountByPersona("wrong"); n != 0 {
+		t.Errorf("expected 0 entries for 'wrong', got %d", n)
+	}
+}
+
+// TestStoreRandom verifies a random pick honors the filter, skips the trash,
+// and returns nil for an empty journal.
+func TestStoreRandom(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	e, err := store.Random(Filter{})
+	if err != nil {
+		t.Fatalf("Random() on empty journal failed: %v", err)
+	}
+	if e != nil {
+		t.Fatalf("expected nil for empty journal, got #%d", e.ID)
+	}
+
+	store.Save("alice", "a1", "model", "msg", "", "", createTestSnapshot())
+	store.Save("alice", "a2", "model", "msg", "", "", createTestSnapshot())
+	bob, _ := store.Save("bob", "b1", "model", "msg", "", "", createTestSnapshot())
+
+	for i := 0; i < 20; i++ {
+		e, err := store.Random(Filter{Persona: "alice"})
+		if err != nil {
+			t.Fatalf("Random() failed: %v", err)
+		}
+		if e == nil || e.Persona != "alice" {
+			t.Fatalf("expected an alice entry, got %+v", e)
+		}
+	}
+
+	if err := store.Delete(bob.ID); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	e, err = store.Random(Filter{Persona: "bob"})
+	if err != nil {
+		t.Fatalf("Random() failed: %v", err)
+	}
+	if e != nil {
+		t.Errorf("expected trashed entry to be skipped, got #%d", e.ID)
+	}
+}
+
+// TestStoreMaintain verifies maintenance shrinks the file after a purge and
+// leaves the remaining entries readable.
+func TestStoreMaintain(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	content := strings.Repeat("A long day of compiling. ", 400)
+	var keep int64
+	for i := 0; i < 50; i++ {
+		saved, err := store.Save("default", content, "model", "msg", "", "", createTestSnapshot())
+		if err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+		if i == 0 {
+			keep = saved.ID
+		}
+	}
+	if _, err := store.DeleteAll(); err != nil {
+		t.Fatalf("DeleteAll() failed: %v", err)
+	}
+	if err := store.Restore(keep); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	if _, err := store.EmptyTrash(); err != nil {
+		t.Fatalf("EmptyTrash() failed: %v", err)
+	}
+
+	before, err := DiskUsage()
+	if err != nil {
+		t.Fatalf("DiskUsage() failed: %v", err)
+	}
+	if err := store.Maintain(); err != nil {
+		t.Fatalf("Maintain() failed: %v", err)
+	}
+	after, err := DiskUsage()
+	if err != nil {
+		t.Fatalf("DiskUsage() failed: %v", err)
+	}
+	if after >= before {
+		t.Errorf("expected the database to shrink, went from %d to %d bytes", before, after)
+	}
+
+	if e, err := store.GetByID(keep); err != nil || e == nil || e.Content != content {
+		t.Errorf("expected the kept entry to survive maintenance, got %v, %v", e, err)
 	}
 }
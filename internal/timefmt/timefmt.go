@@ -0,0 +1,148 @@
+// Package timefmt formats timestamps for display in the configured timezone
+package timefmt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Layouts shared by the CLI, TUI, and prompts
+const (
+	Short  = "Jan 02, 2006 3:04 PM"
+	Long   = "Monday, January 02, 2006 at 3:04 PM"
+	Day    = "Jan 02, 2006"
+	Prompt = "Monday, January 2, 2006 at 3:04 PM"
+)
+
+var (
+	mu       sync.RWMutex
+	location = time.Local
+)
+
+// LoadLocation resolves a display_timezone setting; empty or "local" means
+// the machine's timezone, anything else is an IANA name like "Europe/Berlin"
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" || strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone: %s", name)
+	}
+	return loc, nil
+}
+
+// SetLocation changes the timezone used for display
+func SetLocation(loc *time.Location) {
+	mu.Lock()
+	defer mu.Unlock()
+	location = loc
+}
+
+// Location returns the timezone used for display
+func Location() *time.Location {
+	mu.RLock()
+	defer mu.RUnlock()
+	return location
+}
+
+// In converts t to the display timezone
+func In(t time.Time) time.Time {
+	return t.In(Location())
+}
+
+// Format renders t in the display timezone
+func Format(t time.Time, layout string) string {
+	return In(t).Format(layout)
+}
+
+// Relative describes t relative to now, e.g. "3 hours ago" or "in 5 min";
+// times more than a week in the past fall back to a short date
+func Relative(t time.Time, now time.Time) string {
+	diff := now.Sub(t)
+
+	if diff < 0 {
+		diff = -diff
+		switch {
+		case diff < time.Minute:
+			return "in a moment"
+		case diff < time.Hour:
+			return fmt.Sprintf("in %d min", int(diff.Minutes()))
+		case diff < 24*time.Hour:
+			return "in " + plural(int(diff.Hours()), "hour")
+		default:
+			return "in " + plural(int(diff.Hours()/24), "day")
+		}
+	}
+
+	switch {
+	case diff < time.Minute:
+		return "just now"
+	case diff < time.Hour:
+		return fmt.Sprintf("%d min ago", int(diff.Minutes()))
+	case diff < 24*time.Hour:
+		return plural(int(diff.Hours()), "hour") + " ago"
+	case diff < 7*24*time.Hour:
+		return plural(int(diff.Hours()/24), "day") + " ago"
+	default:
+		return Format(t, "Jan 02")
+	}
+}
+
+// ParseDuration is like time.ParseDuration but also accepts whole days and
+// weeks, e.g. "7d" or "2w"
+func ParseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+// Split breaks a duration into whole days, hours, and minutes
+func Split(d time.Duration) (days int, hours int, minutes int) {
+	if d < 0 {
+		d = -d
+	}
+	return int(d.Hours() / 24), int(d.Hours()) % 24, int(d.Minutes()) % 60
+}
+
+// Human describes a duration by its two largest units, e.g. "2 days, 3 hours"
+// Zero units are dropped, and anything under a minute is "less than a minute"
+func Human(d time.Duration) string {
+	days, hours, minutes := Split(d)
+
+	var parts []string
+	switch {
+	case days > 0:
+		parts = append(parts, plural(days, "day"))
+		if hours > 0 {
+			parts = append(parts, plural(hours, "hour"))
+		}
+	case hours > 0:
+		parts = append(parts, plural(hours, "hour"))
+		if minutes > 0 {
+			parts = append(parts, plural(minutes, "minute"))
+		}
+	case minutes > 0:
+		parts = append(parts, plural(minutes, "minute"))
+	default:
+		return "less than a minute"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,135 @@
+package timefmt
+
+import (
+	"testing"
+	"time"
+)
+
+// TestRelativeFuture verifies the "in X" path used for the daemon's next trigger.
+func TestRelativeFuture(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		offset   time.Duration
+		expected string
+	}{
+		{30 * time.Second, "in a moment"},
+		{5 * time.Minute, "in 5 min"},
+		{time.Hour + 10*time.Minute, "in 1 hour"},
+		{5 * time.Hour, "in 5 hours"},
+		{26 * time.Hour, "in 1 day"},
+		{72 * time.Hour, "in 3 days"},
+	}
+
+	for _, tt := range tests {
+		if got := Relative(now.Add(tt.offset), now); got != tt.expected {
+			t.Errorf("Relative(+%s): expected %q, got %q", tt.offset, tt.expected, got)
+		}
+	}
+}
+
+// TestRelativePast verifies past times and the fallback to a date.
+func TestRelativePast(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		offset   time.Duration
+		expected string
+	}{
+		{10 * time.Second, "just now"},
+		{15 * time.Minute, "15 min ago"},
+		{time.Hour, "1 hour ago"},
+		{3 * time.Hour, "3 hours ago"},
+		{2 * 24 * time.Hour, "2 days ago"},
+		{9 * 24 * time.Hour, "Jun 01"},
+	}
+
+	for _, tt := range tests {
+		if got := Relative(now.Add(-tt.offset), now); got != tt.expected {
+			t.Errorf("Relative(-%s): expected %q, got %q", tt.offset, tt.expected, got)
+		}
+	}
+}
+
+// TestFormatUsesDisplayLocation verifies stored UTC times render in the
+// configured timezone.
+func TestFormatUsesDisplayLocation(t *testing.T) {
+	orig := Location()
+	defer SetLocation(orig)
+
+	tokyo, err := LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	SetLocation(tokyo)
+
+	stored := time.Date(2025, 1, 1, 22, 30, 0, 0, time.UTC)
+	if got := Format(stored, Short); got != "Jan 02, 2025 7:30 AM" {
+		t.Errorf("expected Tokyo time, got %q", got)
+	}
+}
+
+// TestLoadLocation verifies local aliases and invalid names.
+func TestLoadLocation(t *testing.T) {
+	for _, name := range []string{"", "local", "Local"} {
+		loc, err := LoadLocation(name)
+		if err != nil || loc != time.Local {
+			t.Errorf("LoadLocation(%q): expected time.Local, got %v (%v)", name, loc, err)
+		}
+	}
+	if _, err := LoadLocation("Mars/Olympus_Mons"); err == nil {
+		t.Error("expected error for unknown timezone")
+	}
+}
+
+// TestHuman verifies durations are described by their two largest units.
+func TestHuman(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{30 * time.Second, "less than a minute"},
+		{time.Minute, "1 minute"},
+		{45 * time.Minute, "45 minutes"},
+		{time.Hour, "1 hour"},
+		{3*time.Hour + 5*time.Minute, "3 hours, 5 minutes"},
+		{48 * time.Hour, "2 days"},
+		{51*time.Hour + 20*time.Minute, "2 days, 3 hours"},
+		{25 * time.Hour, "1 day, 1 hour"},
+	}
+
+	for _, tt := range tests {
+		if got := Human(tt.d); got != tt.expected {
+			t.Errorf("Human(%s): expected %q, got %q", tt.d, tt.expected, got)
+		}
+	}
+}
+
+// TestParseDuration verifies day and week suffixes alongside Go durations.
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"7d", 7 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"xd", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.s)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDuration(%q): expected error", tt.s)
+			}
+			continue
+		}
+		if err != nil || got != tt.expected {
+			t.Errorf("ParseDuration(%q): expected %s, got %s (err %v)", tt.s, tt.expected, got, err)
+		}
+	}
+}
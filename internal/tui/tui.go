@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
@@ -20,8 +22,11 @@ import (
 	"github.com/cldixon/jernel/internal/config"
 	"github.com/cldixon/jernel/internal/daemon"
 	"github.com/cldixon/jernel/internal/entry"
+	"github.com/cldixon/jernel/internal/llm"
+	"github.com/cldixon/jernel/internal/metrics"
 	"github.com/cldixon/jernel/internal/persona"
 	"github.com/cldixon/jernel/internal/store"
+	"github.com/cldixon/jernel/internal/timefmt"
 )
 
 // Tab represents the main navigation tabs
@@ -34,6 +39,47 @@ const (
 	tabSettings
 )
 
+// tabNames are the labels rendered in the tab bar, indexed by tab
+var tabNames = []string{"Entries", "Personas", "Daemon", "Settings"}
+
+// parseTab looks up a tab by its name, case-insensitively; "" is the entries tab
+func parseTab(name string) (tab, error) {
+	if name == "" {
+		return tabEntries, nil
+	}
+	for i, label := range tabNames {
+		if strings.EqualFold(name, label) {
+			return tab(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid tab: %s (must be entries, personas, daemon, or settings)", name)
+}
+
+// activityDays is how many days of history the daemon tab sparkline covers
+const activityDays = 14
+
+// dateRange is an entries tab date filter covering the last days calendar days
+type dateRange struct {
+	label string
+	days  int // 0 = all time
+}
+
+// dateRanges are cycled through with the date filter key
+var dateRanges = []dateRange{
+	{"all time", 0},
+	{"today", 1},
+	{"last 7 days", 7},
+	{"last 30 days", 30},
+}
+
+// Layout offsets used to map mouse coordinates onto rendered elements
+const (
+	tabBarHeight    = 2 // tab labels + bottom border
+	listHeaderLines = 2 // bubbles list title/filter bar
+	listItemHeight  = 3 // default delegate: title + description + spacing
+	mouseWheelLines = 3 // lines scrolled per wheel tick in viewports
+)
+
 // Sub-modes for specific interactions
 type subMode int
 
@@ -45,6 +91,10 @@ const (
 	subModePersonaEditor // in-TUI persona editor (create/edit)
 	subModeFirstPersona  // first-time persona creation wizard
 	subModeError         // show error message
+	subModeAddTag        // tag input for the selected entry
+	subModeRevisions     // browse and restore earlier versions of the selected entry
+	subModeQuitConfirm   // confirm quitting with unsaved persona editor changes
+	subModeCompare       // two marked entries side by side
 )
 
 // Colors - minimal palette
@@ -55,6 +105,8 @@ var (
 	colorAccent   = lipgloss.Color("#de4f5c") // cherry red
 	colorBorder   = lipgloss.Color("#444444")
 	colorError    = lipgloss.Color("#cc6666")
+	colorWarn     = lipgloss.Color("#d7af5f")
+	colorOK       = lipgloss.Color("#87af87")
 )
 
 // Styles - minimal
@@ -126,23 +178,42 @@ var (
 			Foreground(lipgloss.Color("#cc6666"))
 )
 
+// defaultPreviewLen is the entry preview length used before the layout is known
+const defaultPreviewLen = 30
+
 // entryItem wraps a store.Entry for the list
 type entryItem struct {
-	entry *store.Entry
+	entry      *store.Entry
+	previewLen int  // max preview length in characters
+	absolute   bool // show absolute timestamps instead of relative
+	marked     bool // marked for the compare view
 }
 
 func (i entryItem) Title() string {
-	preview := getContentPreview(i.entry.Content, 30)
+	previewLen := i.previewLen
+	if previewLen <= 0 {
+		previewLen = defaultPreviewLen
+	}
+	preview := getContentPreview(i.entry.Content, previewLen)
+	if i.marked {
+		return fmt.Sprintf("◆ #%d  %s", i.entry.ID, preview)
+	}
 	return fmt.Sprintf("#%d  %s", i.entry.ID, preview)
 }
 
 func (i entryItem) Description() string {
-	relTime := formatRelativeTime(i.entry.CreatedAt)
-	return fmt.Sprintf("%s · %s", relTime, i.entry.Persona)
+	timestamp := formatRelativeTime(i.entry.CreatedAt)
+	if i.absolute {
+		timestamp = timefmt.Format(i.entry.CreatedAt, timefmt.Short)
+	}
+	if !i.entry.IsRead {
+		timestamp = "● " + timestamp
+	}
+	return fmt.Sprintf("%s · %s", timestamp, i.entry.Persona)
 }
 
 func (i entryItem) FilterValue() string {
-	return i.entry.Persona + " " + i.entry.Content
+	return i.entry.Persona + " " + strings.Join(i.entry.Tags, " ") + " " + i.entry.Content
 }
 
 // personaItem wraps a persona for the list
@@ -167,11 +238,21 @@ type editorFinishedMsg struct{ err error }
 type generateDoneMsg struct {
 	entry *store.Entry
 	err   error
+	seq   int // genSeq of the generation that finished
+}
+type regenerateDoneMsg struct {
+	entry *store.Entry
+	err   error
+	seq   int
 }
 type daemonStatusMsg struct {
 	running bool
 	state   *daemon.State
 }
+type clearStatusMsg struct{}
+
+// statusDuration is how long transient status messages stay in the help bar
+const statusDuration = 2 * time.Second
 
 // Model is the main TUI model
 type Model struct {
@@ -185,11 +266,22 @@ type Model struct {
 	version   string
 
 	// Entries tab
-	entryList    list.Model
-	entryView    viewport.Model
-	entries      []*store.Entry
-	showMetrics  bool
-	metricsWidth int
+	entryList     list.Model
+	entryView     viewport.Model
+	entries       []*store.Entry
+	showMetrics   bool
+	showPrompt    bool // show the stored prompt instead of the entry content
+	metricsWidth  int
+	previewLen    int    // entry title preview length, derived from list width
+	filterPersona string // only show this persona's entries (empty = all)
+	filterRange   int    // index into dateRanges
+	filterUnread  bool   // only show entries that haven't been read
+	unreadCount   int    // shown as a badge on the Entries tab
+	entryLimit    int    // most entries loaded per query (0 = no limit)
+
+	// Compare view
+	marked      []*store.Entry // entries marked with space, oldest mark first (at most two)
+	compareView viewport.Model
 
 	// Personas tab
 	personaList list.Model
@@ -201,13 +293,33 @@ type Model struct {
 	genSpinner spinner.Model
 	genError   error
 	genPersona string
+	genTarget  int64              // entry being regenerated (0 when creating a new entry)
+	genCancel  context.CancelFunc // cancels the generation in progress
+	genSeq     int                // incremented per generation so results of cancelled ones are recognized
+
+	// Tagging
+	tagInput textinput.Model
+
+	// Revisions
+	revisions   []*store.Revision // earlier versions of the selected entry, newest first
+	revisionIdx int
 
 	// Persona editor
 	editorNameInput  textinput.Model
 	editorDescInput  textarea.Model
-	editorFocusName  bool   // true = name focused, false = desc focused
-	editorIsNew      bool   // true = creating new, false = editing existing
-	editorOrigName   string // original name when editing (for rename detection)
+	editorFocusName  bool               // true = name focused, false = desc focused
+	editorIsNew      bool               // true = creating new, false = editing existing
+	editorOrigName   string             // original name when editing (for rename detection)
+	editorInclude    []string           // includes carried over when editing (the editor shows only the persona's own text)
+	editorLength     string             // length override carried over when editing
+	editorUseHistory *bool              // use_history setting carried over when editing
+	editorInitName   string             // name the editor opened with, for unsaved change detection
+	editorInitDesc   string             // description the editor opened with
+	editorErr        error              // why the last save failed, shown in the editor
+	quitReturnMode   subMode            // sub-mode to return to when a quit is cancelled
+	examples         []*persona.Persona // bundled examples offered by the first persona wizard
+	exampleIdx       int                // selected example; len(examples) is "start from scratch"
+	pickingExample   bool               // first persona wizard is choosing an example before the editor
 	deleteTarget     string
 	deleteEntryCount int // number of entries that will be deleted with persona
 
@@ -215,16 +327,27 @@ type Model struct {
 	daemonRunning bool
 	daemonState   *daemon.State
 	daemonSpinner spinner.Model
+	activity      []int // entries per day, oldest first
 
 	// Settings tab
-	cfg *config.Config
+	apiKey       string
+	apiKeySource string // where apiKey was found: env, file, or keychain
+	cfg          *config.Config
 
 	// Shared
-	renderer *glamour.TermRenderer
+	renderer  *glamour.TermRenderer
+	statusMsg string // transient confirmation shown in the help bar
 }
 
 // New creates a new TUI model
-func New(entries []*store.Entry, version string) (*Model, error) {
+// entries is the initial list; limit caps later queries when filters change (0 = no limit)
+// startTab names the tab to open on (see tabNames; "" for entries)
+func New(entries []*store.Entry, version string, limit int, startTab string) (*Model, error) {
+	activeTab, err := parseTab(startTab)
+	if err != nil {
+		return nil, err
+	}
+
 	renderer, err := glamour.NewTermRenderer(
 		glamour.WithAutoStyle(),
 		glamour.WithWordWrap(70),
@@ -233,10 +356,13 @@ func New(entries []*store.Entry, version string) (*Model, error) {
 		return nil, fmt.Errorf("failed to create renderer: %w", err)
 	}
 
+	// Load config
+	cfg, _ := config.Load()
+
 	// Entry list
 	entryItems := make([]list.Item, len(entries))
 	for i, e := range entries {
-		entryItems[i] = entryItem{entry: e}
+		entryItems[i] = newEntryItem(e, cfg, defaultPreviewLen)
 	}
 	entryList := createList(entryItems)
 
@@ -260,6 +386,13 @@ func New(entries []*store.Entry, version string) (*Model, error) {
 	nameInput.Width = 40
 	nameInput.Prompt = ""
 
+	// Tag input
+	tagInput := textinput.New()
+	tagInput.Placeholder = "milestone"
+	tagInput.CharLimit = 32
+	tagInput.Width = 32
+	tagInput.Prompt = "#"
+
 	// Persona editor - description textarea
 	descInput := textarea.New()
 	descInput.Placeholder = "Describe the persona's voice, style, and personality..."
@@ -268,24 +401,32 @@ func New(entries []*store.Entry, version string) (*Model, error) {
 	descInput.SetHeight(10)
 	descInput.ShowLineNumbers = false
 
-	// Load config
-	cfg, _ := config.Load()
-
-	return &Model{
-		activeTab:       tabEntries,
+	m := &Model{
+		activeTab:       activeTab,
 		entries:         entries,
 		entryList:       entryList,
 		showMetrics:     true,
 		metricsWidth:    28,
+		previewLen:      defaultPreviewLen,
 		genSpinner:      genSpin,
 		daemonSpinner:   daemonSpin,
+		tagInput:        tagInput,
 		editorNameInput: nameInput,
 		editorDescInput: descInput,
 		editorFocusName: true,
 		cfg:             cfg,
+		entryLimit:      limit,
 		renderer:        renderer,
 		version:         version,
-	}, nil
+	}
+	m.loadUnreadCount()
+	return m, nil
+}
+
+// newEntryItem builds a list item honoring the configured timestamp style
+func newEntryItem(e *store.Entry, cfg *config.Config, previewLen int) entryItem {
+	absolute := cfg != nil && cfg.TUI != nil && cfg.TUI.Timestamps == "absolute"
+	return entryItem{entry: e, previewLen: previewLen, absolute: absolute}
 }
 
 func createList(items []list.Item) list.Model {
@@ -325,13 +466,16 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.recalculateLayout()
 		if !m.ready {
 			m.ready = true
-			// Initialize content on first render
-			m.updateEntryView()
+			// Initialize content on first render, loading whatever the start tab shows
+			return m, m.onTabChange()
 		}
 
 	case tea.KeyMsg:
 		return m.handleKeyMsg(msg)
 
+	case tea.MouseMsg:
+		return m.handleMouseMsg(msg)
+
 	case editorFinishedMsg:
 		if m.activeTab == tabPersonas {
 			m.loadPersonas()
@@ -340,6 +484,15 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case generateDoneMsg:
+		if !m.generating || msg.seq != m.genSeq {
+			// Cancelled; an entry saved before the cancel landed still belongs in the list
+			if msg.entry != nil {
+				m.entries = append([]*store.Entry{msg.entry}, m.entries...)
+				m.refreshEntryList()
+			}
+			return m, nil
+		}
+		m.releaseGeneration()
 		m.generating = false
 		if msg.err != nil {
 			m.genError = msg.err
@@ -352,11 +505,45 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case regenerateDoneMsg:
+		if !m.generating || msg.seq != m.genSeq {
+			if msg.entry != nil {
+				for i, e := range m.entries {
+					if e.ID == msg.entry.ID {
+						m.entries[i] = msg.entry
+					}
+				}
+				m.refreshEntryList()
+			}
+			return m, nil
+		}
+		m.releaseGeneration()
+		m.generating = false
+		m.genTarget = 0
+		if msg.err != nil {
+			m.genError = msg.err
+			m.subMode = subModeError
+		} else {
+			m.subMode = subModeNone
+			for i, e := range m.entries {
+				if e.ID == msg.entry.ID {
+					m.entries[i] = msg.entry
+				}
+			}
+			m.refreshEntryList()
+			m.updateEntryView()
+		}
+		return m, nil
+
 	case daemonStatusMsg:
 		m.daemonRunning = msg.running
 		m.daemonState = msg.state
 		return m, nil
 
+	case clearStatusMsg:
+		m.statusMsg = ""
+		return m, nil
+
 	case spinner.TickMsg:
 		var cmds []tea.Cmd
 		if m.generating {
@@ -378,22 +565,45 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Global quit
+	// Esc or ctrl+c abandons a generation rather than quitting
+	if m.subMode == subModeGenerating {
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			return m, m.cancelGeneration()
+		}
+		return m, nil
+	}
+
+	// Global quit, confirmed first when the persona editor has unsaved changes
 	if msg.String() == "ctrl+c" {
+		if m.inPersonaEditor() && m.editorDirty() {
+			m.quitReturnMode = m.subMode
+			m.subMode = subModeQuitConfirm
+			return m, nil
+		}
 		m.quitting = true
 		return m, tea.Quit
 	}
 
 	// Handle sub-modes first
 	switch m.subMode {
-	case subModeGenerating:
-		return m, nil
 	case subModeDeleteConfirm:
 		return m.handleDeleteConfirm(msg)
+	case subModeQuitConfirm:
+		return m.handleQuitConfirm(msg)
 	case subModeSelectPersona:
 		return m.handleSelectPersona(msg)
 	case subModePersonaEditor, subModeFirstPersona:
+		if m.pickingExample {
+			return m.handleExamplePicker(msg)
+		}
 		return m.handlePersonaEditor(msg)
+	case subModeAddTag:
+		return m.handleAddTag(msg)
+	case subModeRevisions:
+		return m.handleRevisions(msg)
+	case subModeCompare:
+		return m.handleCompare(msg)
 	case subModeError:
 		// Any key dismisses the error
 		m.subMode = subModeNone
@@ -441,6 +651,105 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleMouseMsg handles clicks and wheel scrolling. Mouse input is additive
+// to keyboard navigation and only applies outside of modal sub-modes.
+func (m *Model) handleMouseMsg(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.subMode != subModeNone {
+		return m, nil
+	}
+
+	// Clicking a tab label switches tabs
+	if msg.Y < tabBarHeight {
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			if t, ok := m.tabAt(msg.X); ok && t != m.activeTab {
+				m.activeTab = t
+				return m, m.onTabChange()
+			}
+		}
+		return m, nil
+	}
+
+	switch m.activeTab {
+	case tabEntries:
+		if m.handleListMouse(&m.entryList, &m.entryView, msg) {
+			m.updateEntryView()
+		}
+	case tabPersonas:
+		if m.handleListMouse(&m.personaList, &m.personaView, msg) {
+			m.updatePersonaView()
+		}
+	}
+
+	return m, nil
+}
+
+// handleListMouse applies a mouse event to a list/viewport pair, returning
+// true if the list selection changed
+func (m *Model) handleListMouse(l *list.Model, vp *viewport.Model, msg tea.MouseMsg) bool {
+	listWidth := l.Width() + listStyle.GetHorizontalPadding()
+	prevIdx := l.Index()
+
+	if msg.X >= listWidth {
+		// Over the content pane: let the viewport handle wheel scrolling
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			vp.ScrollUp(mouseWheelLines)
+		case tea.MouseButtonWheelDown:
+			vp.ScrollDown(mouseWheelLines)
+		}
+		return false
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		l.CursorUp()
+	case tea.MouseButtonWheelDown:
+		l.CursorDown()
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return false
+		}
+		if idx, ok := listIndexAt(l, msg.Y-tabBarHeight); ok {
+			l.Select(idx)
+		}
+	}
+
+	return prevIdx != l.Index()
+}
+
+// listIndexAt maps a row within the list panel to a visible item index
+func listIndexAt(l *list.Model, row int) (int, bool) {
+	row -= listHeaderLines
+	if row < 0 {
+		return 0, false
+	}
+
+	offset := row / listItemHeight
+	onPage := l.Paginator.ItemsOnPage(len(l.VisibleItems()))
+	if offset >= onPage {
+		return 0, false
+	}
+
+	return l.Paginator.Page*l.Paginator.PerPage + offset, true
+}
+
+// tabAt returns the tab rendered at the given column of the tab bar
+func (m *Model) tabAt(x int) (tab, bool) {
+	left := 0
+	for i := range tabNames {
+		style := tabStyle
+		if tab(i) == m.activeTab {
+			style = activeTabStyle
+		}
+		right := left + lipgloss.Width(style.Render(m.tabLabel(tab(i))))
+		if x >= left && x < right {
+			return tab(i), true
+		}
+		left = right
+	}
+	return 0, false
+}
+
 func (m *Model) onTabChange() tea.Cmd {
 	switch m.activeTab {
 	case tabEntries:
@@ -452,6 +761,7 @@ func (m *Model) onTabChange() tea.Cmd {
 		m.updatePersonaView()
 	case tabDaemon:
 		// Refresh daemon status when switching to tab
+		m.loadActivity()
 		running, _, _ := daemon.IsRunning()
 		m.daemonRunning = running
 		if running {
@@ -460,27 +770,46 @@ func (m *Model) onTabChange() tea.Cmd {
 		}
 	case tabSettings:
 		m.cfg, _ = config.Load()
+		if m.cfg != nil {
+			m.apiKey, m.apiKeySource, _ = llm.ResolveAPIKey(m.cfg)
+		}
 	}
 	return nil
 }
 
 func (m *Model) handleEntriesTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// While typing a text filter every key belongs to the list
+	if m.entryList.FilterState() == list.Filtering {
+		var cmd tea.Cmd
+		m.entryList, cmd = m.entryList.Update(msg)
+		m.updateEntryView()
+		return m, cmd
+	}
+
 	switch msg.String() {
+	case "f":
+		m.cyclePersonaFilter()
+		return m, nil
+	case "d":
+		m.filterRange = (m.filterRange + 1) % len(dateRanges)
+		m.entryList.ResetSelected()
+		m.refreshEntriesFromDB()
+		return m, nil
+	case "u":
+		m.filterUnread = !m.filterUnread
+		m.entryList.ResetSelected()
+		m.refreshEntriesFromDB()
+		return m, nil
 	case "n":
+		if err := checkAPIKey(); err != nil {
+			m.genError = err
+			m.subMode = subModeError
+			return m, nil
+		}
 		m.loadPersonas()
 		if len(m.personas) == 0 {
-			// No personas exist - show first persona wizard with example
-			examples, _ := persona.ListExamples()
-			if len(examples) > 0 {
-				if example, err := persona.GetExample(examples[0]); err == nil {
-					m.initPersonaEditor(true, "", example.Name, example.Description)
-				} else {
-					m.initPersonaEditor(true, "", "", "")
-				}
-			} else {
-				m.initPersonaEditor(true, "", "", "")
-			}
-			m.subMode = subModeFirstPersona
+			// No personas exist - show first persona wizard
+			m.startFirstPersona()
 		} else {
 			m.subMode = subModeSelectPersona
 		}
@@ -490,6 +819,77 @@ func (m *Model) handleEntriesTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.recalculateLayout()
 		m.updateEntryView()
 		return m, nil
+	case "t":
+		if m.entryList.SelectedItem() != nil {
+			m.tagInput.SetValue("")
+			m.tagInput.Focus()
+			m.subMode = subModeAddTag
+			return m, textinput.Blink
+		}
+		return m, nil
+	case "p":
+		m.showPrompt = !m.showPrompt
+		m.updateEntryView()
+		return m, nil
+	case "R":
+		return m, m.selectRandomEntry()
+	case " ":
+		if sel := m.entryList.SelectedItem(); sel != nil {
+			return m, m.toggleMark(sel.(entryItem).entry)
+		}
+		return m, nil
+	case "c":
+		if len(m.marked) < 2 {
+			return m, m.setStatus("Mark two entries with space to compare them")
+		}
+		m.subMode = subModeCompare
+		m.updateCompareView()
+		return m, nil
+	case "h":
+		if sel := m.entryList.SelectedItem(); sel != nil {
+			revisions, err := m.loadRevisions(sel.(entryItem).entry.ID)
+			if err != nil {
+				m.genError = err
+				m.subMode = subModeError
+				return m, nil
+			}
+			m.revisions = revisions
+			m.revisionIdx = 0
+			m.subMode = subModeRevisions
+		}
+		return m, nil
+	case "y", "Y":
+		// y copies the text alone; Y copies it as Markdown with frontmatter
+		if sel := m.entryList.SelectedItem(); sel != nil {
+			e := sel.(entryItem).entry
+			text, status := e.Content, "Copied to clipboard"
+			if msg.String() == "Y" {
+				text, status = e.ToMarkdown(false), "Copied as Markdown"
+			}
+			if err := clipboard.WriteAll(text); err != nil {
+				m.genError = fmt.Errorf("failed to copy to clipboard: %w", err)
+				m.subMode = subModeError
+				return m, nil
+			}
+			return m, m.setStatus(status)
+		}
+		return m, nil
+	case "r":
+		if sel := m.entryList.SelectedItem(); sel != nil {
+			if err := checkAPIKey(); err != nil {
+				m.genError = err
+				m.subMode = subModeError
+				return m, nil
+			}
+			e := sel.(entryItem).entry
+			m.genPersona = e.Persona
+			m.genTarget = e.ID
+			m.subMode = subModeGenerating
+			m.generating = true
+			m.genError = nil
+			return m, tea.Batch(m.genSpinner.Tick, m.regenerateEntry())
+		}
+		return m, nil
 	}
 
 	// Pass to list
@@ -518,7 +918,10 @@ func (m *Model) handlePersonasTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Edit selected persona
 		if sel := m.personaList.SelectedItem(); sel != nil {
 			p := sel.(personaItem).persona
-			m.initPersonaEditor(false, p.Name, p.Name, p.Description)
+			m.initPersonaEditor(false, p.Name, p.Name, p.Body)
+			m.editorInclude = p.Include
+			m.editorLength = p.Length
+			m.editorUseHistory = p.UseHistory
 			m.subMode = subModePersonaEditor
 		}
 		return m, nil
@@ -561,6 +964,7 @@ func (m *Model) handleDaemonTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, m.startDaemon()
 	case "r":
+		m.loadActivity()
 		running, _, _ := daemon.IsRunning()
 		m.daemonRunning = running
 		if running {
@@ -600,10 +1004,63 @@ func (m *Model) handleSelectPersona(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// startFirstPersona opens the first persona wizard, offering the bundled
+// examples before the editor when there are any
+func (m *Model) startFirstPersona() {
+	m.examples = nil
+	names, _ := persona.ListExamples()
+	for _, name := range names {
+		if example, err := persona.GetExample(name); err == nil {
+			m.examples = append(m.examples, example)
+		}
+	}
+	m.exampleIdx = 0
+	m.pickingExample = len(m.examples) > 0
+	if !m.pickingExample {
+		m.initPersonaEditor(true, "", "", "")
+	}
+	m.subMode = subModeFirstPersona
+}
+
+// handleExamplePicker handles input while choosing the first persona's starting point
+func (m *Model) handleExamplePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.pickingExample = false
+		m.subMode = subModeNone
+		return m, nil
+	case "up", "k":
+		if m.exampleIdx > 0 {
+			m.exampleIdx--
+		}
+		return m, nil
+	case "down", "j":
+		// The extra row past the examples is "start from scratch"
+		if m.exampleIdx < len(m.examples) {
+			m.exampleIdx++
+		}
+		return m, nil
+	case "enter":
+		m.pickingExample = false
+		if m.exampleIdx < len(m.examples) {
+			example := m.examples[m.exampleIdx]
+			m.initPersonaEditor(true, "", example.Name, example.Description)
+		} else {
+			m.initPersonaEditor(true, "", "", "")
+		}
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
 // initPersonaEditor sets up the persona editor with initial values
 func (m *Model) initPersonaEditor(isNew bool, origName, name, desc string) {
 	m.editorIsNew = isNew
 	m.editorOrigName = origName
+	m.editorInclude = nil
+	m.editorLength = ""
+	m.editorUseHistory = nil
+	m.editorErr = nil
 	m.editorFocusName = true
 
 	m.editorNameInput.SetValue(name)
@@ -612,15 +1069,37 @@ func (m *Model) initPersonaEditor(isNew bool, origName, name, desc string) {
 	m.editorDescInput.SetValue(desc)
 	m.editorDescInput.Blur()
 
+	m.editorInitName = m.editorNameInput.Value()
+	m.editorInitDesc = m.editorDescInput.Value()
+
 	// Resize for current window
 	m.editorDescInput.SetWidth(m.width - 20)
 	m.editorDescInput.SetHeight(m.height - 15)
 }
 
+// inPersonaEditor reports whether the persona editor is open (not the example picker)
+func (m *Model) inPersonaEditor() bool {
+	switch m.subMode {
+	case subModePersonaEditor, subModeFirstPersona:
+		return !m.pickingExample
+	}
+	return false
+}
+
+// editorDirty reports whether the editor inputs differ from what it opened with
+func (m *Model) editorDirty() bool {
+	return m.editorNameInput.Value() != m.editorInitName || m.editorDescInput.Value() != m.editorInitDesc
+}
+
 // handlePersonaEditor handles input for the in-TUI persona editor
 func (m *Model) handlePersonaEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
+		// In the first persona wizard, step back to the example list
+		if m.subMode == subModeFirstPersona && len(m.examples) > 0 {
+			m.pickingExample = true
+			return m, nil
+		}
 		m.subMode = subModeNone
 		return m, nil
 
@@ -670,15 +1149,28 @@ func (m *Model) savePersonaFromEditor() (tea.Model, tea.Cmd) {
 		Name:        fileName,
 		Description: desc,
 	}
+	if !m.editorIsNew {
+		p.Include = m.editorInclude
+		p.Length = m.editorLength
+		p.UseHistory = m.editorUseHistory
+	}
 
-	// If editing and name changed, delete old file
+	// If editing and name changed, move the file and its entries to the new
+	// name first; RenamePersona puts the file back if the entries can't move
 	if !m.editorIsNew && m.editorOrigName != "" && m.editorOrigName != fileName {
-		persona.Delete(m.editorOrigName)
+		if _, err := entry.RenamePersona(m.editorOrigName, fileName); err != nil {
+			m.editorErr = err
+			return m, nil
+		}
+		m.editorOrigName = fileName
+		m.refreshEntriesFromDB()
 	}
 
 	if err := persona.Save(p); err != nil {
-		return m, nil // Could show error, for now just don't save
+		m.editorErr = fmt.Errorf("failed to save persona: %w", err)
+		return m, nil
 	}
+	m.editorErr = nil
 
 	// Reload personas
 	m.loadPersonas()
@@ -697,6 +1189,127 @@ func (m *Model) savePersonaFromEditor() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m *Model) handleAddTag(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.tagInput.Blur()
+		m.subMode = subModeNone
+		return m, nil
+	case "enter":
+		m.tagInput.Blur()
+		m.subMode = subModeNone
+
+		sel := m.entryList.SelectedItem()
+		if sel == nil || strings.TrimSpace(m.tagInput.Value()) == "" {
+			return m, nil
+		}
+		e := sel.(entryItem).entry
+
+		tagged, err := m.tagEntry(e.ID, m.tagInput.Value())
+		if err != nil {
+			m.genError = err
+			m.subMode = subModeError
+			return m, nil
+		}
+		for i, existing := range m.entries {
+			if existing.ID == tagged.ID {
+				m.entries[i] = tagged
+			}
+		}
+		m.refreshEntryList()
+		m.updateEntryView()
+		return m, m.setStatus("Tagged #" + strings.Join(tagged.Tags, " #"))
+	}
+
+	var cmd tea.Cmd
+	m.tagInput, cmd = m.tagInput.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) handleRevisions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.revisions = nil
+		m.subMode = subModeNone
+		return m, nil
+	case "up", "k":
+		if m.revisionIdx > 0 {
+			m.revisionIdx--
+		}
+		return m, nil
+	case "down", "j":
+		if m.revisionIdx < len(m.revisions)-1 {
+			m.revisionIdx++
+		}
+		return m, nil
+	case "enter":
+		if len(m.revisions) == 0 {
+			return m, nil
+		}
+		rev := m.revisions[m.revisionIdx]
+		m.revisions = nil
+		m.subMode = subModeNone
+
+		restored, err := m.restoreRevision(rev)
+		if err != nil {
+			m.genError = err
+			m.subMode = subModeError
+			return m, nil
+		}
+		for i, existing := range m.entries {
+			if existing.ID == restored.ID {
+				m.entries[i] = restored
+			}
+		}
+		m.refreshEntryList()
+		m.updateEntryView()
+		return m, m.setStatus("Restored version from " + timefmt.Format(rev.ReplacedAt, timefmt.Short))
+	}
+	return m, nil
+}
+
+// loadRevisions returns the earlier versions of an entry, newest first
+func (m *Model) loadRevisions(id int64) ([]*store.Revision, error) {
+	db, err := store.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	return db.ListRevisions(id)
+}
+
+// restoreRevision puts a revision's content back and returns the refreshed entry
+func (m *Model) restoreRevision(rev *store.Revision) (*store.Entry, error) {
+	db, err := store.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+	db.SetKeepRevisions(m.keepRevisions())
+
+	return db.RestoreRevision(rev.EntryID, rev.ID)
+}
+
+// keepRevisions reports whether replaced entry content is saved as a revision
+func (m *Model) keepRevisions() bool {
+	return m.cfg != nil && m.cfg.KeepRevisions
+}
+
+// tagEntry attaches a tag and returns the refreshed entry
+func (m *Model) tagEntry(id int64, tag string) (*store.Entry, error) {
+	db, err := store.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.AddTag(id, tag); err != nil {
+		return nil, err
+	}
+	return db.GetByID(id)
+}
+
 func (m *Model) handleDeleteConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "y", "Y":
@@ -728,20 +1341,99 @@ func (m *Model) handleDeleteConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m *Model) generateEntry() tea.Cmd {
-	return func() tea.Msg {
-		cfg, err := config.Load()
-		if err != nil {
-			return generateDoneMsg{err: err}
-		}
-		result, err := entry.Generate(context.Background(), cfg, m.genPersona)
-		if err != nil {
-			return generateDoneMsg{err: err}
+func (m *Model) handleQuitConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		m.quitting = true
+		return m, tea.Quit
+	case "n", "N", "esc":
+		m.subMode = m.quitReturnMode
+		return m, nil
+	}
+	return m, nil
+}
+
+// checkAPIKey resolves the API key the same way generation does, so a missing
+// key is reported before the spinner rather than after the request fails
+func checkAPIKey() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if _, _, err := llm.ResolveAPIKey(cfg); err != nil {
+		return err
+	}
+	return nil
+}
+
+// generationContext starts a new generation, returning its context (cancelled
+// by cancelGeneration) and sequence number
+func (m *Model) generationContext() (context.Context, int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.genCancel = cancel
+	m.genSeq++
+	return ctx, m.genSeq
+}
+
+// releaseGeneration cancels the current generation's context, which is a
+// no-op once it has finished
+func (m *Model) releaseGeneration() {
+	if m.genCancel != nil {
+		m.genCancel()
+		m.genCancel = nil
+	}
+}
+
+// cancelGeneration abandons the generation in progress and returns to the
+// entries view; the request is cancelled so nothing is saved
+func (m *Model) cancelGeneration() tea.Cmd {
+	m.releaseGeneration()
+	m.generating = false
+	m.genTarget = 0
+	m.subMode = subModeNone
+	return m.setStatus("Generation cancelled")
+}
+
+func (m *Model) generateEntry() tea.Cmd {
+	ctx, seq := m.generationContext()
+	personaName := m.genPersona
+	return func() tea.Msg {
+		cfg, err := config.Load()
+		if err != nil {
+			return generateDoneMsg{err: err, seq: seq}
+		}
+		result, err := entry.Generate(ctx, cfg, personaName)
+		if err != nil {
+			return generateDoneMsg{err: err, seq: seq}
+		}
+		return generateDoneMsg{entry: result.Entry, seq: seq}
+	}
+}
+
+func (m *Model) regenerateEntry() tea.Cmd {
+	ctx, seq := m.generationContext()
+	target := m.genTarget
+	return func() tea.Msg {
+		cfg, err := config.Load()
+		if err != nil {
+			return regenerateDoneMsg{err: err, seq: seq}
+		}
+		result, err := entry.Regenerate(ctx, cfg, target)
+		if err != nil {
+			return regenerateDoneMsg{err: err, seq: seq}
 		}
-		return generateDoneMsg{entry: result.Entry}
+		return regenerateDoneMsg{entry: result.Entry, seq: seq}
 	}
 }
 
+// setStatus shows a transient message in the help bar
+func (m *Model) setStatus(msg string) tea.Cmd {
+	m.statusMsg = msg
+	return tea.Tick(statusDuration, func(time.Time) tea.Msg {
+		return clearStatusMsg{}
+	})
+}
+
 func (m *Model) startDaemon() tea.Cmd {
 	return func() tea.Msg {
 		executable, err := os.Executable()
@@ -842,11 +1534,48 @@ func (m *Model) loadPersonas() {
 func (m *Model) refreshEntryList() {
 	items := make([]list.Item, len(m.entries))
 	for i, e := range m.entries {
-		items[i] = entryItem{entry: e}
+		item := newEntryItem(e, m.cfg, m.previewLen)
+		item.marked = m.isMarked(e.ID)
+		items[i] = item
 	}
 	m.entryList.SetItems(items)
 }
 
+// isMarked reports whether an entry is marked for the compare view
+func (m *Model) isMarked(id int64) bool {
+	for _, e := range m.marked {
+		if e.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleMark marks or unmarks an entry for comparison; marking a third entry
+// drops the oldest mark
+func (m *Model) toggleMark(e *store.Entry) tea.Cmd {
+	if m.isMarked(e.ID) {
+		for i, marked := range m.marked {
+			if marked.ID == e.ID {
+				m.marked = append(m.marked[:i], m.marked[i+1:]...)
+				break
+			}
+		}
+		m.refreshEntryList()
+		return m.setStatus(fmt.Sprintf("Unmarked #%d", e.ID))
+	}
+
+	if len(m.marked) == 2 {
+		m.marked = m.marked[1:]
+	}
+	m.marked = append(m.marked, e)
+	m.refreshEntryList()
+	if len(m.marked) == 2 {
+		return m.setStatus(fmt.Sprintf("Marked #%d, press c to compare with #%d", e.ID, m.marked[0].ID))
+	}
+	return m.setStatus(fmt.Sprintf("Marked #%d", e.ID))
+}
+
 func (m *Model) refreshEntriesFromDB() {
 	db, err := store.Open()
 	if err != nil {
@@ -854,18 +1583,164 @@ func (m *Model) refreshEntriesFromDB() {
 	}
 	defer db.Close()
 
-	entries, err := db.List(100)
+	entries, err := db.ListFiltered(m.entryFilter(time.Now()))
 	if err != nil {
 		return
 	}
 	m.entries = entries
 	m.refreshEntryList()
+	m.loadUnreadCount()
 	m.updateEntryView()
 }
 
+// selectRandomEntry jumps the selection to a random entry matching the
+// current filters, loading it into the list if it's older than what is shown
+func (m *Model) selectRandomEntry() tea.Cmd {
+	db, err := store.Open()
+	if err != nil {
+		return nil
+	}
+	defer db.Close()
+
+	e, err := db.Random(m.entryFilter(time.Now()))
+	if err != nil {
+		m.genError = err
+		m.subMode = subModeError
+		return nil
+	}
+	if e == nil {
+		return m.setStatus("No entries to pick from")
+	}
+
+	idx := -1
+	for i, existing := range m.entries {
+		if existing.ID == e.ID {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		// Keep the list newest first
+		idx = sort.Search(len(m.entries), func(i int) bool {
+			return !m.entries[i].CreatedAt.After(e.CreatedAt)
+		})
+		m.entries = append(m.entries[:idx], append([]*store.Entry{e}, m.entries[idx:]...)...)
+		m.refreshEntryList()
+	}
+
+	if m.entryList.FilterState() != list.Unfiltered {
+		m.entryList.ResetFilter()
+	}
+	m.entryList.Select(idx)
+	m.updateEntryView()
+	return m.setStatus(fmt.Sprintf("Random pick: #%d", e.ID))
+}
+
+// loadUnreadCount refreshes the unread badge on the Entries tab
+func (m *Model) loadUnreadCount() {
+	db, err := store.Open()
+	if err != nil {
+		return
+	}
+	defer db.Close()
+
+	if count, err := db.CountUnread(); err == nil {
+		m.unreadCount = count
+	}
+}
+
+// markRead flags an entry as read once it is shown in the detail pane
+func (m *Model) markRead(e *store.Entry) {
+	db, err := store.Open()
+	if err != nil {
+		return
+	}
+	defer db.Close()
+
+	if err := db.MarkRead(e.ID); err != nil {
+		return
+	}
+	e.IsRead = true
+	if m.unreadCount > 0 {
+		m.unreadCount--
+	}
+}
+
+// tabLabel returns the tab bar label, with the unread count on Entries
+func (m *Model) tabLabel(t tab) string {
+	if t == tabEntries && m.unreadCount > 0 {
+		return fmt.Sprintf("%s (%d)", tabNames[t], m.unreadCount)
+	}
+	return tabNames[t]
+}
+
+// entryFilter builds the store query for the active persona and date filters
+func (m *Model) entryFilter(now time.Time) store.Filter {
+	f := store.Filter{Persona: m.filterPersona, Unread: m.filterUnread, Limit: m.entryLimit}
+	if days := dateRanges[m.filterRange].days; days > 0 {
+		local := timefmt.In(now)
+		today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+		f.Since = today.AddDate(0, 0, -(days - 1))
+	}
+	return f
+}
+
+// cyclePersonaFilter advances the persona filter through every persona with
+// entries, then back to showing all
+func (m *Model) cyclePersonaFilter() {
+	db, err := store.Open()
+	if err != nil {
+		return
+	}
+	personas, err := db.ListPersonas()
+	db.Close()
+	if err != nil {
+		return
+	}
+
+	m.filterPersona = nextPersona(personas, m.filterPersona)
+	m.refreshEntriesFromDB()
+}
+
+// nextPersona returns the persona after current in personas, or "" (all)
+// after the last one or when current is no longer present
+func nextPersona(personas []string, current string) string {
+	if current == "" {
+		if len(personas) == 0 {
+			return ""
+		}
+		return personas[0]
+	}
+	for i, p := range personas {
+		if p == current && i+1 < len(personas) {
+			return personas[i+1]
+		}
+	}
+	return ""
+}
+
+// filterLabel describes the active persona and date filters, or "" for none
+func (m *Model) filterLabel() string {
+	var parts []string
+	if m.filterPersona != "" {
+		parts = append(parts, formatPersonaName(m.filterPersona))
+	}
+	if m.filterRange != 0 {
+		parts = append(parts, dateRanges[m.filterRange].label)
+	}
+	if m.filterUnread {
+		parts = append(parts, "unread")
+	}
+	return strings.Join(parts, " · ")
+}
+
 func (m *Model) recalculateLayout() {
 	contentHeight := m.height - 4 // tab bar + help bar
 
+	if m.subMode == subModeCompare {
+		m.updateCompareView()
+	}
+
 	switch m.activeTab {
 	case tabEntries:
 		listWidth := m.width / 4
@@ -881,6 +1756,16 @@ func (m *Model) recalculateLayout() {
 		m.entryList.SetSize(listWidth, contentHeight-2)
 		m.entryView = viewport.New(viewWidth, contentHeight-2)
 
+		// Fit entry previews to the list, leaving room for the ID prefix and delegate padding
+		previewLen := listWidth - 10
+		if previewLen < 10 {
+			previewLen = 10
+		}
+		if previewLen != m.previewLen {
+			m.previewLen = previewLen
+			m.refreshEntryList()
+		}
+
 	case tabPersonas:
 		listWidth := m.width / 3
 		if listWidth < 25 {
@@ -905,14 +1790,43 @@ func (m *Model) updateEntryView() {
 	}
 
 	e := sel.(entryItem).entry
+	if !e.IsRead {
+		m.markRead(e)
+	}
 	var content strings.Builder
 
 	content.WriteString(entryTitleStyle.Render(fmt.Sprintf("Entry #%d", e.ID)))
 	content.WriteString("\n\n")
 	content.WriteString(lipgloss.NewStyle().Foreground(colorFgDim).Render(
-		e.CreatedAt.Format("Monday, January 02, 2006 at 3:04 PM")))
+		timefmt.Format(e.CreatedAt, timefmt.Long) + " · " + readingTime(e.Content)))
+	if e.ModelID != "" {
+		content.WriteString("\n")
+		content.WriteString(lipgloss.NewStyle().Foreground(colorFgDim).Render(e.ModelID))
+	}
+	if len(e.Tags) > 0 {
+		content.WriteString("\n")
+		content.WriteString(lipgloss.NewStyle().Foreground(colorAccent).Render(
+			"#" + strings.Join(e.Tags, " #")))
+	}
 	content.WriteString("\n\n")
 
+	if m.showPrompt {
+		content.WriteString(entryTitleStyle.Render("Prompt"))
+		content.WriteString("\n\n")
+		if e.PromptText == "" {
+			content.WriteString(lipgloss.NewStyle().Foreground(colorFgDim).Render("No prompt stored for this entry"))
+		} else {
+			content.WriteString(e.PromptText)
+		}
+		m.entryView.SetContent(content.String())
+		return
+	}
+
+	if chart := metricsChart(e.MetricsSnapshot); chart != "" {
+		content.WriteString(chart)
+		content.WriteString("\n")
+	}
+
 	rendered, err := m.renderer.Render(e.Content)
 	if err != nil {
 		content.WriteString(e.Content)
@@ -923,6 +1837,78 @@ func (m *Model) updateEntryView() {
 	m.entryView.SetContent(content.String())
 }
 
+// chartWidth is how many cells a full (100%) bar spans in the entry detail chart
+const chartWidth = 20
+
+// barBlocks are the partial blocks for each eighth of a cell
+var barBlocks = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// metricsChart draws CPU, memory, and disk usage as bars, or nothing when the
+// entry has no recorded metrics (e.g. it was imported without them)
+func metricsChart(snap *metrics.Snapshot) string {
+	if snap == nil || (snap.MemoryTotal == 0 && snap.DiskTotal == 0) {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, bar := range []struct {
+		label   string
+		percent float64
+	}{
+		{"CPU", snap.CPUPercent},
+		{"Memory", snap.MemoryPercent},
+		{"Disk", snap.DiskPercent},
+	} {
+		sb.WriteString(labelStyle.Width(8).Render(bar.label))
+		sb.WriteString(renderBar(bar.percent, chartWidth))
+		sb.WriteString(lipgloss.NewStyle().Foreground(colorFgDim).Render(fmt.Sprintf(" %3.0f%%", bar.percent)))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// renderBar draws percent as a bar width cells wide, in eighths of a cell,
+// colored by barColor over a dim track
+func renderBar(percent float64, width int) string {
+	percent = max(0, min(percent, 100))
+	eighths := int(percent / 100 * float64(width*8))
+	full, part := eighths/8, eighths%8
+
+	filled := strings.Repeat("█", full) + barBlocks[part]
+	used := full
+	if part > 0 {
+		used++
+	}
+	track := strings.Repeat("░", width-used)
+
+	return lipgloss.NewStyle().Foreground(barColor(percent)).Render(filled) +
+		lipgloss.NewStyle().Foreground(colorBorder).Render(track)
+}
+
+// barColor shades a usage bar: calm below 60%, warning from 60%, alarm from 85%
+func barColor(percent float64) lipgloss.Color {
+	switch {
+	case percent >= 85:
+		return colorError
+	case percent >= 60:
+		return colorWarn
+	default:
+		return colorOK
+	}
+}
+
+// readingWPM is the reading speed behind the estimate in the entry detail
+const readingWPM = 200
+
+// readingTime estimates how long an entry takes to read, e.g. "2 min read"
+func readingTime(content string) string {
+	words := len(strings.Fields(content))
+	if words < readingWPM {
+		return "< 1 min read"
+	}
+	return fmt.Sprintf("%d min read", (words+readingWPM/2)/readingWPM)
+}
+
 func (m *Model) updatePersonaView() {
 	if len(m.personas) == 0 {
 		m.personaView.SetContent(lipgloss.NewStyle().Foreground(colorFgDim).Render(
@@ -958,6 +1944,10 @@ func (m *Model) updatePersonaView() {
 }
 
 func (m *Model) renderEmptyEntries() string {
+	if label := m.filterLabel(); label != "" {
+		return lipgloss.NewStyle().Foreground(colorFgDim).Render(
+			fmt.Sprintf("\n  No entries for %s.\n\n  Press 'f', 'd', or 'u' to change the filter.", label))
+	}
 	return lipgloss.NewStyle().Foreground(colorFgDim).Render(
 		"\n  No entries yet.\n\n  Press 'n' to create your first entry.")
 }
@@ -988,14 +1978,14 @@ func (m *Model) View() string {
 
 func (m *Model) renderTabBar() string {
 	// Tab navigation
-	tabs := []string{"Entries", "Personas", "Daemon", "Settings"}
 	var rendered []string
 
-	for i, t := range tabs {
+	for i := range tabNames {
+		label := m.tabLabel(tab(i))
 		if tab(i) == m.activeTab {
-			rendered = append(rendered, activeTabStyle.Render(t))
+			rendered = append(rendered, activeTabStyle.Render(label))
 		} else {
-			rendered = append(rendered, tabStyle.Render(t))
+			rendered = append(rendered, tabStyle.Render(label))
 		}
 	}
 
@@ -1010,14 +2000,25 @@ func (m *Model) renderContent() string {
 		return m.renderGenerating()
 	case subModeDeleteConfirm:
 		return m.renderDeleteConfirm()
+	case subModeQuitConfirm:
+		return m.renderQuitConfirm()
 	case subModeSelectPersona:
 		return m.renderSelectPersona()
 	case subModePersonaEditor:
 		return m.renderPersonaEditor(false)
 	case subModeFirstPersona:
+		if m.pickingExample {
+			return m.renderExamplePicker()
+		}
 		return m.renderPersonaEditor(true)
 	case subModeError:
 		return m.renderError()
+	case subModeAddTag:
+		return m.renderAddTag()
+	case subModeRevisions:
+		return m.renderRevisions()
+	case subModeCompare:
+		return m.renderCompare()
 	}
 
 	switch m.activeTab {
@@ -1093,6 +2094,28 @@ func (m *Model) renderDaemonTab() string {
 
 	content.WriteString("\n")
 
+	// Activity
+	if len(m.activity) > 0 {
+		content.WriteString(lipgloss.NewStyle().Foreground(colorAccent).Render("Activity"))
+		content.WriteString("\n")
+
+		total, peak := 0, 0
+		for _, n := range m.activity {
+			total += n
+			peak = max(peak, n)
+		}
+
+		// contentStyle padding (4) plus the label column
+		sparkWidth := m.width - 4 - labelStyle.GetWidth()
+		content.WriteString(labelStyle.Render(fmt.Sprintf("Last %d days", len(m.activity))))
+		content.WriteString(statusRunning.Render(sparkline(m.activity, sparkWidth)))
+		content.WriteString("\n")
+		content.WriteString(labelStyle.Render(""))
+		content.WriteString(lipgloss.NewStyle().Foreground(colorFgDim).Render(
+			fmt.Sprintf("%d entries, peak %d/day", total, peak)))
+		content.WriteString("\n\n")
+	}
+
 	// Config
 	content.WriteString(lipgloss.NewStyle().Foreground(colorAccent).Render("Configuration"))
 	content.WriteString("\n")
@@ -1112,8 +2135,9 @@ func (m *Model) renderDaemonTab() string {
 	}
 
 	content.WriteString("\n")
+	cfgPath, _ := config.Path()
 	content.WriteString(lipgloss.NewStyle().Foreground(colorFgDim).Render(
-		"Edit ~/.config/jernel/config.yaml to change daemon settings."))
+		fmt.Sprintf("Edit %s to change daemon settings.", cfgPath)))
 
 	return contentStyle.Height(contentHeight).Render(content.String())
 }
@@ -1132,8 +2156,7 @@ func (m *Model) renderSettingsTab() string {
 
 	cfgPath, _ := config.Path()
 	personaDir, _ := persona.Dir()
-	homeDir, _ := os.UserHomeDir()
-	dbPath := homeDir + "/.local/share/jernel/jernel.db"
+	dbPath, _ := store.DBPath()
 
 	content.WriteString(labelStyle.Render("Config"))
 	content.WriteString(valueStyle.Render(cfgPath))
@@ -1160,13 +2183,19 @@ func (m *Model) renderSettingsTab() string {
 		content.WriteString(valueStyle.Render(m.cfg.Model))
 		content.WriteString("\n")
 
-		apiKey := os.Getenv("ANTHROPIC_API_KEY")
+		if m.cfg.BaseURL != "" {
+			content.WriteString(labelStyle.Render("Base URL"))
+			content.WriteString(valueStyle.Render(m.cfg.BaseURL))
+			content.WriteString("\n")
+		}
+
+		apiKey := m.apiKey
 		keyStatus := "Not set"
 		if apiKey != "" {
 			if len(apiKey) > 8 {
-				keyStatus = "****..." + apiKey[len(apiKey)-4:] + " (env)"
+				keyStatus = "****..." + apiKey[len(apiKey)-4:] + " (" + m.apiKeySource + ")"
 			} else {
-				keyStatus = "**** (env)"
+				keyStatus = "**** (" + m.apiKeySource + ")"
 			}
 		}
 		content.WriteString(labelStyle.Render("API Key"))
@@ -1176,7 +2205,7 @@ func (m *Model) renderSettingsTab() string {
 
 	content.WriteString("\n")
 	content.WriteString(lipgloss.NewStyle().Foreground(colorFgDim).Render(
-		"Edit ~/.config/jernel/config.yaml to change settings."))
+		fmt.Sprintf("Edit %s to change settings.", cfgPath)))
 
 	return contentStyle.Height(contentHeight).Render(content.String())
 }
@@ -1221,17 +2250,32 @@ func (m *Model) renderMetricsPanel() string {
 			Render(content.String())
 	}
 
+	content.WriteString(renderSnapshotMetrics(snap, panelWidth))
+
+	return lipgloss.NewStyle().
+		Width(panelWidth).
+		Height(panelHeight).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(colorBorder).
+		Padding(0, 2).
+		Render(content.String())
+}
+
+// renderSnapshotMetrics lists a snapshot's metrics as label/value lines
+func renderSnapshotMetrics(snap *metrics.Snapshot, width int) string {
+	var sb strings.Builder
 	addMetric := func(label, value string) {
-		content.WriteString(labelStyle.Width(10).Render(label))
-		content.WriteString(valueStyle.Render(value))
-		content.WriteString("\n")
+		sb.WriteString(labelStyle.Width(10).Render(label))
+		sb.WriteString(valueStyle.Render(value))
+		sb.WriteString("\n")
 	}
 
 	// Machine identity
 	addMetric("Type", string(snap.MachineType))
 	addMetric("Time", string(snap.TimeOfDay))
 
-	content.WriteString("\n")
+	sb.WriteString("\n")
 
 	// Core metrics
 	addMetric("Uptime", formatDuration(snap.Uptime))
@@ -1252,21 +2296,31 @@ func (m *Model) renderMetricsPanel() string {
 		addMetric("Procs", fmt.Sprintf("%d", *snap.ProcessCount))
 	}
 
+	if snap.TopProcess != nil {
+		addMetric("Top", truncate(snap.TopProcess.Name, width-14))
+	}
+
 	if snap.LoadAverages != nil {
-		content.WriteString("\n")
+		sb.WriteString("\n")
 		addMetric("Load 1m", fmt.Sprintf("%.2f", snap.LoadAverages.Load1))
 		addMetric("Load 5m", fmt.Sprintf("%.2f", snap.LoadAverages.Load5))
 		addMetric("Load 15m", fmt.Sprintf("%.2f", snap.LoadAverages.Load15))
 	}
 
 	if snap.NetworkIO != nil {
-		content.WriteString("\n")
+		sb.WriteString("\n")
 		addMetric("Net ↑", fmt.Sprintf("%.2f GB", float64(snap.NetworkIO.BytesSent)/1024/1024/1024))
 		addMetric("Net ↓", fmt.Sprintf("%.2f GB", float64(snap.NetworkIO.BytesRecv)/1024/1024/1024))
 	}
 
+	if snap.DiskIO != nil {
+		sb.WriteString("\n")
+		addMetric("Disk R", fmt.Sprintf("%.2f GB", float64(snap.DiskIO.ReadBytes)/1024/1024/1024))
+		addMetric("Disk W", fmt.Sprintf("%.2f GB", float64(snap.DiskIO.WriteBytes)/1024/1024/1024))
+	}
+
 	if snap.Battery != nil {
-		content.WriteString("\n")
+		sb.WriteString("\n")
 		status := fmt.Sprintf("%.0f%%", snap.Battery.Percent)
 		// if snap.Battery.Charging {
 		// 	status += " ⚡"
@@ -1275,7 +2329,7 @@ func (m *Model) renderMetricsPanel() string {
 	}
 
 	if snap.GPU != nil && snap.GPU.Usage != nil {
-		content.WriteString("\n")
+		sb.WriteString("\n")
 		addMetric("GPU", fmt.Sprintf("%.1f%%", *snap.GPU.Usage))
 	}
 
@@ -1293,24 +2347,26 @@ func (m *Model) renderMetricsPanel() string {
 		addMetric("Fan", fmt.Sprintf("%.0f RPM", avgRPM))
 	}
 
-	return lipgloss.NewStyle().
-		Width(panelWidth).
-		Height(panelHeight).
-		BorderStyle(lipgloss.NormalBorder()).
-		BorderLeft(true).
-		BorderForeground(colorBorder).
-		Padding(0, 2).
-		Render(content.String())
+	return sb.String()
 }
 
 func (m *Model) renderGenerating() string {
 	contentHeight := m.height - 4
 
+	title := "Generating Entry"
+	status := " Creating with persona " + m.genPersona + "..."
+	if m.genTarget != 0 {
+		title = "Regenerating Entry"
+		status = fmt.Sprintf(" Rewriting #%d with persona %s...", m.genTarget, m.genPersona)
+	}
+
 	content := lipgloss.JoinVertical(lipgloss.Center,
 		"",
-		titleStyle.Render("Generating Entry"),
+		titleStyle.Render(title),
+		"",
+		m.genSpinner.View()+status,
 		"",
-		m.genSpinner.View()+" Creating with persona "+m.genPersona+"...",
+		lipgloss.NewStyle().Foreground(colorFgDim).Render("Press esc to cancel"),
 	)
 
 	return lipgloss.Place(m.width, contentHeight,
@@ -1384,9 +2440,13 @@ func (m *Model) renderPersonaEditor(isFirstTime bool) string {
 	// Instructions for first-time users
 	var intro string
 	if isFirstTime {
-		intro = lipgloss.NewStyle().Foreground(colorFgDim).Width(60).Render(
-			"Personas define the voice and personality for your journal entries. " +
-				"Here's an example to get you started - feel free to edit it or create your own.")
+		text := "Personas define the voice and personality for your journal entries. "
+		if m.exampleIdx < len(m.examples) {
+			text += "Edit the example as much as you like before saving."
+		} else {
+			text += "Give yours a name and describe how it should write."
+		}
+		intro = lipgloss.NewStyle().Foreground(colorFgDim).Width(60).Render(text)
 	}
 
 	// Name field
@@ -1416,8 +2476,12 @@ func (m *Model) renderPersonaEditor(isFirstTime bool) string {
 	)
 
 	// Help text
+	escHelp := "Esc: cancel"
+	if isFirstTime && len(m.examples) > 0 {
+		escHelp = "Esc: back"
+	}
 	help := lipgloss.NewStyle().Foreground(colorFgDim).Render(
-		"Tab: switch field  Ctrl+S: save  Esc: cancel")
+		"Tab: switch field  Ctrl+S: save  " + escHelp)
 
 	// Combine all elements
 	var elements []string
@@ -1430,9 +2494,11 @@ func (m *Model) renderPersonaEditor(isFirstTime bool) string {
 		nameField,
 		"",
 		descField,
-		"",
-		help,
 	)
+	if m.editorErr != nil {
+		elements = append(elements, "", errorStyle.Width(60).Render("Error: "+m.editorErr.Error()))
+	}
+	elements = append(elements, "", help)
 
 	content := lipgloss.JoinVertical(lipgloss.Left, elements...)
 
@@ -1442,6 +2508,115 @@ func (m *Model) renderPersonaEditor(isFirstTime bool) string {
 		content)
 }
 
+func (m *Model) handleCompare(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "c":
+		m.subMode = subModeNone
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.compareView, cmd = m.compareView.Update(msg)
+	return m, cmd
+}
+
+// updateCompareView lays out the marked entries in two columns, the older
+// entry on the left, scrolling together in one viewport
+func (m *Model) updateCompareView() {
+	if len(m.marked) < 2 {
+		return
+	}
+	left, right := m.marked[0], m.marked[1]
+	if right.CreatedAt.Before(left.CreatedAt) {
+		left, right = right, left
+	}
+
+	contentHeight := m.height - 4
+	columnWidth := (m.width - 8) / 2
+	if columnWidth < 20 {
+		columnWidth = 20
+	}
+
+	// Equal heights keep the divider running the full length of both columns
+	leftContent := renderCompareColumn(left, columnWidth-2)
+	rightContent := renderCompareColumn(right, columnWidth-4)
+	height := max(lipgloss.Height(leftContent), lipgloss.Height(rightContent))
+
+	leftColumn := lipgloss.NewStyle().Width(columnWidth).Height(height).Padding(0, 2, 0, 0).
+		Render(leftContent)
+	rightColumn := viewportStyle.Width(columnWidth).Height(height).
+		Render(rightContent)
+
+	m.compareView = viewport.New(m.width-4, contentHeight-4)
+	m.compareView.SetContent(lipgloss.JoinHorizontal(lipgloss.Top, leftColumn, rightColumn))
+}
+
+// renderCompareColumn renders one entry's header, content, and metrics for the compare view
+func renderCompareColumn(e *store.Entry, width int) string {
+	var content strings.Builder
+
+	content.WriteString(entryTitleStyle.Render(fmt.Sprintf("Entry #%d", e.ID)))
+	content.WriteString("\n\n")
+	content.WriteString(lipgloss.NewStyle().Foreground(colorFgDim).Render(
+		timefmt.Format(e.CreatedAt, timefmt.Long)))
+	content.WriteString("\n")
+	content.WriteString(valueStyle.Render(e.Persona))
+	if len(e.Tags) > 0 {
+		content.WriteString("\n")
+		content.WriteString(lipgloss.NewStyle().Foreground(colorAccent).Render(
+			"#" + strings.Join(e.Tags, " #")))
+	}
+	content.WriteString("\n\n")
+	content.WriteString(lipgloss.NewStyle().Width(width).Render(strings.TrimSpace(e.Content)))
+	content.WriteString("\n\n")
+
+	content.WriteString(titleStyle.Render("System"))
+	content.WriteString("\n\n")
+	if e.MetricsSnapshot == nil {
+		content.WriteString(lipgloss.NewStyle().Foreground(colorFgDim).Render("No system metrics"))
+	} else {
+		content.WriteString(renderSnapshotMetrics(e.MetricsSnapshot, width))
+	}
+
+	return content.String()
+}
+
+func (m *Model) renderCompare() string {
+	contentHeight := m.height - 4
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		"",
+		titleStyle.Render("Compare Entries"),
+		"",
+		m.compareView.View(),
+	)
+
+	return lipgloss.NewStyle().Padding(0, 2).Height(contentHeight).Render(content)
+}
+
+func (m *Model) renderQuitConfirm() string {
+	contentHeight := m.height - 4
+
+	name := strings.TrimSpace(m.editorNameInput.Value())
+	message := "Discard your unsaved persona changes and quit?"
+	if name != "" {
+		message = fmt.Sprintf("Discard your unsaved changes to \"%s\" and quit?", name)
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Center,
+		"",
+		titleStyle.Render("Unsaved Changes"),
+		"",
+		message,
+		"",
+		lipgloss.NewStyle().Foreground(colorFgDim).Render("Press y to discard and quit, n to keep editing"),
+	)
+
+	return lipgloss.Place(m.width, contentHeight,
+		lipgloss.Center, lipgloss.Center,
+		content)
+}
+
 func (m *Model) renderDeleteConfirm() string {
 	contentHeight := m.height - 4
 
@@ -1460,7 +2635,7 @@ func (m *Model) renderDeleteConfirm() string {
 	// Warning text if entries will be deleted
 	var warning string
 	if m.deleteEntryCount > 0 {
-		warning = errorStyle.Render("This action cannot be undone.")
+		warning = errorStyle.Render("Entries will be moved to the trash (restore with 'jernel trash').")
 	}
 
 	elements := []string{
@@ -1506,6 +2681,159 @@ func (m *Model) renderError() string {
 		content)
 }
 
+func (m *Model) renderAddTag() string {
+	contentHeight := m.height - 4
+
+	target := ""
+	if sel := m.entryList.SelectedItem(); sel != nil {
+		target = fmt.Sprintf("Entry #%d", sel.(entryItem).entry.ID)
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Center,
+		"",
+		titleStyle.Render("Add Tag"),
+		"",
+		lipgloss.NewStyle().Foreground(colorFgDim).Render(target),
+		"",
+		m.tagInput.View(),
+		"",
+		lipgloss.NewStyle().Foreground(colorFgDim).Render("Enter: save  Esc: cancel"),
+	)
+
+	return lipgloss.Place(m.width, contentHeight,
+		lipgloss.Center, lipgloss.Center,
+		content)
+}
+
+func (m *Model) renderExamplePicker() string {
+	contentHeight := m.height - 4
+	dim := lipgloss.NewStyle().Foreground(colorFgDim)
+
+	title := titleStyle.Render("Create Your First Persona")
+	intro := dim.Width(60).Render(
+		"Personas define the voice and personality for your journal entries. " +
+			"Start from one of these examples or write your own.")
+
+	// Left side: example names, then the from-scratch option
+	labels := make([]string, 0, len(m.examples)+1)
+	for _, example := range m.examples {
+		labels = append(labels, example.Name)
+	}
+	labels = append(labels, "Start from scratch")
+
+	var rows []string
+	for i, label := range labels {
+		if i == m.exampleIdx {
+			rows = append(rows, lipgloss.NewStyle().Foreground(colorAccent).Render("> "+label))
+		} else {
+			rows = append(rows, lipgloss.NewStyle().Foreground(colorFg).Render("  "+label))
+		}
+	}
+	listView := lipgloss.NewStyle().
+		Padding(1, 2).
+		Width(m.width / 3).
+		Render(strings.Join(rows, "\n"))
+
+	// Right side: the selected example's description and a canned sample entry
+	var previewContent string
+	if m.exampleIdx < len(m.examples) {
+		example := m.examples[m.exampleIdx]
+		previewContent = example.Description
+		if sample := persona.ExampleSample(example.Name); sample != "" {
+			previewContent += "\n\n" + dim.Render("Sample entry:") + "\n\n" + dim.Render(sample)
+		}
+	} else {
+		previewContent = dim.Render("An empty persona for you to name and describe.")
+	}
+	previewStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(colorBorder).
+		Padding(1, 2).
+		Width(m.width - m.width/3 - 4).
+		Height(contentHeight - 10)
+	preview := previewStyle.Render(previewContent)
+
+	panels := lipgloss.JoinHorizontal(lipgloss.Top, listView, preview)
+	help := dim.Render("↑↓: choose  Enter: continue  Esc: cancel")
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		"",
+		title,
+		"",
+		intro,
+		"",
+		panels,
+		"",
+		help,
+	)
+
+	return lipgloss.NewStyle().Height(contentHeight).Render(content)
+}
+
+func (m *Model) renderRevisions() string {
+	contentHeight := m.height - 4
+	dim := lipgloss.NewStyle().Foreground(colorFgDim)
+
+	target := ""
+	if sel := m.entryList.SelectedItem(); sel != nil {
+		target = fmt.Sprintf("Entry #%d", sel.(entryItem).entry.ID)
+	}
+	title := titleStyle.Render("Revisions of " + target)
+
+	if len(m.revisions) == 0 {
+		hint := "No earlier versions yet."
+		if !m.keepRevisions() {
+			hint += "\n\nSet keep_revisions: true in config.yaml to keep the previous\ncontent each time an entry is regenerated."
+		}
+		content := lipgloss.JoinVertical(lipgloss.Center,
+			"",
+			title,
+			"",
+			dim.Render(hint),
+		)
+		return lipgloss.Place(m.width, contentHeight,
+			lipgloss.Center, lipgloss.Center,
+			content)
+	}
+
+	// Left side: revision timestamps, newest first
+	var rows []string
+	for i, rev := range m.revisions {
+		label := timefmt.Format(rev.ReplacedAt, timefmt.Short) + " · " + rev.ModelID
+		if i == m.revisionIdx {
+			rows = append(rows, lipgloss.NewStyle().Foreground(colorAccent).Render("> "+label))
+		} else {
+			rows = append(rows, lipgloss.NewStyle().Foreground(colorFg).Render("  "+label))
+		}
+	}
+	listView := lipgloss.NewStyle().
+		Padding(1, 2).
+		Width(m.width / 3).
+		Render(strings.Join(rows, "\n"))
+
+	// Right side: the selected revision's content
+	previewStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(colorBorder).
+		Padding(1, 2).
+		Width(m.width - m.width/3 - 4).
+		Height(contentHeight - 6)
+	preview := previewStyle.Render(m.revisions[m.revisionIdx].Content)
+
+	panels := lipgloss.JoinHorizontal(lipgloss.Top, listView, preview)
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		"",
+		title,
+		"",
+		panels,
+	)
+
+	return lipgloss.NewStyle().Height(contentHeight).Render(content)
+}
+
 func (m *Model) renderHelpBar() string {
 	var keys []string
 	add := func(key, desc string) {
@@ -1524,15 +2852,41 @@ func (m *Model) renderHelpBar() string {
 	case subModePersonaEditor, subModeFirstPersona:
 		// Help shown in editor view
 		keys = nil
-	case subModeDeleteConfirm, subModeError:
+	case subModeRevisions:
+		keys = nil
+		if len(m.revisions) > 0 {
+			add("Enter", "restore")
+			add("↑↓", "navigate")
+		}
+		add("Esc", "back")
+	case subModeCompare:
+		keys = nil
+		add("↑↓", "scroll")
+		add("Esc", "back")
+	case subModeDeleteConfirm, subModeQuitConfirm, subModeError, subModeAddTag:
 		// Help shown in modal
 		keys = nil
 	default:
 		switch m.activeTab {
 		case tabEntries:
 			add("n", "new")
+			add("r", "regenerate")
+			add("y/Y", "copy")
+			add("t", "tag")
+			add("p", "prompt")
+			add("h", "history")
+			add("R", "random")
+			add("space", "mark")
+			add("c", "compare")
 			add("s", "system")
+			add("/", "search")
+			add("f", "persona")
+			add("d", "dates")
+			add("u", "unread")
 			add("↑↓", "navigate")
+			if label := m.filterLabel(); label != "" {
+				keys = append(keys, statusRunning.Render("filter: "+label))
+			}
 		case tabPersonas:
 			add("c", "create")
 			add("e", "edit")
@@ -1550,6 +2904,10 @@ func (m *Model) renderHelpBar() string {
 		}
 	}
 
+	if m.statusMsg != "" {
+		keys = append(keys, statusRunning.Render(m.statusMsg))
+	}
+
 	if len(keys) == 0 {
 		return ""
 	}
@@ -1560,9 +2918,7 @@ func (m *Model) renderHelpBar() string {
 // Helper functions
 
 func formatDuration(d time.Duration) string {
-	days := int(d.Hours() / 24)
-	hours := int(d.Hours()) % 24
-	mins := int(d.Minutes()) % 60
+	days, hours, mins := timefmt.Split(d)
 
 	if days > 0 {
 		return fmt.Sprintf("%dd %dh", days, hours)
@@ -1573,42 +2929,54 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%dm", mins)
 }
 
-func formatRelativeTime(t time.Time) string {
-	now := time.Now()
-	diff := now.Sub(t)
-
-	if diff < 0 {
-		diff = -diff
-		switch {
-		case diff < time.Minute:
-			return "in a moment"
-		case diff < time.Hour:
-			mins := int(diff.Minutes())
-			return fmt.Sprintf("in %d min", mins)
-		case diff < 24*time.Hour:
-			hours := int(diff.Hours())
-			return fmt.Sprintf("in %d hours", hours)
-		default:
-			days := int(diff.Hours() / 24)
-			return fmt.Sprintf("in %d days", days)
-		}
+// loadActivity refreshes the entries-per-day counts shown in the daemon tab
+func (m *Model) loadActivity() {
+	db, err := store.Open()
+	if err != nil {
+		return
 	}
+	defer db.Close()
 
-	switch {
-	case diff < time.Minute:
-		return "just now"
-	case diff < time.Hour:
-		mins := int(diff.Minutes())
-		return fmt.Sprintf("%d min ago", mins)
-	case diff < 24*time.Hour:
-		hours := int(diff.Hours())
-		return fmt.Sprintf("%d hours ago", hours)
-	case diff < 7*24*time.Hour:
-		days := int(diff.Hours() / 24)
-		return fmt.Sprintf("%d days ago", days)
-	default:
-		return t.Format("Jan 02")
+	counts, err := db.CountPerDay(activityDays)
+	if err != nil {
+		return
+	}
+	m.activity = counts
+}
+
+// sparkBlocks are the bar heights used by sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders counts as block characters scaled to the largest value
+// Days with no entries show as a dot; when width is too narrow for every
+// value the oldest are dropped, and wide panels repeat each bar
+func sparkline(counts []int, width int) string {
+	if len(counts) == 0 || width <= 0 {
+		return ""
+	}
+	if width < len(counts) {
+		counts = counts[len(counts)-width:]
+	}
+	cell := min(width/len(counts), 3)
+
+	peak := 0
+	for _, n := range counts {
+		peak = max(peak, n)
+	}
+
+	var b strings.Builder
+	for _, n := range counts {
+		ch := '·'
+		if n > 0 {
+			ch = sparkBlocks[n*(len(sparkBlocks)-1)/peak]
+		}
+		b.WriteString(strings.Repeat(string(ch), cell))
 	}
+	return b.String()
+}
+
+func formatRelativeTime(t time.Time) string {
+	return timefmt.Relative(t, time.Now())
 }
 
 func getContentPreview(content string, maxLen int) string {
@@ -1617,10 +2985,7 @@ func getContentPreview(content string, maxLen int) string {
 	preview = strings.ReplaceAll(preview, "*", "")
 	preview = strings.TrimSpace(preview)
 
-	if len(preview) > maxLen {
-		return preview[:maxLen-1] + "…"
-	}
-	return preview
+	return truncate(preview, maxLen)
 }
 
 // formatPersonaName converts a file name like "poor_charlie" to "Poor Charlie"
@@ -1637,25 +3002,27 @@ func formatPersonaName(name string) string {
 	return strings.Join(words, " ")
 }
 
-// truncate shortens a string to maxLen, adding ellipsis if needed
+// truncate shortens a string to maxLen characters, adding ellipsis if needed
+// Operates on runes so multibyte content is never split mid-character
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
 	if maxLen <= 1 {
 		return "…"
 	}
-	return s[:maxLen-1] + "…"
+	return string(runes[:maxLen-1]) + "…"
 }
 
 // Run starts the TUI
-func Run(entries []*store.Entry, version string) error {
-	m, err := New(entries, version)
+func Run(entries []*store.Entry, version string, limit int, startTab string) error {
+	m, err := New(entries, version, limit, startTab)
 	if err != nil {
 		return err
 	}
 
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	_, err = p.Run()
 	return err
 }
@@ -0,0 +1,317 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/cldixon/jernel/internal/config"
+	"github.com/cldixon/jernel/internal/metrics"
+	"github.com/cldixon/jernel/internal/persona"
+	"github.com/cldixon/jernel/internal/store"
+)
+
+// TestGetContentPreviewTruncation verifies previews are truncated to the
+// requested length with an ellipsis.
+func TestGetContentPreviewTruncation(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		maxLen   int
+		expected string
+	}{
+		{"short content unchanged", "Hello world", 30, "Hello world"},
+		{"exact length unchanged", "abcde", 5, "abcde"},
+		{"long content truncated", "abcdefghij", 5, "abcd…"},
+		{"markdown stripped", "# Title\n**bold** text", 30, "Title bold text"},
+		{"tiny max length", "abcdef", 1, "…"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := getContentPreview(tc.content, tc.maxLen)
+			if got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestGetContentPreviewMultibyte verifies truncation never splits a
+// multibyte character.
+func TestGetContentPreviewMultibyte(t *testing.T) {
+	content := strings.Repeat("日本語のテキスト🔥", 5)
+
+	got := getContentPreview(content, 12)
+
+	if !utf8.ValidString(got) {
+		t.Fatalf("preview is not valid UTF-8: %q", got)
+	}
+	if n := utf8.RuneCountInString(got); n != 12 {
+		t.Errorf("expected 12 characters, got %d (%q)", n, got)
+	}
+	if !strings.HasSuffix(got, "…") {
+		t.Errorf("expected ellipsis suffix, got %q", got)
+	}
+}
+
+// TestSparkline verifies bars scale to the peak, zero days render as dots,
+// and the output fits the available width.
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name     string
+		counts   []int
+		width    int
+		expected string
+	}{
+		{"scaled to peak", []int{0, 1, 7}, 3, "·▂█"},
+		{"all zero", []int{0, 0, 0}, 3, "···"},
+		{"narrow drops oldest", []int{7, 0, 7}, 2, "·█"},
+		{"wide repeats bars", []int{0, 7}, 20, "···███"},
+		{"no width", []int{1, 2}, 0, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := sparkline(tc.counts, tc.width)
+			if got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+			if n := utf8.RuneCountInString(got); n > tc.width {
+				t.Errorf("sparkline is %d wide, exceeds %d", n, tc.width)
+			}
+		})
+	}
+}
+
+// TestNextPersona verifies persona filter cycling wraps back to all.
+func TestNextPersona(t *testing.T) {
+	personas := []string{"alice", "bob"}
+
+	tests := []struct {
+		current  string
+		expected string
+	}{
+		{"", "alice"},
+		{"alice", "bob"},
+		{"bob", ""},
+		{"deleted", ""},
+	}
+
+	for _, tt := range tests {
+		if got := nextPersona(personas, tt.current); got != tt.expected {
+			t.Errorf("nextPersona(%q): expected %q, got %q", tt.current, tt.expected, got)
+		}
+	}
+
+	if got := nextPersona(nil, ""); got != "" {
+		t.Errorf("expected empty filter with no personas, got %q", got)
+	}
+}
+
+// TestCheckAPIKey verifies a missing key is reported by name before generation.
+func TestCheckAPIKey(t *testing.T) {
+	t.Setenv(config.DirEnv, t.TempDir())
+
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	err := checkAPIKey()
+	if err == nil || !strings.Contains(err.Error(), "ANTHROPIC_API_KEY") {
+		t.Errorf("expected an error naming ANTHROPIC_API_KEY, got %v", err)
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	if err := checkAPIKey(); err != nil {
+		t.Errorf("expected no error with the key set, got %v", err)
+	}
+}
+
+// TestQuitConfirmUnsavedEditor verifies ctrl+c in the persona editor asks
+// before discarding edits, and only when something changed.
+func TestQuitConfirmUnsavedEditor(t *testing.T) {
+	m := &Model{editorNameInput: textinput.New(), editorDescInput: textarea.New()}
+	m.initPersonaEditor(false, "poet", "poet", "Writes verse.")
+	m.subMode = subModePersonaEditor
+	ctrlC := tea.KeyMsg{Type: tea.KeyCtrlC}
+
+	if _, cmd := m.handleKeyMsg(ctrlC); cmd == nil || !m.quitting {
+		t.Fatalf("expected an unchanged editor to quit immediately")
+	}
+
+	m.quitting = false
+	m.editorDescInput.SetValue("Writes free verse.")
+	if _, cmd := m.handleKeyMsg(ctrlC); cmd != nil || m.subMode != subModeQuitConfirm {
+		t.Fatalf("expected the quit confirmation, got sub-mode %d", m.subMode)
+	}
+
+	m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.subMode != subModePersonaEditor || m.quitting {
+		t.Errorf("expected cancel to return to the editor, got sub-mode %d", m.subMode)
+	}
+	if m.editorDescInput.Value() != "Writes free verse." {
+		t.Errorf("expected edits to be kept, got %q", m.editorDescInput.Value())
+	}
+}
+
+// TestSavePersonaRenameConflict verifies a rename onto an existing persona is
+// reported in the editor and leaves both persona files in place.
+func TestSavePersonaRenameConflict(t *testing.T) {
+	t.Setenv(config.DirEnv, t.TempDir())
+	for _, name := range []string{"poet", "critic"} {
+		if err := persona.Save(&persona.Persona{Name: name, Description: "A persona."}); err != nil {
+			t.Fatalf("failed to save persona: %v", err)
+		}
+	}
+
+	m := &Model{editorNameInput: textinput.New(), editorDescInput: textarea.New()}
+	m.initPersonaEditor(false, "poet", "critic", "Writes verse.")
+	m.subMode = subModePersonaEditor
+
+	m.savePersonaFromEditor()
+	if m.editorErr == nil || m.subMode != subModePersonaEditor {
+		t.Fatalf("expected the editor to stay open with an error, got sub-mode %d and %v", m.subMode, m.editorErr)
+	}
+	for _, name := range []string{"poet", "critic"} {
+		if p, err := persona.Get(name); err != nil || p.Description != "A persona." {
+			t.Errorf("expected persona %s untouched, got %v", name, err)
+		}
+	}
+}
+
+// TestToggleMark verifies entries toggle in and out of the compare marks and
+// that a third mark replaces the oldest.
+func TestToggleMark(t *testing.T) {
+	entries := []*store.Entry{{ID: 1}, {ID: 2}, {ID: 3}}
+	m := &Model{entries: entries, entryList: createList(nil)}
+
+	m.toggleMark(entries[0])
+	m.toggleMark(entries[1])
+	m.toggleMark(entries[2])
+	if len(m.marked) != 2 || m.marked[0].ID != 2 || m.marked[1].ID != 3 {
+		t.Fatalf("expected #2 and #3 marked, got %v", m.marked)
+	}
+	if item := m.entryList.Items()[2].(entryItem); !item.marked {
+		t.Errorf("expected the list item for #3 to show as marked")
+	}
+
+	m.toggleMark(entries[1])
+	if len(m.marked) != 1 || m.marked[0].ID != 3 {
+		t.Errorf("expected only #3 marked after unmarking #2, got %v", m.marked)
+	}
+}
+
+// TestCancelGeneration verifies esc leaves the generating view and cancels the
+// request, and that the cancelled run's late result is ignored.
+func TestCancelGeneration(t *testing.T) {
+	m := &Model{entryList: createList(nil), subMode: subModeGenerating, generating: true}
+	ctx, seq := m.generationContext()
+
+	m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.subMode != subModeNone || m.generating {
+		t.Fatalf("expected to leave the generating view, got sub-mode %d", m.subMode)
+	}
+	if ctx.Err() == nil {
+		t.Error("expected the generation context to be cancelled")
+	}
+
+	m.Update(generateDoneMsg{err: ctx.Err(), seq: seq})
+	if m.subMode != subModeNone || m.genError != nil {
+		t.Errorf("expected the cancelled result to be ignored, got sub-mode %d and error %v", m.subMode, m.genError)
+	}
+}
+
+// TestReadingTime verifies the estimate rounds to whole minutes at 200 words
+// a minute and short entries read as under a minute.
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		words    int
+		expected string
+	}{
+		{0, "< 1 min read"},
+		{199, "< 1 min read"},
+		{200, "1 min read"},
+		{299, "1 min read"},
+		{300, "2 min read"},
+		{1000, "5 min read"},
+	}
+	for _, tc := range tests {
+		content := strings.TrimSpace(strings.Repeat("word ", tc.words))
+		if got := readingTime(content); got != tc.expected {
+			t.Errorf("%d words: expected %q, got %q", tc.words, tc.expected, got)
+		}
+	}
+}
+
+// TestRenderBar verifies bars keep a fixed width, fill in proportion to the
+// percentage, and clamp out-of-range values.
+func TestRenderBar(t *testing.T) {
+	tests := []struct {
+		percent float64
+		filled  string
+	}{
+		{0, ""},
+		{50, strings.Repeat("█", 5)},
+		{55, strings.Repeat("█", 5) + "▌"},
+		{100, strings.Repeat("█", 10)},
+		{140, strings.Repeat("█", 10)},
+		{-5, ""},
+	}
+	for _, tc := range tests {
+		bar := renderBar(tc.percent, 10)
+		if w := lipgloss.Width(bar); w != 10 {
+			t.Errorf("%.0f%%: expected width 10, got %d", tc.percent, w)
+		}
+		if !strings.Contains(bar, tc.filled+strings.Repeat("░", 10-utf8.RuneCountInString(tc.filled))) {
+			t.Errorf("%.0f%%: expected %q filled, got %q", tc.percent, tc.filled, bar)
+		}
+	}
+
+	if barColor(30) != colorOK || barColor(60) != colorWarn || barColor(90) != colorError {
+		t.Error("expected bars colored by threshold")
+	}
+}
+
+// TestMetricsChart verifies the chart is skipped for entries without metrics.
+func TestMetricsChart(t *testing.T) {
+	if chart := metricsChart(nil); chart != "" {
+		t.Errorf("expected no chart without a snapshot, got %q", chart)
+	}
+	if chart := metricsChart(&metrics.Snapshot{}); chart != "" {
+		t.Errorf("expected no chart for an empty snapshot, got %q", chart)
+	}
+
+	chart := metricsChart(&metrics.Snapshot{CPUPercent: 42, MemoryPercent: 70, MemoryTotal: 1, DiskPercent: 91, DiskTotal: 1})
+	for _, want := range []string{"CPU", "Memory", "Disk", "42%", "70%", "91%"} {
+		if !strings.Contains(chart, want) {
+			t.Errorf("expected %q in chart, got %q", want, chart)
+		}
+	}
+}
+
+// TestParseTab verifies --tab names match case-insensitively and that an
+// unknown name is rejected.
+func TestParseTab(t *testing.T) {
+	tests := []struct {
+		name string
+		want tab
+	}{
+		{"", tabEntries},
+		{"entries", tabEntries},
+		{"personas", tabPersonas},
+		{"Daemon", tabDaemon},
+		{"SETTINGS", tabSettings},
+	}
+	for _, tt := range tests {
+		got, err := parseTab(tt.name)
+		if err != nil || got != tt.want {
+			t.Errorf("parseTab(%q) = %d, %v; want %d", tt.name, got, err, tt.want)
+		}
+	}
+
+	if _, err := parseTab("logs"); err == nil {
+		t.Error("expected an error for an unknown tab")
+	}
+}
@@ -0,0 +1,108 @@
+// Package jernel is the public API for embedding jernel's journal generation
+// in other programs. It is a thin layer over the internal packages used by the
+// CLI: entries are written with the same config, personas, prompts, and
+// database as the jernel command, so the two can be used side by side.
+//
+// The config directory defaults to ~/.config/jernel; set the JERNEL_CONFIG_DIR
+// environment variable (ConfigDirEnv) to use another one, and JERNEL_PROFILE
+// (ProfileEnv) to apply one of the profiles defined in config.yaml.
+package jernel
+
+import (
+	"context"
+
+	"github.com/cldixon/jernel/internal/config"
+	"github.com/cldixon/jernel/internal/entry"
+	"github.com/cldixon/jernel/internal/metrics"
+	"github.com/cldixon/jernel/internal/persona"
+	"github.com/cldixon/jernel/internal/store"
+	"github.com/cldixon/jernel/internal/timefmt"
+)
+
+// Types shared with the internal packages
+type (
+	Config   = config.Config    // application settings from config.yaml
+	Entry    = store.Entry      // a saved journal entry
+	Store    = store.Store      // the entries database
+	Filter   = store.Filter     // narrows Store.ListFiltered and Store.EachFiltered
+	Snapshot = metrics.Snapshot // system metrics an entry was written from
+	Persona  = persona.Persona  // the voice an entry is written in
+	Result   = entry.Result     // a generated entry with its persona and metrics
+)
+
+// Sentinel errors shared with the internal packages; check them with errors.Is
+var (
+	ErrEntryNotFound        = store.ErrEntryNotFound
+	ErrPersonaNotFound      = persona.ErrNotFound
+	ErrPersonaAlreadyExists = persona.ErrAlreadyExists
+)
+
+// ConfigDirEnv is the environment variable that overrides the config directory
+const ConfigDirEnv = config.DirEnv
+
+// ProfileEnv is the environment variable that selects a config profile
+const ProfileEnv = config.ProfileEnv
+
+// Options controls GenerateEntry; the zero value writes and saves an entry
+// with the default persona from the user's config
+type Options struct {
+	Config      *Config // settings to use (nil loads config.yaml)
+	Persona     string  // persona name (empty uses the config's default persona)
+	Description string  // inline persona description used instead of a persona file
+	Note        string  // recent events for the persona to react to
+	NoSave      bool    // return the entry without saving it (its ID is 0)
+}
+
+// Init creates the config directory with the default config, prompts, and
+// persona if they don't exist yet; it leaves existing files alone
+func Init() error {
+	return config.Init()
+}
+
+// LoadConfig reads config.yaml, returning defaults if it doesn't exist
+func LoadConfig() (*Config, error) {
+	return config.Load()
+}
+
+// OpenStore opens the entries database, creating and migrating it as needed
+// The caller must Close it
+func OpenStore() (*Store, error) {
+	return store.Open()
+}
+
+// SetTimezone sets the timezone entry dates are shown in, including those of the
+// previous entries given to the LLM, from a display_timezone value such as
+// "Europe/Berlin" (empty means local time). It is process-wide, so set it once
+// at startup, e.g. from Config.DisplayTimezone; GenerateEntry leaves it alone
+func SetTimezone(name string) error {
+	loc, err := timefmt.LoadLocation(name)
+	if err != nil {
+		return err
+	}
+	timefmt.SetLocation(loc)
+	return nil
+}
+
+// GenerateEntry gathers system metrics, asks the configured LLM for an entry,
+// and saves it unless opts.NoSave is set
+func GenerateEntry(ctx context.Context, opts Options) (*Result, error) {
+	cfg := opts.Config
+	if cfg == nil {
+		loaded, err := config.Load()
+		if err != nil {
+			return nil, err
+		}
+		cfg = loaded
+	}
+
+	name := opts.Persona
+	if name == "" {
+		name = cfg.DefaultPersona
+	}
+
+	return entry.GenerateWithOptions(ctx, cfg, name, entry.Options{
+		NoSave:      opts.NoSave,
+		Note:        opts.Note,
+		Description: opts.Description,
+	})
+}
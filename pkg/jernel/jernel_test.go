@@ -0,0 +1,74 @@
+package jernel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cldixon/jernel/internal/metrics"
+	"github.com/cldixon/jernel/internal/timefmt"
+)
+
+// TestOpenStore verifies the facade opens a usable database in the config directory.
+func TestOpenStore(t *testing.T) {
+	t.Setenv(ConfigDirEnv, t.TempDir())
+
+	if err := Init(); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+
+	db, err := OpenStore()
+	if err != nil {
+		t.Fatalf("OpenStore() failed: %v", err)
+	}
+	defer db.Close()
+
+	saved, err := db.Save("default", "Dear diary", "model", "msg", "", "", &metrics.Snapshot{Timestamp: time.Now()})
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	entries, err := db.ListFiltered(Filter{Persona: "default"})
+	if err != nil {
+		t.Fatalf("ListFiltered() failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != saved.ID {
+		t.Errorf("expected the saved entry back, got %d entries", len(entries))
+	}
+}
+
+// TestSetTimezone verifies display timezones are set by name and unknown names
+// are rejected without changing the current one.
+func TestSetTimezone(t *testing.T) {
+	t.Cleanup(func() { timefmt.SetLocation(time.Local) })
+
+	if err := SetTimezone("Europe/Berlin"); err != nil {
+		t.Fatalf("SetTimezone() failed: %v", err)
+	}
+	if got := timefmt.Location().String(); got != "Europe/Berlin" {
+		t.Errorf("expected Europe/Berlin, got %s", got)
+	}
+	if err := SetTimezone("Mars/Olympus"); err == nil {
+		t.Error("expected an error for an unknown timezone")
+	}
+	if got := timefmt.Location().String(); got != "Europe/Berlin" {
+		t.Errorf("expected the timezone to be unchanged after an error, got %s", got)
+	}
+}
+
+// TestGenerateEntryMissingPersona verifies persona errors surface before any
+// metrics or LLM work.
+func TestGenerateEntryMissingPersona(t *testing.T) {
+	t.Setenv(ConfigDirEnv, t.TempDir())
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	_, err = GenerateEntry(context.Background(), Options{Config: cfg, Persona: "ghost"})
+	if !errors.Is(err, ErrPersonaNotFound) {
+		t.Errorf("expected ErrPersonaNotFound, got %v", err)
+	}
+}